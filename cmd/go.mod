@@ -0,0 +1,8 @@
+module github.com/LiangQinghai/kratos-ext/cmd
+
+go 1.22
+
+require (
+	google.golang.org/genproto/googleapis/api v0.0.0-20240521202816-d264139d666e
+	google.golang.org/protobuf v1.34.1
+)
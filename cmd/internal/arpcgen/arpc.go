@@ -1,10 +1,9 @@
-package main
+// Package arpcgen generates the tarpc servers and clients of protobuf services.
+package arpcgen
 
 import (
 	"fmt"
-	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/compiler/protogen"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/descriptorpb"
 	"strings"
 )
@@ -15,18 +14,32 @@ const (
 	deprecationComment   = "// Deprecated: Do not use."
 )
 
-var methodSets = make(map[string]int)
+// Generator generates the arpc code of the services.
+type Generator struct {
+	plugin     string
+	release    string
+	methodSets map[string]int
+}
+
+// NewGenerator returns a generator, plugin and release name the generating plugin in the file header.
+func NewGenerator(plugin, release string) *Generator {
+	return &Generator{
+		plugin:     plugin,
+		release:    release,
+		methodSets: make(map[string]int),
+	}
+}
 
-// generateFile generates a _arpc.pb.go file containing kratos errors definitions.
-func generateFile(gen *protogen.Plugin, file *protogen.File) *protogen.GeneratedFile {
+// GenerateFile generates a _arpc.pb.go file containing the arpc server and client of the services.
+func (gr *Generator) GenerateFile(gen *protogen.Plugin, file *protogen.File) *protogen.GeneratedFile {
 	if len(file.Services) == 0 {
 		return nil
 	}
 	filename := file.GeneratedFilenamePrefix + "_arpc.pb.go"
 	g := gen.NewGeneratedFile(filename, file.GoImportPath)
-	g.P("// Code generated by protoc-gen-go-arpc. DO NOT EDIT.")
+	g.P("// Code generated by ", gr.plugin, ". DO NOT EDIT.")
 	g.P("// version:")
-	g.P(fmt.Sprintf("// - protoc-gen-go-arpc %s", release))
+	g.P(fmt.Sprintf("// - %s %s", gr.plugin, gr.release))
 	g.P("// - protoc             ", protocVersion(gen))
 	if file.Proto.GetOptions().GetDeprecated() {
 		g.P("// ", file.Desc.Path(), " is a deprecated file.")
@@ -36,12 +49,12 @@ func generateFile(gen *protogen.Plugin, file *protogen.File) *protogen.Generated
 	g.P()
 	g.P("package ", file.GoPackageName)
 	g.P()
-	generateFileContent(gen, file, g)
+	gr.generateFileContent(gen, file, g)
 	return g
 }
 
-// generateFileContent generates the kratos errors definitions, excluding the package statement.
-func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile) {
+// generateFileContent generates the arpc server and client of the services, excluding the package statement.
+func (gr *Generator) generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile) {
 	if len(file.Services) == 0 {
 		return
 	}
@@ -50,11 +63,11 @@ func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.
 	g.P()
 
 	for _, service := range file.Services {
-		genService(gen, file, g, service)
+		gr.genService(gen, file, g, service)
 	}
 }
 
-func genService(_ *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service) {
+func (gr *Generator) genService(_ *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service) {
 	if service.Desc.Options().(*descriptorpb.ServiceOptions).GetDeprecated() {
 		g.P("//")
 		g.P(deprecationComment)
@@ -66,18 +79,15 @@ func genService(_ *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFi
 		Metadata:    file.Desc.Path(),
 	}
 	for _, method := range service.Methods {
-		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
-			continue
-		}
-		sd.Methods = append(sd.Methods, buildMethodDesc(g, method))
+		sd.Methods = append(sd.Methods, gr.buildMethodDesc(g, method))
 	}
 	if len(sd.Methods) != 0 {
 		g.P(sd.execute())
 	}
 }
 
-func buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method) *methodDesc {
-	defer func() { methodSets[m.GoName]++ }()
+func (gr *Generator) buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method) *methodDesc {
+	defer func() { gr.methodSets[m.GoName]++ }()
 	comment := m.Comments.Leading.String() + m.Comments.Trailing.String()
 	if comment != "" {
 		comment = "// " + m.GoName + strings.TrimPrefix(strings.TrimSuffix(comment, "\n"), "//")
@@ -85,38 +95,15 @@ func buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method) *methodDesc
 	return &methodDesc{
 		Name:         m.GoName,
 		OriginalName: string(m.Desc.Name()),
-		Num:          methodSets[m.GoName],
+		Num:          gr.methodSets[m.GoName],
 		Request:      g.QualifiedGoIdent(m.Input.GoIdent),
 		Reply:        g.QualifiedGoIdent(m.Output.GoIdent),
 		Comment:      comment,
+		ServerStream: m.Desc.IsStreamingServer(),
+		ClientStream: m.Desc.IsStreamingClient(),
 	}
 }
 
-// Is c an ASCII lower-case letter?
-func isASCIILower(c byte) bool {
-	return 'a' <= c && c <= 'z'
-}
-
-// Is c an ASCII digit?
-func isASCIIDigit(c byte) bool {
-	return '0' <= c && c <= '9'
-}
-
-func hasHTTPRule(services []*protogen.Service) bool {
-	for _, service := range services {
-		for _, method := range service.Methods {
-			if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
-				continue
-			}
-			rule, ok := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
-			if rule != nil && ok {
-				return true
-			}
-		}
-	}
-	return false
-}
-
 func protocVersion(gen *protogen.Plugin) string {
 	v := gen.Request.GetCompilerVersion()
 	if v == nil {
@@ -0,0 +1,235 @@
+{{$svrType := .ServiceType}}
+{{$svrName := .ServiceName}}
+
+{{- range .MethodSets}}
+const ArpcOperation{{$svrType}}{{.OriginalName}} = "/{{$svrName}}/{{.OriginalName}}"
+{{- end}}
+
+type {{.ServiceType}}ArpcServer interface {
+{{- range .MethodSets}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{- if .ClientStream}}
+	{{.Name}}({{$svrType}}_{{.Name}}ArpcServer) error
+	{{- else if .ServerStream}}
+	{{.Name}}(*{{.Request}}, {{$svrType}}_{{.Name}}ArpcServer) error
+	{{- else}}
+	{{.Name}}(context.Context, *{{.Request}}) (*{{.Reply}}, error)
+	{{- end}}
+{{- end}}
+}
+
+func Register{{.ServiceType}}ArpcServer(s *tarpc.Server, srv {{.ServiceType}}ArpcServer) {
+	{{- range .Methods}}
+	s.Handle(ArpcOperation{{$svrType}}{{.OriginalName}}, _{{$svrType}}_{{.Name}}{{.Num}}_Arpc_Handler(s, srv))
+	{{- end}}
+}
+
+{{range .Methods}}
+{{- if or .ClientStream .ServerStream}}
+func _{{$svrType}}_{{.Name}}{{.Num}}_Arpc_Handler(s *tarpc.Server, srv {{$svrType}}ArpcServer) tarpc.HandlerFunc {
+	return func(c *tarpc.Ctx) {
+		{{- if .ClientStream}}
+		stream, _, err := s.DecodeStream(c)
+		if err != nil {
+			panic(err)
+		}
+		tarpc.SetOperation(stream.Context(), ArpcOperation{{$svrType}}{{.OriginalName}})
+		s.ServeStream(c, stream, func(ctx context.Context) error {
+			h := s.Middleware(ctx, func(_ctx context.Context, _ interface{}) (interface{}, error) {
+				return nil, srv.{{.Name}}(&_{{$svrType}}_{{.Name}}ArpcServerImpl{stream, _ctx})
+			})
+			_, err := h(ctx, nil)
+			return err
+		})
+		{{- else}}
+		stream, bytes, err := s.DecodeStream(c)
+		if err != nil {
+			panic(err)
+		}
+		tarpc.SetOperation(stream.Context(), ArpcOperation{{$svrType}}{{.OriginalName}})
+		s.ServeStream(c, stream, func(ctx context.Context) error {
+			// a bad request ends the stream, which releases its context
+			var in {{.Request}}
+			if err := s.DecodeData(bytes, &in); err != nil {
+				return err
+			}
+			h := s.Middleware(ctx, func(_ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, srv.{{.Name}}(req.(*{{.Request}}), &_{{$svrType}}_{{.Name}}ArpcServerImpl{stream, _ctx})
+			})
+			_, err := h(ctx, &in)
+			return err
+		})
+		{{- end}}
+	}
+}
+
+type {{$svrType}}_{{.Name}}ArpcServer interface {
+	{{- if .ServerStream}}
+	Send(*{{.Reply}}) error
+	{{- else}}
+	SendAndClose(*{{.Reply}}) error
+	{{- end}}
+	{{- if .ClientStream}}
+	Recv() (*{{.Request}}, error)
+	{{- end}}
+	Context() context.Context
+}
+
+type _{{$svrType}}_{{.Name}}ArpcServerImpl struct {
+	*tarpc.ServerStream
+	ctx context.Context
+}
+{{- if .ServerStream}}
+
+func (x *_{{$svrType}}_{{.Name}}ArpcServerImpl) Send(m *{{.Reply}}) error {
+	return x.ServerStream.SendMsg(m)
+}
+{{- else}}
+
+func (x *_{{$svrType}}_{{.Name}}ArpcServerImpl) SendAndClose(m *{{.Reply}}) error {
+	return x.ServerStream.SendMsg(m)
+}
+{{- end}}
+{{- if .ClientStream}}
+
+func (x *_{{$svrType}}_{{.Name}}ArpcServerImpl) Recv() (*{{.Request}}, error) {
+	m := new({{.Request}})
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- end}}
+
+func (x *_{{$svrType}}_{{.Name}}ArpcServerImpl) Context() context.Context {
+	return x.ctx
+}
+{{- else}}
+func _{{$svrType}}_{{.Name}}{{.Num}}_Arpc_Handler(s *tarpc.Server, srv {{$svrType}}ArpcServer) tarpc.HandlerFunc {
+	return func(c *tarpc.Ctx) {
+	    var err error
+	    ctx, bytes, err := s.DecodeRequest(c)
+	    if err != nil {
+	        panic(err)
+	    }
+	    // timeout
+	    ctx, cancel := s.Timeout(ctx)
+	    defer cancel()
+		var in {{.Request}}
+		err = s.DecodeData(bytes, &in)
+	    if err != nil {
+	        panic(err)
+	    }
+		tarpc.SetOperation(ctx, ArpcOperation{{$svrType}}{{.OriginalName}})
+		h := s.Middleware(ctx, func(_ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.{{.Name}}(_ctx, req.(*{{.Request}}))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			panic(err)
+		}
+		reply := out.(*{{.Reply}})
+		resp := s.EncodeResponse(ctx, reply, err)
+		s.Write(c, resp)
+	}
+}
+{{- end}}
+{{end}}
+
+type {{.ServiceType}}ArpcClient interface {
+{{- range .MethodSets}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{- if .ClientStream}}
+	{{.Name}}(context.Context) ({{$svrType}}_{{.Name}}ArpcClient, error)
+	{{- else if .ServerStream}}
+	{{.Name}}(context.Context, *{{.Request}}) ({{$svrType}}_{{.Name}}ArpcClient, error)
+	{{- else}}
+	{{.Name}}(context.Context, *{{.Request}}) (*{{.Reply}}, error)
+	{{- end}}
+{{- end}}
+}
+
+type _{{.ServiceType}}ArpcClientImpl struct {
+    cc *tarpc.Client
+}
+
+func New{{.ServiceType}}ArpcClient(cc *tarpc.Client) {{.ServiceType}}ArpcClient {
+    return &_{{.ServiceType}}ArpcClientImpl{cc}
+}
+
+{{range .Methods}}
+{{- if or .ClientStream .ServerStream}}
+{{- if .ClientStream}}
+func (c *_{{$svrType}}ArpcClientImpl) {{.Name}}(ctx context.Context) ({{$svrType}}_{{.Name}}ArpcClient, error) {
+    stream, err := c.cc.NewStream(ctx, ArpcOperation{{$svrType}}{{.OriginalName}}, nil)
+{{- else}}
+func (c *_{{$svrType}}ArpcClientImpl) {{.Name}}(ctx context.Context, req *{{.Request}}) ({{$svrType}}_{{.Name}}ArpcClient, error) {
+    stream, err := c.cc.NewStream(ctx, ArpcOperation{{$svrType}}{{.OriginalName}}, req)
+{{- end}}
+    if err != nil {
+        return nil, err
+    }
+    return &_{{$svrType}}_{{.Name}}ArpcClientImpl{stream}, nil
+}
+
+type {{$svrType}}_{{.Name}}ArpcClient interface {
+	{{- if .ClientStream}}
+	Send(*{{.Request}}) error
+	{{- end}}
+	{{- if .ServerStream}}
+	Recv() (*{{.Reply}}, error)
+	{{- else}}
+	CloseAndRecv() (*{{.Reply}}, error)
+	{{- end}}
+	{{- if and .ClientStream .ServerStream}}
+	CloseSend() error
+	{{- end}}
+	Context() context.Context
+}
+
+type _{{$svrType}}_{{.Name}}ArpcClientImpl struct {
+	*tarpc.ClientStream
+}
+{{- if .ClientStream}}
+
+func (x *_{{$svrType}}_{{.Name}}ArpcClientImpl) Send(m *{{.Request}}) error {
+	return x.ClientStream.SendMsg(m)
+}
+{{- end}}
+{{- if .ServerStream}}
+
+func (x *_{{$svrType}}_{{.Name}}ArpcClientImpl) Recv() (*{{.Reply}}, error) {
+	m := new({{.Reply}})
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- else}}
+
+func (x *_{{$svrType}}_{{.Name}}ArpcClientImpl) CloseAndRecv() (*{{.Reply}}, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new({{.Reply}})
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- end}}
+{{- else}}
+func (c *_{{$svrType}}ArpcClientImpl) {{.Name}}(ctx context.Context, req *{{.Request}}) (*{{.Reply}}, error) {
+    out := new({{.Reply}})
+    err := c.cc.Call(ctx, ArpcOperation{{$svrType}}{{.OriginalName}}, req, out)
+    if err != nil {
+        return nil, err
+    }
+    return out, nil
+}
+{{- end}}
+{{end}}
\ No newline at end of file
@@ -0,0 +1,82 @@
+package arpcgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	sd := &serviceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*methodDesc{
+			{
+				Name:         "SayHello",
+				OriginalName: "SayHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				Comment:      "// SayHello says hello",
+			},
+			{
+				Name:         "ListHello",
+				OriginalName: "ListHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				ServerStream: true,
+			},
+			{
+				Name:         "RecordHello",
+				OriginalName: "RecordHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				ClientStream: true,
+			},
+			{
+				Name:         "ChatHello",
+				OriginalName: "ChatHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				ClientStream: true,
+				ServerStream: true,
+			},
+		},
+	}
+	code := sd.execute()
+	for _, want := range []string{
+		`const ArpcOperationGreeterSayHello = "/helloworld.Greeter/SayHello"`,
+		`const ArpcOperationGreeterListHello = "/helloworld.Greeter/ListHello"`,
+		"// SayHello says hello",
+		"SayHello(context.Context, *HelloRequest) (*HelloReply, error)",
+		"ListHello(*HelloRequest, Greeter_ListHelloArpcServer) error",
+		"s.Handle(ArpcOperationGreeterListHello, _Greeter_ListHello0_Arpc_Handler(s, srv))",
+		"func (x *_Greeter_ListHelloArpcServerImpl) Send(m *HelloReply) error {",
+		"ListHello(context.Context, *HelloRequest) (Greeter_ListHelloArpcClient, error)",
+		"stream, err := c.cc.NewStream(ctx, ArpcOperationGreeterListHello, req)",
+		"func (x *_Greeter_ListHelloArpcClientImpl) Recv() (*HelloReply, error) {",
+		"err := c.cc.Call(ctx, ArpcOperationGreeterSayHello, req, out)",
+		"RecordHello(Greeter_RecordHelloArpcServer) error",
+		"func (x *_Greeter_RecordHelloArpcServerImpl) SendAndClose(m *HelloReply) error {",
+		"func (x *_Greeter_RecordHelloArpcServerImpl) Recv() (*HelloRequest, error) {",
+		"RecordHello(context.Context) (Greeter_RecordHelloArpcClient, error)",
+		"stream, err := c.cc.NewStream(ctx, ArpcOperationGreeterRecordHello, nil)",
+		"func (x *_Greeter_RecordHelloArpcClientImpl) CloseAndRecv() (*HelloReply, error) {",
+		"ChatHello(Greeter_ChatHelloArpcServer) error",
+		"func (x *_Greeter_ChatHelloArpcServerImpl) Send(m *HelloReply) error {",
+		"func (x *_Greeter_ChatHelloArpcServerImpl) Recv() (*HelloRequest, error) {",
+		"ChatHello(context.Context) (Greeter_ChatHelloArpcClient, error)",
+		"Recv() (*HelloReply, error)\n\tCloseSend() error",
+		"func (x *_Greeter_ChatHelloArpcClientImpl) Send(m *HelloRequest) error {",
+		"func (x *_Greeter_ChatHelloArpcClientImpl) Recv() (*HelloReply, error) {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+	// the request of a server stream is decoded by the stream handler, whose error ends the stream
+	handler := code[strings.Index(code, "func _Greeter_ListHello0_Arpc_Handler"):]
+	serve := strings.Index(handler, "s.ServeStream(c, stream")
+	decode := strings.Index(handler, "if err := s.DecodeData(bytes, &in); err != nil {\n\t\t\t\treturn err")
+	if serve < 0 || decode < serve {
+		t.Error("the request of a server stream should be decoded in the stream handler")
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package arpcgen
 
 import (
 	"bytes"
@@ -26,6 +26,8 @@ type methodDesc struct {
 	Request      string
 	Reply        string
 	Comment      string
+	ServerStream bool
+	ClientStream bool
 }
 
 func (s *serviceDesc) execute() string {
@@ -0,0 +1,72 @@
+package httpgen
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dialect is what a web framework brings to the generator. The route syntax is
+// described by Method and Param, while the handler signature and the binder
+// calls live in the Template, which is executed with a ServiceDesc.
+type Dialect interface {
+	// Name is the framework name, the generated file is <file>_<name>.pb.go.
+	Name() string
+	// Package is the transport package imported by the generated code.
+	Package() protogen.GoImportPath
+	// Template is the text/template of a service.
+	Template() string
+	// Method returns the route method of an upper case http method, e.g. Get for fiber.
+	Method(method string) string
+	// Param returns the route segment which matches a single segment, e.g. :name.
+	Param(name string) string
+	// CatchAll returns the route segment which matches the rest of the path, e.g. *name.
+	CatchAll(name string) string
+	// WebSocket reports whether client and bidi streaming methods are served over websocket,
+	// they are skipped otherwise.
+	WebSocket() bool
+}
+
+var (
+	routeVar     = regexp.MustCompile(`(?i){([a-z.0-9_\s]*)=?([^{}]*)}`)
+	nonParamChar = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// RoutePath replaces the vars of the google.api.http path with the route syntax of d.
+// A {var=pattern} var may span several segments, so each segment of its pattern becomes
+// a route segment: * a param, ** a catch-all and the others are kept. Such params only
+// make the route match, the var is bound by matching the request path with the pattern.
+func RoutePath(d Dialect, path string) string {
+	return routeVar.ReplaceAllStringFunc(path, func(s string) string {
+		m := routeVar.FindStringSubmatch(s)
+		name := strings.TrimSpace(m[1])
+		if len(name) <= 1 || len(m[2]) == 0 {
+			return d.Param(name)
+		}
+		segments := strings.Split(m[2], "/")
+		for i, segment := range segments {
+			if segment != "*" && segment != "**" {
+				continue
+			}
+			param := patternParam(name, segments[:i])
+			if segment == "*" {
+				segments[i] = d.Param(param)
+			} else {
+				segments[i] = d.CatchAll(param)
+			}
+		}
+		return strings.Join(segments, "/")
+	})
+}
+
+// patternParam names the param of a pattern segment after the collection before it,
+// e.g. shelves of shelves/*, so that {name=shelves/*} and {parent=shelves/*}/books
+// share the param, which gin requires. The var name is the fallback, without dots
+// since fiber reads a dot as the end of a param.
+func patternParam(name string, before []string) string {
+	if n := len(before); n > 0 && before[n-1] != "*" && before[n-1] != "**" {
+		return nonParamChar.ReplaceAllString(before[n-1], "_")
+	}
+	return nonParamChar.ReplaceAllString(name, "_") + strconv.Itoa(len(before))
+}
@@ -0,0 +1,39 @@
+package httpgen_test
+
+import (
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/echo"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/fiber"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/gin"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/hertz"
+	"testing"
+)
+
+func TestDialectRoutePath(t *testing.T) {
+	dialects := []httpgen.Dialect{fiber.Dialect{}, hertz.Dialect{}, echo.Dialect{}, gin.Dialect{}}
+	tests := []struct {
+		path string
+		// want is the route of each dialect, in the order of dialects
+		want [4]string
+	}{
+		{"/test/{message.id}", [4]string{"/test/:message.id", "/test/:message.id", "/test/:message.id", "/test/:message.id"}},
+		{
+			"/test/{message.id}/{message.name=messages/*}",
+			[4]string{"/test/:message.id/messages/:messages", "/test/:message.id/messages/:messages", "/test/:message.id/messages/:messages", "/test/:message.id/messages/:messages"},
+		},
+		{
+			"/v1/{name=shelves/*/books/*}",
+			[4]string{"/v1/shelves/:shelves/books/:books", "/v1/shelves/:shelves/books/:books", "/v1/shelves/:shelves/books/:books", "/v1/shelves/:shelves/books/:books"},
+		},
+		{"/v1/{parent=shelves/*}/books", [4]string{"/v1/shelves/:shelves/books", "/v1/shelves/:shelves/books", "/v1/shelves/:shelves/books", "/v1/shelves/:shelves/books"}},
+		{"/v1/{name=files/**}", [4]string{"/v1/files/*", "/v1/files/*files", "/v1/files/*", "/v1/files/*files"}},
+		{"/v1/{book.name=*}", [4]string{"/v1/:book_name0", "/v1/:book_name0", "/v1/:book_name0", "/v1/:book_name0"}},
+	}
+	for _, tt := range tests {
+		for i, d := range dialects {
+			if got := httpgen.RoutePath(d, tt.path); got != tt.want[i] {
+				t.Errorf("%s %s: expected %s got %s", d.Name(), tt.path, tt.want[i], got)
+			}
+		}
+	}
+}
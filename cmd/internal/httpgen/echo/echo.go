@@ -0,0 +1,45 @@
+// Package echo is the httpgen dialect of echo.
+package echo
+
+import (
+	_ "embed"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+//go:embed httpTemplate.tpl
+var httpTemplate string
+
+// Dialect generates the techo routes, routes are added with the upper case http method.
+type Dialect struct{}
+
+var _ httpgen.Dialect = Dialect{}
+
+func (Dialect) Name() string {
+	return "echo"
+}
+
+func (Dialect) Package() protogen.GoImportPath {
+	return "github.com/LiangQinghai/kratos-ext/transport/techo"
+}
+
+func (Dialect) Template() string {
+	return httpTemplate
+}
+
+func (Dialect) Method(method string) string {
+	return method
+}
+
+func (Dialect) Param(name string) string {
+	return ":" + name
+}
+
+// CatchAll ignores the name, the echo wildcard is named *.
+func (Dialect) CatchAll(string) string {
+	return "*"
+}
+
+func (Dialect) WebSocket() bool {
+	return false
+}
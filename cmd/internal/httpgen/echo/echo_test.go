@@ -0,0 +1,76 @@
+package echo
+
+import (
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"strings"
+	"testing"
+)
+
+func TestServerTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "CreateHello",
+				OriginalName: "CreateHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				Path:         "/hello/:name",
+				Method:       "POST",
+				HTTPMethod:   "POST",
+				HasVars:      true,
+				HasBody:      true,
+				Body:         ".Inner",
+				ResponseBody: ".Inner",
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"type GreeterEchoServer interface",
+		`r.Add("POST", "/hello/:name", _Greeter_CreateHello0_Echo_Handler(s, srv))`,
+		"if err := techo.BindBody(ctx, &in.Inner); err != nil {",
+		"if err := techo.BindPath(ctx, &in); err != nil {",
+		"techo.SetOperation(c, EchoOperationGreeterCreateHello)",
+		"return s.Write(ctx, reply.Inner)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+}
+
+func TestServerStreamTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "StreamHello",
+				OriginalName: "StreamHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				ServerStream: true,
+				Path:         "/hello/:name/stream",
+				Method:       "GET",
+				HTTPMethod:   "GET",
+				HasVars:      true,
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"StreamHello(*HelloRequest, Greeter_StreamHelloEchoServer) error",
+		"type Greeter_StreamHelloEchoServer interface",
+		"*techo.Stream",
+		"s.ServeStream(ctx, stream, func() error {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+	if strings.Contains(code, "techo.BindBody") {
+		t.Error("generated handler should not bind a body without one")
+	}
+}
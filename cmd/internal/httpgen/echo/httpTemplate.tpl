@@ -0,0 +1,96 @@
+{{$svrType := .ServiceType}}
+{{$svrName := .ServiceName}}
+
+{{- range .MethodSets}}
+const EchoOperation{{$svrType}}{{.OriginalName}} = "/{{$svrName}}/{{.OriginalName}}"
+{{- end}}
+
+type {{.ServiceType}}EchoServer interface {
+{{- range .MethodSets}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{- if .ServerStream}}
+	{{.Name}}(*{{.Request}}, {{$svrType}}_{{.Name}}EchoServer) error
+	{{- else}}
+	{{.Name}}(context.Context, *{{.Request}}) (*{{.Reply}}, error)
+	{{- end}}
+{{- end}}
+}
+
+{{range .MethodSets}}
+{{- if .ServerStream}}
+type {{$svrType}}_{{.Name}}EchoServer interface {
+	Send(*{{.Reply}}) error
+	Context() context.Context
+	LastEventID() string
+}
+
+type _{{$svrType}}_{{.Name}}EchoServerImpl struct {
+	*techo.Stream
+	ctx context.Context
+}
+
+func (x *_{{$svrType}}_{{.Name}}EchoServerImpl) Send(m *{{.Reply}}) error {
+	return x.Stream.Send(m)
+}
+
+func (x *_{{$svrType}}_{{.Name}}EchoServerImpl) Context() context.Context {
+	return x.ctx
+}
+{{end}}
+{{- end}}
+
+func Register{{.ServiceType}}EchoServer(s *techo.Server, srv {{.ServiceType}}EchoServer) {
+	r := s.Router()
+	{{- range .Methods}}
+	r.Add("{{.HTTPMethod}}", "{{.Path}}", _{{$svrType}}_{{.Name}}{{.Num}}_Echo_Handler(s, srv))
+	{{- end}}
+}
+
+{{range .Methods}}
+func _{{$svrType}}_{{.Name}}{{.Num}}_Echo_Handler(s *techo.Server, srv {{$svrType}}EchoServer) techo.Handler {
+	return func(ctx techo.Ctx) error {
+		var in {{.Request}}
+		{{- if .HasBody}}
+		if err := techo.BindBody(ctx, &in{{.Body}}); err != nil {
+			return err
+		}
+		{{- end}}
+		if err := techo.BindQuery(ctx, &in); err != nil {
+			return err
+		}
+		{{- if .HasPatternVars}}
+		if err := techo.BindPattern(ctx, "{{.Pattern}}", &in); err != nil {
+			return err
+		}
+		{{- else if .HasVars}}
+		if err := techo.BindPath(ctx, &in); err != nil {
+			return err
+		}
+		{{- end}}
+		c := ctx.Request().Context()
+		techo.SetOperation(c, EchoOperation{{$svrType}}{{.OriginalName}})
+		{{- if .ServerStream}}
+		stream := s.NewStream(ctx)
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, srv.{{.Name}}(req.(*{{.Request}}), &_{{$svrType}}_{{.Name}}EchoServerImpl{stream, ctx})
+		}, c, ctx.Path())
+		return s.ServeStream(ctx, stream, func() error {
+			_, err := h(stream.Context(), &in)
+			return err
+		})
+		{{- else}}
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.{{.Name}}(ctx, req.(*{{.Request}}))
+		}, c, ctx.Path())
+		out, err := h(c, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*{{.Reply}})
+		return s.Write(ctx, reply{{.ResponseBody}})
+		{{- end}}
+	}
+}
+{{end}}
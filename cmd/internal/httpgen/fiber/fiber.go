@@ -0,0 +1,66 @@
+// Package fiber is the httpgen dialect of fiber.
+package fiber
+
+import (
+	_ "embed"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"google.golang.org/protobuf/compiler/protogen"
+	"net/http"
+)
+
+//go:embed httpTemplate.tpl
+var httpTemplate string
+
+// Dialect generates the tfiber routes.
+type Dialect struct{}
+
+var _ httpgen.Dialect = Dialect{}
+
+func (Dialect) Name() string {
+	return "fiber"
+}
+
+func (Dialect) Package() protogen.GoImportPath {
+	return "github.com/LiangQinghai/kratos-ext/transport/tfiber"
+}
+
+func (Dialect) Template() string {
+	return httpTemplate
+}
+
+func (Dialect) Method(method string) string {
+	switch method {
+	case http.MethodGet:
+		return MethodGet
+	case http.MethodHead:
+		return MethodHead
+	case http.MethodPost:
+		return MethodPost
+	case http.MethodPut:
+		return MethodPut
+	case http.MethodPatch:
+		return MethodPatch
+	case http.MethodDelete:
+		return MethodDelete
+	case http.MethodConnect:
+		return MethodConnect
+	case http.MethodOptions:
+		return MethodOptions
+	case http.MethodTrace:
+		return MethodTrace
+	}
+	return method
+}
+
+func (Dialect) Param(name string) string {
+	return ":" + name
+}
+
+// CatchAll ignores the name, the greedy params of fiber are named *1, *2 and so on.
+func (Dialect) CatchAll(string) string {
+	return "*"
+}
+
+func (Dialect) WebSocket() bool {
+	return false
+}
@@ -0,0 +1,85 @@
+package fiber
+
+import (
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"strings"
+	"testing"
+)
+
+func TestMethod(t *testing.T) {
+	if m := (Dialect{}).Method("DELETE"); m != MethodDelete {
+		t.Fatalf("expected %s got %s", MethodDelete, m)
+	}
+}
+
+func TestClientTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "CreateHello",
+				OriginalName: "CreateHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				Path:         "/hello/:name<regex(users/.*)>",
+				Pattern:      "/hello/{name=users/*}",
+				Method:       MethodPost,
+				HTTPMethod:   "POST",
+				HasVars:      true,
+				HasBody:      true,
+				Body:         ".Inner",
+				BodyField:    "inner",
+				ResponseBody: ".Inner",
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"type GreeterFiberClient interface",
+		"func NewGreeterFiberClient(cc *tfiber.Client) GreeterFiberClient",
+		`pattern := "/hello/{name=users/*}"`,
+		`path := tfiber.EncodeURL(pattern, in, true, "inner")`,
+		`err := c.cc.Invoke(ctx, "POST", path, in.Inner, &out.Inner, opts...)`,
+		"return s.Write(ctx, reply.Inner)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+}
+
+func TestServerStreamTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "StreamHello",
+				OriginalName: "StreamHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				ServerStream: true,
+				Path:         "/hello/:name/stream",
+				Pattern:      "/hello/{name}/stream",
+				Method:       "Get",
+				HTTPMethod:   "GET",
+				HasVars:      true,
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"StreamHello(*HelloRequest, Greeter_StreamHelloFiberServer) error",
+		"type Greeter_StreamHelloFiberServer interface",
+		"*tfiber.Stream",
+		"s.ServeStream(ctx, stream, func() error {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+	if strings.Contains(code, "func (c *_GreeterFiberClientImpl) StreamHello") {
+		t.Error("generated client should skip server-streaming methods")
+	}
+}
@@ -0,0 +1,139 @@
+{{$svrType := .ServiceType}}
+{{$svrName := .ServiceName}}
+
+{{- range .MethodSets}}
+const FiberOperation{{$svrType}}{{.OriginalName}} = "/{{$svrName}}/{{.OriginalName}}"
+{{- end}}
+
+type {{.ServiceType}}FiberServer interface {
+{{- range .MethodSets}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{- if .ServerStream}}
+	{{.Name}}(*{{.Request}}, {{$svrType}}_{{.Name}}FiberServer) error
+	{{- else}}
+	{{.Name}}(context.Context, *{{.Request}}) (*{{.Reply}}, error)
+	{{- end}}
+{{- end}}
+}
+
+{{range .MethodSets}}
+{{- if .ServerStream}}
+type {{$svrType}}_{{.Name}}FiberServer interface {
+	Send(*{{.Reply}}) error
+	Context() context.Context
+	LastEventID() string
+}
+
+type _{{$svrType}}_{{.Name}}FiberServerImpl struct {
+	*tfiber.Stream
+	ctx context.Context
+}
+
+func (x *_{{$svrType}}_{{.Name}}FiberServerImpl) Send(m *{{.Reply}}) error {
+	return x.Stream.Send(m)
+}
+
+func (x *_{{$svrType}}_{{.Name}}FiberServerImpl) Context() context.Context {
+	return x.ctx
+}
+{{end}}
+{{- end}}
+
+func Register{{.ServiceType}}FiberServer(s *tfiber.Server, srv {{.ServiceType}}FiberServer) {
+	r := s.Router()
+	{{- range .Methods}}
+	r.{{.Method}}("{{.Path}}", _{{$svrType}}_{{.Name}}{{.Num}}_Fiber_Handler(s, srv))
+	{{- end}}
+}
+
+{{range .Methods}}
+func _{{$svrType}}_{{.Name}}{{.Num}}_Fiber_Handler(s *tfiber.Server, srv {{$svrType}}FiberServer) tfiber.Handler {
+	return func(ctx *tfiber.Ctx) error {
+		var in {{.Request}}
+		{{- if .HasBody}}
+		if err := ctx.BodyParser(&in{{.Body}}); err != nil {
+			return err
+		}
+		{{- end}}
+		if err := ctx.QueryParser(&in); err != nil {
+			return err
+		}
+		{{- if .HasPatternVars}}
+		if err := tfiber.BindPattern(ctx, "{{.Pattern}}", &in); err != nil {
+			return err
+		}
+		{{- else if .HasVars}}
+		if err := ctx.ParamsParser(&in); err != nil {
+			return err
+		}
+		{{- end}}
+		tfiber.SetOperation(ctx.UserContext(),FiberOperation{{$svrType}}{{.OriginalName}})
+		{{- if .ServerStream}}
+		stream := s.NewStream(ctx)
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, srv.{{.Name}}(req.(*{{.Request}}), &_{{$svrType}}_{{.Name}}FiberServerImpl{stream, ctx})
+		}, ctx.UserContext(), ctx.Path())
+		return s.ServeStream(ctx, stream, func() error {
+			_, err := h(stream.Context(), &in)
+			return err
+		})
+		{{- else}}
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.{{.Name}}(ctx, req.(*{{.Request}}))
+		}, ctx.UserContext(), ctx.Path())
+		out, err := h(ctx.UserContext(), &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*{{.Reply}})
+		return s.Write(ctx, reply{{.ResponseBody}})
+		{{- end}}
+	}
+}
+{{end}}
+
+type {{.ServiceType}}FiberClient interface {
+{{- range .MethodSets}}
+	{{- if .ServerStream}}{{continue}}{{end}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{.Name}}(ctx context.Context, req *{{.Request}}, opts ...tfiber.CallOption) (rsp *{{.Reply}}, err error)
+{{- end}}
+}
+
+type _{{.ServiceType}}FiberClientImpl struct {
+	cc *tfiber.Client
+}
+
+func New{{.ServiceType}}FiberClient(cc *tfiber.Client) {{.ServiceType}}FiberClient {
+	return &_{{.ServiceType}}FiberClientImpl{cc}
+}
+
+{{range .MethodSets}}
+{{- if .ServerStream}}{{continue}}{{end}}
+func (c *_{{$svrType}}FiberClientImpl) {{.Name}}(ctx context.Context, in *{{.Request}}, opts ...tfiber.CallOption) (*{{.Reply}}, error) {
+	var out {{.Reply}}
+	pattern := "{{.Pattern}}"
+	{{- if not .HasBody}}
+	path := tfiber.EncodeURL(pattern, in, true)
+	{{- else if ne .BodyField ""}}
+	path := tfiber.EncodeURL(pattern, in, true, "{{.BodyField}}")
+	{{- else}}
+	path := tfiber.EncodeURL(pattern, in, false)
+	{{- end}}
+	opts = append(opts, tfiber.Operation(FiberOperation{{$svrType}}{{.OriginalName}}))
+	opts = append(opts, tfiber.PathTemplate(pattern))
+	{{- if .HasBody}}
+	err := c.cc.Invoke(ctx, "{{.HTTPMethod}}", path, in{{.Body}}, &out{{.ResponseBody}}, opts...)
+	{{- else}}
+	err := c.cc.Invoke(ctx, "{{.HTTPMethod}}", path, nil, &out{{.ResponseBody}}, opts...)
+	{{- end}}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+{{end}}
@@ -0,0 +1,44 @@
+// Package gin is the httpgen dialect of gin.
+package gin
+
+import (
+	_ "embed"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+//go:embed httpTemplate.tpl
+var httpTemplate string
+
+// Dialect generates the tgin routes, routes are added with the upper case http method.
+type Dialect struct{}
+
+var _ httpgen.Dialect = Dialect{}
+
+func (Dialect) Name() string {
+	return "gin"
+}
+
+func (Dialect) Package() protogen.GoImportPath {
+	return "github.com/LiangQinghai/kratos-ext/transport/tgin"
+}
+
+func (Dialect) Template() string {
+	return httpTemplate
+}
+
+func (Dialect) Method(method string) string {
+	return method
+}
+
+func (Dialect) Param(name string) string {
+	return ":" + name
+}
+
+func (Dialect) CatchAll(name string) string {
+	return "*" + name
+}
+
+func (Dialect) WebSocket() bool {
+	return false
+}
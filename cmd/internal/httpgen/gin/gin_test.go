@@ -0,0 +1,77 @@
+package gin
+
+import (
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"strings"
+	"testing"
+)
+
+func TestServerTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "CreateHello",
+				OriginalName: "CreateHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				Path:         "/hello/:name",
+				Method:       "POST",
+				HTTPMethod:   "POST",
+				HasVars:      true,
+				HasBody:      true,
+				Body:         ".Inner",
+				ResponseBody: ".Inner",
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"type GreeterGinServer interface",
+		`r.Handle("POST", "/hello/:name", _Greeter_CreateHello0_Gin_Handler(s, srv))`,
+		"if err := tgin.BindBody(ctx, &in.Inner); err != nil {",
+		"if err := tgin.BindPath(ctx, &in); err != nil {",
+		"tgin.SetOperation(c, GinOperationGreeterCreateHello)",
+		"s.Write(ctx, reply.Inner)",
+		"_ = ctx.Error(err)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+}
+
+func TestServerStreamTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "StreamHello",
+				OriginalName: "StreamHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				ServerStream: true,
+				Path:         "/hello/:name/stream",
+				Method:       "GET",
+				HTTPMethod:   "GET",
+				HasVars:      true,
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"StreamHello(*HelloRequest, Greeter_StreamHelloGinServer) error",
+		"type Greeter_StreamHelloGinServer interface",
+		"*tgin.Stream",
+		"s.ServeStream(ctx, stream, func() error {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+	if strings.Contains(code, "tgin.BindBody") {
+		t.Error("generated handler should not bind a body without one")
+	}
+}
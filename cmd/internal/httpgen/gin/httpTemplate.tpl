@@ -0,0 +1,104 @@
+{{$svrType := .ServiceType}}
+{{$svrName := .ServiceName}}
+
+{{- range .MethodSets}}
+const GinOperation{{$svrType}}{{.OriginalName}} = "/{{$svrName}}/{{.OriginalName}}"
+{{- end}}
+
+type {{.ServiceType}}GinServer interface {
+{{- range .MethodSets}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{- if .ServerStream}}
+	{{.Name}}(*{{.Request}}, {{$svrType}}_{{.Name}}GinServer) error
+	{{- else}}
+	{{.Name}}(context.Context, *{{.Request}}) (*{{.Reply}}, error)
+	{{- end}}
+{{- end}}
+}
+
+{{range .MethodSets}}
+{{- if .ServerStream}}
+type {{$svrType}}_{{.Name}}GinServer interface {
+	Send(*{{.Reply}}) error
+	Context() context.Context
+	LastEventID() string
+}
+
+type _{{$svrType}}_{{.Name}}GinServerImpl struct {
+	*tgin.Stream
+	ctx context.Context
+}
+
+func (x *_{{$svrType}}_{{.Name}}GinServerImpl) Send(m *{{.Reply}}) error {
+	return x.Stream.Send(m)
+}
+
+func (x *_{{$svrType}}_{{.Name}}GinServerImpl) Context() context.Context {
+	return x.ctx
+}
+{{end}}
+{{- end}}
+
+func Register{{.ServiceType}}GinServer(s *tgin.Server, srv {{.ServiceType}}GinServer) {
+	r := s.Router()
+	{{- range .Methods}}
+	r.Handle("{{.HTTPMethod}}", "{{.Path}}", _{{$svrType}}_{{.Name}}{{.Num}}_Gin_Handler(s, srv))
+	{{- end}}
+}
+
+{{range .Methods}}
+func _{{$svrType}}_{{.Name}}{{.Num}}_Gin_Handler(s *tgin.Server, srv {{$svrType}}GinServer) tgin.Handler {
+	return func(ctx *tgin.Ctx) {
+		var in {{.Request}}
+		{{- if .HasBody}}
+		if err := tgin.BindBody(ctx, &in{{.Body}}); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		{{- end}}
+		if err := tgin.BindQuery(ctx, &in); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		{{- if .HasPatternVars}}
+		if err := tgin.BindPattern(ctx, "{{.Pattern}}", &in); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		{{- else if .HasVars}}
+		if err := tgin.BindPath(ctx, &in); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		{{- end}}
+		c := ctx.Request.Context()
+		tgin.SetOperation(c, GinOperation{{$svrType}}{{.OriginalName}})
+		{{- if .ServerStream}}
+		stream := s.NewStream(ctx)
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, srv.{{.Name}}(req.(*{{.Request}}), &_{{$svrType}}_{{.Name}}GinServerImpl{stream, ctx})
+		}, c, ctx.FullPath())
+		err := s.ServeStream(ctx, stream, func() error {
+			_, err := h(stream.Context(), &in)
+			return err
+		})
+		if err != nil {
+			_ = ctx.Error(err)
+		}
+		{{- else}}
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.{{.Name}}(ctx, req.(*{{.Request}}))
+		}, c, ctx.FullPath())
+		out, err := h(c, &in)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		reply := out.(*{{.Reply}})
+		s.Write(ctx, reply{{.ResponseBody}})
+		{{- end}}
+	}
+}
+{{end}}
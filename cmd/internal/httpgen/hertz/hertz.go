@@ -0,0 +1,44 @@
+// Package hertz is the httpgen dialect of hertz.
+package hertz
+
+import (
+	_ "embed"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+//go:embed httpTemplate.tpl
+var httpTemplate string
+
+// Dialect generates the thertz routes, client and bidi streams are served over websocket.
+type Dialect struct{}
+
+var _ httpgen.Dialect = Dialect{}
+
+func (Dialect) Name() string {
+	return "hertz"
+}
+
+func (Dialect) Package() protogen.GoImportPath {
+	return "github.com/LiangQinghai/kratos-ext/transport/thertz"
+}
+
+func (Dialect) Template() string {
+	return httpTemplate
+}
+
+func (Dialect) Method(method string) string {
+	return method
+}
+
+func (Dialect) Param(name string) string {
+	return ":" + name
+}
+
+func (Dialect) CatchAll(name string) string {
+	return "*" + name
+}
+
+func (Dialect) WebSocket() bool {
+	return true
+}
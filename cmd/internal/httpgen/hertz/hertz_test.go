@@ -0,0 +1,158 @@
+package hertz
+
+import (
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"strings"
+	"testing"
+)
+
+func TestClientTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "SayHello",
+				OriginalName: "SayHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				Path:         "/hello/:name",
+				Pattern:      "/hello/{name}",
+				Method:       "GET",
+				HasVars:      true,
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"type GreeterHertzClient interface",
+		"func NewGreeterHertzClient(cc *thertz.Client) GreeterHertzClient",
+		`pattern := "/hello/{name}"`,
+		"path := thertz.EncodeURL(pattern, in, true)",
+		`err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+}
+
+func TestServerStreamTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "StreamHello",
+				OriginalName: "StreamHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				ServerStream: true,
+				Path:         "/hello/:name/stream",
+				Pattern:      "/hello/{name}/stream",
+				Method:       "GET",
+				HasVars:      true,
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"StreamHello(*HelloRequest, Greeter_StreamHelloHertzServer) error",
+		"type Greeter_StreamHelloHertzServer interface",
+		"*thertz.Stream",
+		"s.ServeStream(ctx, stream, func() error {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+	if strings.Contains(code, "func (c *_GreeterHertzClientImpl) StreamHello") {
+		t.Error("generated client should skip server-streaming methods")
+	}
+}
+
+func TestWebSocketTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "BidiHello",
+				OriginalName: "BidiHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				WebSocket:    true,
+				Bidi:         true,
+				Path:         "/hello/bidi",
+				Pattern:      "/hello/bidi",
+				Method:       "GET",
+			},
+			{
+				Name:         "RecordHello",
+				OriginalName: "RecordHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				WebSocket:    true,
+				Path:         "/hello/record",
+				Pattern:      "/hello/record",
+				Method:       "GET",
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"BidiHello(Greeter_BidiHelloHertzServer) error",
+		"func (x *_Greeter_BidiHelloHertzServerImpl) Send(m *HelloReply) error",
+		"func (x *_Greeter_RecordHelloHertzServerImpl) SendAndClose(m *HelloReply) error",
+		"func (x *_Greeter_RecordHelloHertzServerImpl) Recv() (*HelloRequest, error)",
+		"err := s.Upgrade(c, ctx, func(ws *thertz.WebSocket) error {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+	if strings.Contains(code, "func (c *_GreeterHertzClientImpl) BidiHello") {
+		t.Error("generated client should skip websocket methods")
+	}
+}
+
+func TestBodyTemplate(t *testing.T) {
+	sd := &httpgen.ServiceDesc{
+		ServiceType: "Library",
+		ServiceName: "library.Library",
+		Methods: []*httpgen.MethodDesc{
+			{
+				Name:         "CreateBook",
+				OriginalName: "CreateBook",
+				Request:      "CreateBookRequest",
+				Reply:        "Book",
+				Path:         "/v1/shelves/:parent/books",
+				Pattern:      "/v1/shelves/{parent}/books",
+				Method:       "POST",
+				HasVars:      true,
+				HasBody:      true,
+				Body:         ".Book",
+				BodyField:    "book",
+			},
+			{
+				Name:         "UpdateBook",
+				OriginalName: "UpdateBook",
+				Request:      "UpdateBookRequest",
+				Reply:        "Book",
+				Path:         "/v1/books",
+				Pattern:      "/v1/books",
+				Method:       "PUT",
+				HasBody:      true,
+			},
+		},
+	}
+	code := sd.Execute(httpTemplate)
+	for _, want := range []string{
+		"if err := thertz.BindBody(ctx, &in.Book); err != nil {",
+		"if err := ctx.Bind(&in); err != nil {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+{{$svrType := .ServiceType}}
+{{$svrName := .ServiceName}}
+
+{{- range .MethodSets}}
+const HertzOperation{{$svrType}}{{.OriginalName}} = "/{{$svrName}}/{{.OriginalName}}"
+{{- end}}
+
+type {{.ServiceType}}HertzServer interface {
+{{- range .MethodSets}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{- if .ServerStream}}
+	{{.Name}}(*{{.Request}}, {{$svrType}}_{{.Name}}HertzServer) error
+	{{- else if .WebSocket}}
+	{{.Name}}({{$svrType}}_{{.Name}}HertzServer) error
+	{{- else}}
+	{{.Name}}(context.Context, *{{.Request}}) (*{{.Reply}}, error)
+	{{- end}}
+{{- end}}
+}
+
+{{range .MethodSets}}
+{{- if .ServerStream}}
+type {{$svrType}}_{{.Name}}HertzServer interface {
+	Send(*{{.Reply}}) error
+	Context() context.Context
+	LastEventID() string
+}
+
+type _{{$svrType}}_{{.Name}}HertzServerImpl struct {
+	*thertz.Stream
+	ctx context.Context
+}
+
+func (x *_{{$svrType}}_{{.Name}}HertzServerImpl) Send(m *{{.Reply}}) error {
+	return x.Stream.Send(m)
+}
+
+func (x *_{{$svrType}}_{{.Name}}HertzServerImpl) Context() context.Context {
+	return x.ctx
+}
+{{end}}
+{{- if .WebSocket}}
+type {{$svrType}}_{{.Name}}HertzServer interface {
+	{{- if .Bidi}}
+	Send(*{{.Reply}}) error
+	{{- else}}
+	SendAndClose(*{{.Reply}}) error
+	{{- end}}
+	Recv() (*{{.Request}}, error)
+	Context() context.Context
+}
+
+type _{{$svrType}}_{{.Name}}HertzServerImpl struct {
+	*thertz.WebSocket
+}
+
+{{if .Bidi -}}
+func (x *_{{$svrType}}_{{.Name}}HertzServerImpl) Send(m *{{.Reply}}) error {
+{{- else -}}
+func (x *_{{$svrType}}_{{.Name}}HertzServerImpl) SendAndClose(m *{{.Reply}}) error {
+{{- end}}
+	return x.SendMsg(m)
+}
+
+func (x *_{{$svrType}}_{{.Name}}HertzServerImpl) Recv() (*{{.Request}}, error) {
+	m := new({{.Request}})
+	if err := x.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{end}}
+{{- end}}
+
+func Register{{.ServiceType}}HertzServer(s *thertz.Server, srv {{.ServiceType}}HertzServer) {
+	r := s.Router()
+	{{- range .Methods}}
+	r.{{.Method}}("{{.Path}}", _{{$svrType}}_{{.Name}}{{.Num}}_Hertz_Handler(s, srv))
+	{{- end}}
+}
+
+{{range .Methods}}
+func _{{$svrType}}_{{.Name}}{{.Num}}_Hertz_Handler(s *thertz.Server, srv {{$svrType}}HertzServer) thertz.Handler {
+	return func(c context.Context, ctx *thertz.ReqCtx) {
+		{{- if .WebSocket}}
+		thertz.SetOperation(c,HertzOperation{{$svrType}}{{.OriginalName}})
+		err := s.Upgrade(c, ctx, func(ws *thertz.WebSocket) error {
+			return srv.{{.Name}}(&_{{$svrType}}_{{.Name}}HertzServerImpl{ws})
+		})
+		if err != nil {
+			panic(err)
+		}
+		{{- else}}
+		var in {{.Request}}
+		{{- if ne .BodyField ""}}
+		if err := thertz.BindBody(ctx, &in{{.Body}}); err != nil {
+			panic(err)
+		}
+		{{- else if .HasBody}}
+		if err := ctx.Bind(&in); err != nil {
+			panic(err)
+		}
+		{{- end}}
+		if err := ctx.BindQuery(&in); err != nil {
+			panic(err)
+		}
+		{{- if .HasPatternVars}}
+		if err := thertz.BindPattern(ctx, "{{.Pattern}}", &in); err != nil {
+			panic(err)
+		}
+		{{- else if .HasVars}}
+		if err := ctx.BindPath(&in); err != nil {
+			panic(err)
+		}
+		{{- end}}
+		thertz.SetOperation(c,HertzOperation{{$svrType}}{{.OriginalName}})
+		{{- if .ServerStream}}
+		stream := s.NewStream(c, ctx)
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, srv.{{.Name}}(req.(*{{.Request}}), &_{{$svrType}}_{{.Name}}HertzServerImpl{stream, ctx})
+		}, c, string(ctx.Path()))
+		err := s.ServeStream(ctx, stream, func() error {
+			_, err := h(stream.Context(), &in)
+			return err
+		})
+		if err != nil {
+			panic(err)
+		}
+		{{- else}}
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.{{.Name}}(ctx, req.(*{{.Request}}))
+		}, c, string(ctx.Path()))
+		out, err := h(c, &in)
+		if err != nil {
+			panic(err)
+		}
+		reply := out.(*{{.Reply}})
+		s.Write(ctx, reply{{.ResponseBody}})
+		{{- end}}
+		{{- end}}
+	}
+}
+{{end}}
+
+type {{.ServiceType}}HertzClient interface {
+{{- range .MethodSets}}
+	{{- if or .ServerStream .WebSocket}}{{continue}}{{end}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{.Name}}(ctx context.Context, req *{{.Request}}, opts ...thertz.CallOption) (rsp *{{.Reply}}, err error)
+{{- end}}
+}
+
+type _{{.ServiceType}}HertzClientImpl struct {
+	cc *thertz.Client
+}
+
+func New{{.ServiceType}}HertzClient(cc *thertz.Client) {{.ServiceType}}HertzClient {
+	return &_{{.ServiceType}}HertzClientImpl{cc}
+}
+
+{{range .MethodSets}}
+{{- if or .ServerStream .WebSocket}}{{continue}}{{end}}
+func (c *_{{$svrType}}HertzClientImpl) {{.Name}}(ctx context.Context, in *{{.Request}}, opts ...thertz.CallOption) (*{{.Reply}}, error) {
+	var out {{.Reply}}
+	pattern := "{{.Pattern}}"
+	{{- if not .HasBody}}
+	path := thertz.EncodeURL(pattern, in, true)
+	{{- else if ne .BodyField ""}}
+	path := thertz.EncodeURL(pattern, in, true, "{{.BodyField}}")
+	{{- else}}
+	path := thertz.EncodeURL(pattern, in, false)
+	{{- end}}
+	opts = append(opts, thertz.Operation(HertzOperation{{$svrType}}{{.OriginalName}}))
+	opts = append(opts, thertz.PathTemplate(pattern))
+	{{- if .HasBody}}
+	err := c.cc.Invoke(ctx, "{{.Method}}", path, in{{.Body}}, &out{{.ResponseBody}}, opts...)
+	{{- else}}
+	err := c.cc.Invoke(ctx, "{{.Method}}", path, nil, &out{{.ResponseBody}}, opts...)
+	{{- end}}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+{{end}}
@@ -1,4 +1,6 @@
-package main
+// Package httpgen is the generator core shared by the http plugins, which
+// differ only in their Dialect.
+package httpgen
 
 import (
 	"fmt"
@@ -14,23 +16,38 @@ import (
 )
 
 const (
-	contextPackage       = protogen.GoImportPath("context")
-	transportHTTPPackage = protogen.GoImportPath("github.com/LiangQinghai/kratos-ext/transport/thertz")
-	deprecationComment   = "// Deprecated: Do not use."
+	contextPackage     = protogen.GoImportPath("context")
+	deprecationComment = "// Deprecated: Do not use."
 )
 
-var methodSets = make(map[string]int)
+// Generator generates the http code of a dialect.
+type Generator struct {
+	dialect    Dialect
+	plugin     string
+	release    string
+	methodSets map[string]int
+}
+
+// NewGenerator returns a generator of d, plugin and release name the generating plugin in the file header.
+func NewGenerator(d Dialect, plugin, release string) *Generator {
+	return &Generator{
+		dialect:    d,
+		plugin:     plugin,
+		release:    release,
+		methodSets: make(map[string]int),
+	}
+}
 
-// generateFile generates a _hertz.pb.go file containing kratos errors definitions.
-func generateFile(gen *protogen.Plugin, file *protogen.File, omitempty bool, omitemptyPrefix string) *protogen.GeneratedFile {
-	if len(file.Services) == 0 || (omitempty && !hasHTTPRule(file.Services)) {
+// GenerateFile generates a _<name>.pb.go file containing the http server and client of the services.
+func (gr *Generator) GenerateFile(gen *protogen.Plugin, file *protogen.File, omitempty bool, omitemptyPrefix string) *protogen.GeneratedFile {
+	if len(file.Services) == 0 || (omitempty && !gr.hasHTTPRule(file.Services)) {
 		return nil
 	}
-	filename := file.GeneratedFilenamePrefix + "_hertz.pb.go"
+	filename := file.GeneratedFilenamePrefix + "_" + gr.dialect.Name() + ".pb.go"
 	g := gen.NewGeneratedFile(filename, file.GoImportPath)
-	g.P("// Code generated by protoc-gen-go-hertz. DO NOT EDIT.")
+	g.P("// Code generated by ", gr.plugin, ". DO NOT EDIT.")
 	g.P("// version:")
-	g.P(fmt.Sprintf("// - protoc-gen-go-hertz %s", release))
+	g.P(fmt.Sprintf("// - %s %s", gr.plugin, gr.release))
 	g.P("// - protoc             ", protocVersion(gen))
 	if file.Proto.GetOptions().GetDeprecated() {
 		g.P("// ", file.Desc.Path(), " is a deprecated file.")
@@ -40,56 +57,60 @@ func generateFile(gen *protogen.Plugin, file *protogen.File, omitempty bool, omi
 	g.P()
 	g.P("package ", file.GoPackageName)
 	g.P()
-	generateFileContent(gen, file, g, omitempty, omitemptyPrefix)
+	gr.generateFileContent(gen, file, g, omitempty, omitemptyPrefix)
 	return g
 }
 
-// generateFileContent generates the kratos errors definitions, excluding the package statement.
-func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, omitempty bool, omitemptyPrefix string) {
+// generateFileContent generates the http definitions, excluding the package statement.
+func (gr *Generator) generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, omitempty bool, omitemptyPrefix string) {
 	if len(file.Services) == 0 {
 		return
 	}
 	g.P("var _ = new(", contextPackage.Ident("Context"), ")")
-	g.P("const _ = ", transportHTTPPackage.Ident("SupportPackageIsVersion1"))
+	g.P("const _ = ", gr.dialect.Package().Ident("SupportPackageIsVersion1"))
 	g.P()
 
 	for _, service := range file.Services {
-		genService(gen, file, g, service, omitempty, omitemptyPrefix)
+		gr.genService(gen, file, g, service, omitempty, omitemptyPrefix)
 	}
 }
 
-func genService(_ *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service, omitempty bool, omitemptyPrefix string) {
+func (gr *Generator) genService(_ *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service, omitempty bool, omitemptyPrefix string) {
 	if service.Desc.Options().(*descriptorpb.ServiceOptions).GetDeprecated() {
 		g.P("//")
 		g.P(deprecationComment)
 	}
 	// HTTP Server.
-	sd := &serviceDesc{
+	sd := &ServiceDesc{
 		ServiceType: service.GoName,
 		ServiceName: string(service.Desc.FullName()),
 		Metadata:    file.Desc.Path(),
 	}
 	for _, method := range service.Methods {
-		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+		if method.Desc.IsStreamingClient() && !gr.dialect.WebSocket() {
 			continue
 		}
 		rule, ok := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if (method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer()) && (rule == nil || !ok) {
+			// streaming methods are only served with an explicit http rule
+			continue
+		}
 		if rule != nil && ok {
 			for _, bind := range rule.AdditionalBindings {
-				sd.Methods = append(sd.Methods, buildHTTPRule(g, service, method, bind, omitemptyPrefix))
+				sd.Methods = append(sd.Methods, gr.buildStreamRule(gr.buildHTTPRule(g, service, method, bind, omitemptyPrefix)))
 			}
-			sd.Methods = append(sd.Methods, buildHTTPRule(g, service, method, rule, omitemptyPrefix))
+			sd.Methods = append(sd.Methods, gr.buildStreamRule(gr.buildHTTPRule(g, service, method, rule, omitemptyPrefix)))
 		} else if !omitempty {
 			path := fmt.Sprintf("%s/%s/%s", omitemptyPrefix, service.Desc.FullName(), method.Desc.Name())
-			sd.Methods = append(sd.Methods, buildMethodDesc(g, method, http.MethodPost, path))
+			sd.Methods = append(sd.Methods, gr.buildMethodDesc(g, method, http.MethodPost, path))
 		}
 	}
 	if len(sd.Methods) != 0 {
-		g.P(sd.execute())
+		g.P(sd.Execute(gr.dialect.Template()))
 	}
 }
 
-func buildHTTPRule(g *protogen.GeneratedFile, service *protogen.Service, m *protogen.Method, rule *annotations.HttpRule, omitemptyPrefix string) *methodDesc {
+func (gr *Generator) buildHTTPRule(g *protogen.GeneratedFile, service *protogen.Service, m *protogen.Method, rule *annotations.HttpRule, omitemptyPrefix string) *MethodDesc {
 	var (
 		path         string
 		method       string
@@ -115,7 +136,7 @@ func buildHTTPRule(g *protogen.GeneratedFile, service *protogen.Service, m *prot
 		method = http.MethodPatch
 	case *annotations.HttpRule_Custom:
 		path = pattern.Custom.Path
-		method = pattern.Custom.Kind
+		method = strings.ToUpper(pattern.Custom.Kind)
 	}
 	if method == "" {
 		method = http.MethodPost
@@ -125,7 +146,7 @@ func buildHTTPRule(g *protogen.GeneratedFile, service *protogen.Service, m *prot
 	}
 	body = rule.Body
 	responseBody = rule.ResponseBody
-	md := buildMethodDesc(g, m, method, path)
+	md := gr.buildMethodDesc(g, m, method, path)
 	if method == http.MethodGet || method == http.MethodDelete {
 		if body != "" {
 			_, _ = fmt.Fprintf(os.Stderr, "\u001B[31mWARN\u001B[m: %s %s body should not be declared.\n", method, path)
@@ -141,6 +162,7 @@ func buildHTTPRule(g *protogen.GeneratedFile, service *protogen.Service, m *prot
 	} else if body != "" {
 		md.HasBody = true
 		md.Body = "." + camelCaseVars(body)
+		md.BodyField = body
 	} else {
 		md.HasBody = false
 	}
@@ -152,16 +174,27 @@ func buildHTTPRule(g *protogen.GeneratedFile, service *protogen.Service, m *prot
 	return md
 }
 
-func buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method, method, path string) *methodDesc {
-	defer func() { methodSets[m.GoName]++ }()
+// buildStreamRule upgrades websocket methods with GET, as the websocket handshake requires.
+func (gr *Generator) buildStreamRule(md *MethodDesc) *MethodDesc {
+	if md.WebSocket && md.HTTPMethod != http.MethodGet {
+		_, _ = fmt.Fprintf(os.Stderr, "\u001B[31mWARN\u001B[m: %s %s is a websocket and served with GET.\n", md.HTTPMethod, md.Pattern)
+		md.Method = gr.dialect.Method(http.MethodGet)
+		md.HTTPMethod = http.MethodGet
+	}
+	return md
+}
+
+func (gr *Generator) buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method, method, path string) *MethodDesc {
+	defer func() { gr.methodSets[m.GoName]++ }()
 
 	vars := buildPathVars(path)
+	hasPatternVars := false
 
 	for v, s := range vars {
 		fields := m.Input.Desc.Fields()
 
 		if s != nil {
-			path = replacePath(v, *s, path)
+			hasPatternVars = true
 		}
 		for _, field := range strings.Split(v, ".") {
 			if strings.TrimSpace(field) == "" {
@@ -188,16 +221,22 @@ func buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method, method, path
 	if comment != "" {
 		comment = "// " + m.GoName + strings.TrimPrefix(strings.TrimSuffix(comment, "\n"), "//")
 	}
-	return &methodDesc{
-		Name:         m.GoName,
-		OriginalName: string(m.Desc.Name()),
-		Num:          methodSets[m.GoName],
-		Request:      g.QualifiedGoIdent(m.Input.GoIdent),
-		Reply:        g.QualifiedGoIdent(m.Output.GoIdent),
-		Comment:      comment,
-		Path:         replacePathWithHertz(path),
-		Method:       method,
-		HasVars:      len(vars) > 0,
+	return &MethodDesc{
+		Name:           m.GoName,
+		OriginalName:   string(m.Desc.Name()),
+		Num:            gr.methodSets[m.GoName],
+		Request:        g.QualifiedGoIdent(m.Input.GoIdent),
+		Reply:          g.QualifiedGoIdent(m.Output.GoIdent),
+		Comment:        comment,
+		ServerStream:   m.Desc.IsStreamingServer() && !m.Desc.IsStreamingClient(),
+		WebSocket:      m.Desc.IsStreamingClient(),
+		Bidi:           m.Desc.IsStreamingClient() && m.Desc.IsStreamingServer(),
+		Path:           RoutePath(gr.dialect, path),
+		Pattern:        path,
+		Method:         gr.dialect.Method(method),
+		HTTPMethod:     method,
+		HasVars:        len(vars) > 0,
+		HasPatternVars: hasPatternVars,
 	}
 }
 
@@ -219,41 +258,6 @@ func buildPathVars(path string) (res map[string]*string) {
 	return
 }
 
-func replacePath(name string, value string, path string) string {
-	pattern := regexp.MustCompile(fmt.Sprintf(`(?i){([\s]*%s\b[\s]*)=?([^{}]*)}`, name))
-	idx := pattern.FindStringIndex(path)
-	if len(idx) > 0 {
-		path = fmt.Sprintf("%s{%s:%s}%s",
-			path[:idx[0]], // The start of the match
-			name,
-			strings.ReplaceAll(value, "*", ".*"),
-			path[idx[1]:],
-		)
-	}
-	return path
-}
-
-func replacePathWithHertz(path string) string {
-	pattern := regexp.MustCompile(`(?i){([a-z.0-9_\s]*):?([^{}]*)}`)
-	idx := pattern.FindStringIndex(path)
-	matches := pattern.FindAllStringSubmatch(path, -1)
-	for i, m := range matches {
-		name := strings.TrimSpace(m[1])
-		if len(name) > 1 && len(m[2]) > 0 {
-			// ignore regex
-			path = strings.Replace(
-				path,
-				fmt.Sprintf("{%s:%s}", name, m[2]),
-				fmt.Sprintf(":%s", name),
-				idx[i],
-			)
-		} else {
-			path = strings.Replace(path, fmt.Sprintf("{%s}", name), fmt.Sprintf(":%s", name), idx[i])
-		}
-	}
-	return path
-}
-
 func camelCaseVars(s string) string {
 	subs := strings.Split(s, ".")
 	vars := make([]string, 0, len(subs))
@@ -320,10 +324,10 @@ func isASCIIDigit(c byte) bool {
 	return '0' <= c && c <= '9'
 }
 
-func hasHTTPRule(services []*protogen.Service) bool {
+func (gr *Generator) hasHTTPRule(services []*protogen.Service) bool {
 	for _, service := range services {
 		for _, method := range service.Methods {
-			if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+			if method.Desc.IsStreamingClient() && !gr.dialect.WebSocket() {
 				continue
 			}
 			rule, ok := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
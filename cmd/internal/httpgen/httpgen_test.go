@@ -0,0 +1,109 @@
+package httpgen
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testDialect struct{}
+
+func (testDialect) Name() string                   { return "test" }
+func (testDialect) Package() protogen.GoImportPath { return "example.com/ttest" }
+func (testDialect) Template() string               { return "{{range .Methods}}r.{{.Method}}(\"{{.Path}}\"){{end}}" }
+func (testDialect) Method(method string) string    { return strings.ToLower(method) }
+func (testDialect) WebSocket() bool                { return true }
+
+func (testDialect) Param(name string) string    { return ":" + name }
+func (testDialect) CatchAll(name string) string { return "*" + name }
+
+func TestNoParameters(t *testing.T) {
+	path := "/test/noparams"
+	m := buildPathVars(path)
+	if !reflect.DeepEqual(m, map[string]*string{}) {
+		t.Fatalf("Map should be empty")
+	}
+}
+
+func TestSingleParam(t *testing.T) {
+	path := "/test/{message.id}"
+	m := buildPathVars(path)
+	if !reflect.DeepEqual(len(m), 1) {
+		t.Fatalf("len(m) not is 1")
+	}
+	if m["message.id"] != nil {
+		t.Fatalf(`m["message.id"] should be empty`)
+	}
+}
+
+func TestTwoParametersReplacement(t *testing.T) {
+	path := "/test/{message.id}/{message.name=messages/*}"
+	m := buildPathVars(path)
+	if len(m) != 2 {
+		t.Fatal("len(m) should be 2")
+	}
+	if m["message.id"] != nil {
+		t.Fatal(`m["message.id"] should be nil`)
+	}
+	if m["message.name"] == nil {
+		t.Fatal(`m["message.name"] should not be nil`)
+	}
+	if *m["message.name"] != "messages/*" {
+		t.Fatal(`m["message.name"] should be "messages/*"`)
+	}
+}
+
+func TestRoutePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/test/noparams", "/test/noparams"},
+		{"/test/{message.id}", "/test/:message.id"},
+		{"/test/{message.id=test}", "/test/test"},
+		{"/test/{message.id=test/*}", "/test/test/:test"},
+		{"/test/{message.id}/{message.name=messages/*}", "/test/:message.id/messages/:messages"},
+		{"/test/{message.name=messages/*}/books", "/test/messages/:messages/books"},
+		{"/test/{message.namespace=*}/name/{message.name=*}", "/test/:message_namespace0/name/:message_name0"},
+		{"/v1/{name=shelves/*/books/*}", "/v1/shelves/:shelves/books/:books"},
+		{"/v1/{name=files/**}", "/v1/files/*files"},
+		{"/v1/{name=*/books/*}", "/v1/:name0/books/:books"},
+		{"/{id}/{name=*}/{id}", "/:id/:name0/:id"},
+	}
+	for _, tt := range tests {
+		if path := RoutePath(testDialect{}, tt.path); path != tt.want {
+			t.Errorf("%s: expected %s got %s", tt.path, tt.want, path)
+		}
+	}
+}
+
+func TestBuildStreamRule(t *testing.T) {
+	gr := NewGenerator(testDialect{}, "protoc-gen-go-test", "v0.0.0")
+	md := gr.buildStreamRule(&MethodDesc{WebSocket: true, Method: "post", HTTPMethod: "POST", Pattern: "/hello/bidi"})
+	if md.HTTPMethod != "GET" || md.Method != "get" {
+		t.Errorf("expected %s got %s %s", "GET", md.HTTPMethod, md.Method)
+	}
+	md = gr.buildStreamRule(&MethodDesc{Method: "post", HTTPMethod: "POST", Pattern: "/hello"})
+	if md.HTTPMethod != "POST" || md.Method != "post" {
+		t.Errorf("expected %s got %s %s", "POST", md.HTTPMethod, md.Method)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	sd := &ServiceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*MethodDesc{
+			{Name: "SayHello", Path: "/hello/:name", Method: "get"},
+			{Name: "CreateHello", Path: "/hello", Method: "post"},
+		},
+	}
+	code := sd.Execute(testDialect{}.Template())
+	if code != `r.get("/hello/:name")r.post("/hello")` {
+		t.Fatalf("unexpected code %s", code)
+	}
+	if len(sd.MethodSets) != 2 {
+		t.Fatalf("expected 2 method sets got %d", len(sd.MethodSets))
+	}
+}
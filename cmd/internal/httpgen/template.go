@@ -0,0 +1,58 @@
+package httpgen
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+type ServiceDesc struct {
+	ServiceType string // Greeter
+	ServiceName string // helloworld.Greeter
+	Metadata    string // api/helloworld/helloworld.proto
+	Methods     []*MethodDesc
+	MethodSets  map[string]*MethodDesc
+}
+
+type MethodDesc struct {
+	// method
+	Name         string
+	OriginalName string // The parsed original name
+	Num          int
+	Request      string
+	Reply        string
+	Comment      string
+	ServerStream bool // Served as server-sent events
+	WebSocket    bool // Client or bidi streaming, served as websocket
+	Bidi         bool
+	// http_rule
+	Path       string // The route path of the dialect
+	Pattern    string // The original google.api.http path, used by client
+	Method     string // The route method of the dialect
+	HTTPMethod string // The upper case http method
+	HasVars    bool
+	// HasPatternVars reports whether a var declares a pattern such as {name=messages/*},
+	// its value may span several segments and is bound with the Pattern.
+	HasPatternVars bool
+	HasBody        bool
+	Body           string
+	BodyField      string // The proto field path of body, excluded from query by client
+	ResponseBody   string
+}
+
+// Execute executes the service template tpl.
+func (s *ServiceDesc) Execute(tpl string) string {
+	s.MethodSets = make(map[string]*MethodDesc)
+	for _, m := range s.Methods {
+		s.MethodSets[m.Name] = m
+	}
+	buf := new(bytes.Buffer)
+	tmpl, err := template.New("http").Parse(strings.TrimSpace(tpl))
+	if err != nil {
+		panic(err)
+	}
+	if err := tmpl.Execute(buf, s); err != nil {
+		panic(err)
+	}
+	return strings.Trim(buf.String(), "\r\n")
+}
@@ -0,0 +1,119 @@
+// Package rpcxgen generates the trpcx servers and clients of protobuf services.
+package rpcxgen
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"strings"
+)
+
+const (
+	contextPackage       = protogen.GoImportPath("context")
+	transportRpcxPackage = protogen.GoImportPath("github.com/LiangQinghai/kratos-ext/transport/trpcx")
+	deprecationComment   = "// Deprecated: Do not use."
+)
+
+// Generator generates the rpcx code of the services.
+type Generator struct {
+	plugin     string
+	release    string
+	methodSets map[string]int
+}
+
+// NewGenerator returns a generator, plugin and release name the generating plugin in the file header.
+func NewGenerator(plugin, release string) *Generator {
+	return &Generator{
+		plugin:     plugin,
+		release:    release,
+		methodSets: make(map[string]int),
+	}
+}
+
+// GenerateFile generates a _rpcx.pb.go file containing the rpcx server and client of the services.
+func (gr *Generator) GenerateFile(gen *protogen.Plugin, file *protogen.File) *protogen.GeneratedFile {
+	if len(file.Services) == 0 {
+		return nil
+	}
+	filename := file.GeneratedFilenamePrefix + "_rpcx.pb.go"
+	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+	g.P("// Code generated by ", gr.plugin, ". DO NOT EDIT.")
+	g.P("// version:")
+	g.P(fmt.Sprintf("// - %s %s", gr.plugin, gr.release))
+	g.P("// - protoc             ", protocVersion(gen))
+	if file.Proto.GetOptions().GetDeprecated() {
+		g.P("// ", file.Desc.Path(), " is a deprecated file.")
+	} else {
+		g.P("// source: ", file.Desc.Path())
+	}
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+	gr.generateFileContent(gen, file, g)
+	return g
+}
+
+// generateFileContent generates the rpcx server and client of the services, excluding the package statement.
+func (gr *Generator) generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile) {
+	if len(file.Services) == 0 {
+		return
+	}
+	g.P("var _ = new(", contextPackage.Ident("Context"), ")")
+	g.P("const _ = ", transportRpcxPackage.Ident("SupportPackageIsVersion1"))
+	g.P()
+
+	for _, service := range file.Services {
+		gr.genService(gen, file, g, service)
+	}
+}
+
+func (gr *Generator) genService(_ *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service) {
+	if service.Desc.Options().(*descriptorpb.ServiceOptions).GetDeprecated() {
+		g.P("//")
+		g.P(deprecationComment)
+	}
+	// rpcx Server.
+	sd := &serviceDesc{
+		ServiceType: service.GoName,
+		ServiceName: string(service.Desc.FullName()),
+		Metadata:    file.Desc.Path(),
+	}
+	for _, method := range service.Methods {
+		// rpcx calls are unary, the streaming methods are left out
+		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+			continue
+		}
+		sd.Methods = append(sd.Methods, gr.buildMethodDesc(g, method))
+	}
+	if len(sd.Methods) != 0 {
+		g.P(sd.execute())
+	}
+}
+
+func (gr *Generator) buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method) *methodDesc {
+	defer func() { gr.methodSets[m.GoName]++ }()
+	comment := m.Comments.Leading.String() + m.Comments.Trailing.String()
+	if comment != "" {
+		comment = "// " + m.GoName + strings.TrimPrefix(strings.TrimSuffix(comment, "\n"), "//")
+	}
+	return &methodDesc{
+		Name:         m.GoName,
+		OriginalName: string(m.Desc.Name()),
+		Num:          gr.methodSets[m.GoName],
+		Request:      g.QualifiedGoIdent(m.Input.GoIdent),
+		Reply:        g.QualifiedGoIdent(m.Output.GoIdent),
+		Comment:      comment,
+	}
+}
+
+func protocVersion(gen *protogen.Plugin) string {
+	v := gen.Request.GetCompilerVersion()
+	if v == nil {
+		return "(unknown)"
+	}
+	var suffix string
+	if s := v.GetSuffix(); s != "" {
+		suffix = "-" + s
+	}
+	return fmt.Sprintf("v%d.%d.%d%s", v.GetMajor(), v.GetMinor(), v.GetPatch(), suffix)
+}
@@ -0,0 +1,66 @@
+{{$svrType := .ServiceType}}
+{{$svrName := .ServiceName}}
+
+{{- range .MethodSets}}
+const RpcxOperation{{$svrType}}{{.OriginalName}} = "/{{$svrName}}/{{.OriginalName}}"
+{{- end}}
+
+type {{.ServiceType}}RpcxServer interface {
+{{- range .MethodSets}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{.Name}}(context.Context, *{{.Request}}) (*{{.Reply}}, error)
+{{- end}}
+}
+
+func Register{{.ServiceType}}RpcxServer(s *trpcx.Server, srv {{.ServiceType}}RpcxServer) {
+	{{- range .Methods}}
+	s.Handle(RpcxOperation{{$svrType}}{{.OriginalName}}, _{{$svrType}}_{{.Name}}{{.Num}}_Rpcx_Handler(s, srv))
+	{{- end}}
+}
+
+{{range .Methods}}
+func _{{$svrType}}_{{.Name}}{{.Num}}_Rpcx_Handler(s *trpcx.Server, srv {{$svrType}}RpcxServer) func(context.Context, *{{.Request}}, *{{.Reply}}) error {
+	return func(ctx context.Context, in *{{.Request}}, out *{{.Reply}}) error {
+		ctx, cancel := s.Timeout(s.NewContext(ctx, RpcxOperation{{$svrType}}{{.OriginalName}}))
+		defer cancel()
+		h := s.Middleware(ctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.{{.Name}}(ctx, req.(*{{.Request}}))
+		})
+		reply, err := h(ctx, in)
+		if err != nil {
+			return err
+		}
+		return s.Reply(out, reply)
+	}
+}
+{{end}}
+
+type {{.ServiceType}}RpcxClient interface {
+{{- range .MethodSets}}
+	{{- if ne .Comment ""}}
+	{{.Comment}}
+	{{- end}}
+	{{.Name}}(context.Context, *{{.Request}}) (*{{.Reply}}, error)
+{{- end}}
+}
+
+type _{{.ServiceType}}RpcxClientImpl struct {
+	cc *trpcx.Client
+}
+
+func New{{.ServiceType}}RpcxClient(cc *trpcx.Client) {{.ServiceType}}RpcxClient {
+	return &_{{.ServiceType}}RpcxClientImpl{cc}
+}
+
+{{range .Methods}}
+func (c *_{{$svrType}}RpcxClientImpl) {{.Name}}(ctx context.Context, req *{{.Request}}) (*{{.Reply}}, error) {
+	out := new({{.Reply}})
+	err := c.cc.Call(ctx, RpcxOperation{{$svrType}}{{.OriginalName}}, req, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+{{end}}
@@ -0,0 +1,37 @@
+package rpcxgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	sd := &serviceDesc{
+		ServiceType: "Greeter",
+		ServiceName: "helloworld.Greeter",
+		Methods: []*methodDesc{
+			{
+				Name:         "SayHello",
+				OriginalName: "SayHello",
+				Request:      "HelloRequest",
+				Reply:        "HelloReply",
+				Comment:      "// SayHello says hello",
+			},
+		},
+	}
+	code := sd.execute()
+	for _, want := range []string{
+		`const RpcxOperationGreeterSayHello = "/helloworld.Greeter/SayHello"`,
+		"type GreeterRpcxServer interface",
+		"// SayHello says hello",
+		"s.Handle(RpcxOperationGreeterSayHello, _Greeter_SayHello0_Rpcx_Handler(s, srv))",
+		"return func(ctx context.Context, in *HelloRequest, out *HelloReply) error {",
+		"return s.Reply(out, reply)",
+		"func NewGreeterRpcxClient(cc *trpcx.Client) GreeterRpcxClient {",
+		"err := c.cc.Call(ctx, RpcxOperationGreeterSayHello, req, out)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code should contain %q", want)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package rpcxgen
 
 import (
 	"bytes"
@@ -7,8 +7,8 @@ import (
 	"text/template"
 )
 
-//go:embed httpTemplate.tpl
-var httpTemplate string
+//go:embed rpcxTemplate.tpl
+var rpcxTemplate string
 
 type serviceDesc struct {
 	ServiceType string // Greeter
@@ -26,13 +26,6 @@ type methodDesc struct {
 	Request      string
 	Reply        string
 	Comment      string
-	// http_rule
-	Path         string
-	Method       string
-	HasVars      bool
-	HasBody      bool
-	Body         string
-	ResponseBody string
 }
 
 func (s *serviceDesc) execute() string {
@@ -41,7 +34,7 @@ func (s *serviceDesc) execute() string {
 		s.MethodSets[m.Name] = m
 	}
 	buf := new(bytes.Buffer)
-	tmpl, err := template.New("http").Parse(strings.TrimSpace(httpTemplate))
+	tmpl, err := template.New("rpcx").Parse(strings.TrimSpace(rpcxTemplate))
 	if err != nil {
 		panic(err)
 	}
@@ -1,13 +0,0 @@
-module github.com/LiangQinghai/kratos-ext/cmd/protoc-gen-go-arpc
-
-go 1.22
-
-require (
-	google.golang.org/genproto/googleapis/api v0.0.0-20240521202816-d264139d666e
-	google.golang.org/protobuf v1.34.1
-)
-
-require (
-	github.com/google/go-cmp v0.5.6 // indirect
-	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
-)
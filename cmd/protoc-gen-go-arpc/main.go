@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/arpcgen"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/types/pluginpb"
 )
@@ -16,7 +17,7 @@ func main() {
 	flag.Parse()
 
 	if *showVersion {
-		fmt.Printf("protoc-gen-go-fiber %v\n", release)
+		fmt.Printf("protoc-gen-go-arpc %v\n", release)
 		return
 	}
 
@@ -24,11 +25,12 @@ func main() {
 		ParamFunc: flag.CommandLine.Set,
 	}.Run(func(gen *protogen.Plugin) error {
 		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		g := arpcgen.NewGenerator("protoc-gen-go-arpc", release)
 		for _, f := range gen.Files {
 			if !f.Generate {
 				continue
 			}
-			generateFile(gen, f)
+			g.GenerateFile(gen, f)
 		}
 		return nil
 	})
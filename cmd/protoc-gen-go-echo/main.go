@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/echo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var (
+	showVersion     = flag.Bool("version", false, "print the version and exit")
+	omitempty       = flag.Bool("omitempty", true, "omit if google.api is empty")
+	omitemptyPrefix = flag.String("omitempty_prefix", "", "omit if google.api is empty")
+)
+
+func main() {
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("protoc-gen-go-echo %v\n", release)
+		return
+	}
+
+	protogen.Options{
+		ParamFunc: flag.CommandLine.Set,
+	}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		g := httpgen.NewGenerator(echo.Dialect{}, "protoc-gen-go-echo", release)
+		for _, f := range gen.Files {
+			if !f.Generate {
+				continue
+			}
+			g.GenerateFile(gen, f, *omitempty, *omitemptyPrefix)
+		}
+		return nil
+	})
+
+}
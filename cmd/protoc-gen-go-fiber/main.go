@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/fiber"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/types/pluginpb"
 )
@@ -26,11 +28,12 @@ func main() {
 		ParamFunc: flag.CommandLine.Set,
 	}.Run(func(gen *protogen.Plugin) error {
 		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		g := httpgen.NewGenerator(fiber.Dialect{}, "protoc-gen-go-fiber", release)
 		for _, f := range gen.Files {
 			if !f.Generate {
 				continue
 			}
-			generateFile(gen, f, *omitempty, *omitemptyPrefix)
+			g.GenerateFile(gen, f, *omitempty, *omitemptyPrefix)
 		}
 		return nil
 	})
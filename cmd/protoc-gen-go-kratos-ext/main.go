@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const plugin = "protoc-gen-go-kratos-ext"
+
+var (
+	showVersion     = flag.Bool("version", false, "print the version and exit")
+	omitempty       = flag.Bool("omitempty", true, "omit if google.api is empty")
+	omitemptyPrefix = flag.String("omitempty_prefix", "", "omit if google.api is empty")
+	targets         targetsFlag
+)
+
+func init() {
+	flag.Var(&targets, "targets", "the frameworks to generate, e.g. hertz,fiber,arpc")
+}
+
+func main() {
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("%s %v\n", plugin, release)
+		return
+	}
+
+	protogen.Options{
+		ParamFunc: paramFunc(flag.CommandLine.Set),
+	}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		return generate(gen, targets)
+	})
+
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/arpcgen"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/echo"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/fiber"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/gin"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/httpgen/hertz"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/rpcxgen"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"os"
+	"strings"
+)
+
+const (
+	fileOption    protoreflect.FullName = "kratos.ext.file"
+	serviceOption protoreflect.FullName = "kratos.ext.service"
+)
+
+// target is a framework the plugin generates code for.
+type target struct {
+	name     string
+	generate func(gen *protogen.Plugin, file *protogen.File) *protogen.GeneratedFile
+}
+
+// newTargets returns the targets in the order their files are generated.
+func newTargets() []*target {
+	http := func(d httpgen.Dialect) *target {
+		g := httpgen.NewGenerator(d, plugin, release)
+		return &target{
+			name: d.Name(),
+			generate: func(gen *protogen.Plugin, file *protogen.File) *protogen.GeneratedFile {
+				return g.GenerateFile(gen, file, *omitempty, *omitemptyPrefix)
+			},
+		}
+	}
+	return []*target{
+		http(hertz.Dialect{}),
+		http(fiber.Dialect{}),
+		http(echo.Dialect{}),
+		http(gin.Dialect{}),
+		{name: "arpc", generate: arpcgen.NewGenerator(plugin, release).GenerateFile},
+		{name: "rpcx", generate: rpcxgen.NewGenerator(plugin, release).GenerateFile},
+	}
+}
+
+// targetsFlag is the targets parameter, a comma separated list of target names.
+type targetsFlag []string
+
+func (t *targetsFlag) String() string {
+	return strings.Join(*t, ",")
+}
+
+func (t *targetsFlag) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*t = append(*t, name)
+		}
+	}
+	return nil
+}
+
+// paramFunc wraps set, protogen splits the parameter at commas,
+// so targets=hertz,fiber arrives as targets=hertz followed by a bare fiber.
+func paramFunc(set func(name, value string) error) func(name, value string) error {
+	var last string
+	return func(name, value string) error {
+		if value == "" && last == "targets" {
+			return set(last, name)
+		}
+		last = name
+		return set(name, value)
+	}
+}
+
+// generate generates the files of the targets of every service, defaults are
+// the targets parameter, overridden by the kratos.ext.file and kratos.ext.service options.
+func generate(gen *protogen.Plugin, defaults []string) error {
+	targets := newTargets()
+	known := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		known[t.name] = true
+	}
+	if err := checkTargets("the targets parameter", defaults, known); err != nil {
+		return err
+	}
+	types := extensionTypes(gen)
+	for _, f := range gen.Files {
+		if !f.Generate || len(f.Services) == 0 {
+			continue
+		}
+		selected, err := selectTargets(f, types, defaults, known)
+		if err != nil {
+			return err
+		}
+		for _, t := range targets {
+			var services []*protogen.Service
+			for _, service := range f.Services {
+				if selected[service][t.name] {
+					services = append(services, service)
+				}
+			}
+			if len(services) == 0 {
+				continue
+			}
+			// the generators take every service of a file, the copy holds the services of t
+			file := *f
+			file.Services = services
+			t.generate(gen, &file)
+		}
+	}
+	return nil
+}
+
+// extensionTypes returns the kratos.ext options declared by the files of the request. The plugin
+// does not link the ext package of the root module, the options are read with dynamicpb.
+func extensionTypes(gen *protogen.Plugin) *protoregistry.Types {
+	types := new(protoregistry.Types)
+	for _, f := range gen.Files {
+		xds := f.Desc.Extensions()
+		for i := 0; i < xds.Len(); i++ {
+			if xd := xds.Get(i); xd.FullName() == fileOption || xd.FullName() == serviceOption {
+				_ = types.RegisterExtension(dynamicpb.NewExtensionType(xd))
+			}
+		}
+	}
+	return types
+}
+
+// selectTargets returns the targets of every service of file.
+func selectTargets(file *protogen.File, types *protoregistry.Types, defaults []string, known map[string]bool) (map[*protogen.Service]map[string]bool, error) {
+	fileTargets, overridden, err := options(file.Desc.Options(), types, fileOption)
+	if err != nil {
+		return nil, err
+	}
+	if overridden {
+		if err := checkTargets(fmt.Sprintf("the kratos.ext.file option of %s", file.Desc.Path()), fileTargets, known); err != nil {
+			return nil, err
+		}
+	} else {
+		fileTargets = defaults
+	}
+	selected := make(map[*protogen.Service]map[string]bool, len(file.Services))
+	for _, service := range file.Services {
+		names, ok, err := options(service.Desc.Options(), types, serviceOption)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if err := checkTargets(fmt.Sprintf("the kratos.ext.service option of %s", service.Desc.FullName()), names, known); err != nil {
+				return nil, err
+			}
+			overridden = true
+		} else {
+			names = fileTargets
+		}
+		selected[service] = make(map[string]bool, len(names))
+		for _, name := range names {
+			selected[service][name] = true
+		}
+	}
+	if !overridden && len(defaults) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "\u001B[31mWARN\u001B[m: %s has no targets, set the targets parameter or the kratos.ext.file option.\n", file.Desc.Path())
+	}
+	return selected, nil
+}
+
+// options returns the targets of the option name of opts, ok reports whether it is set.
+// The option is among the unknown fields of opts, so opts is parsed again with types into
+// the options message of the request, which declares the extension.
+func options(opts protoreflect.ProtoMessage, types *protoregistry.Types, name protoreflect.FullName) (targets []string, ok bool, err error) {
+	xt, err := types.FindExtensionByName(name)
+	if err != nil || opts == nil || !opts.ProtoReflect().IsValid() {
+		return nil, false, nil
+	}
+	b, err := proto.Marshal(opts)
+	if err != nil {
+		return nil, false, err
+	}
+	xd := xt.TypeDescriptor()
+	m := dynamicpb.NewMessage(xd.ContainingMessage())
+	if err = (proto.UnmarshalOptions{Resolver: types}).Unmarshal(b, m); err != nil {
+		return nil, false, fmt.Errorf("read the %s option: %w", name, err)
+	}
+	if !m.Has(xd) {
+		return nil, false, nil
+	}
+	msg := m.Get(xd).Message()
+	field := msg.Descriptor().Fields().ByName("targets")
+	if field == nil || !field.IsList() {
+		return nil, false, fmt.Errorf("the %s option has no targets", name)
+	}
+	list := msg.Get(field).List()
+	targets = make([]string, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		targets = append(targets, list.Get(i).String())
+	}
+	return targets, true, nil
+}
+
+func checkTargets(source string, names []string, known map[string]bool) error {
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("unknown target %q in %s", name, source)
+		}
+	}
+	return nil
+}
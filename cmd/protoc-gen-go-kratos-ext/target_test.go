@@ -0,0 +1,223 @@
+package main
+
+import (
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/pluginpb"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// optionsProto is ext/options.proto of the root module, which the plugin does not link.
+var optionsProto = &descriptorpb.FileDescriptorProto{
+	Name:       proto.String("ext/options.proto"),
+	Package:    proto.String("kratos.ext"),
+	Syntax:     proto.String("proto3"),
+	Dependency: []string{"google/protobuf/descriptor.proto"},
+	Options:    &descriptorpb.FileOptions{GoPackage: proto.String("github.com/LiangQinghai/kratos-ext/ext;ext")},
+	MessageType: []*descriptorpb.DescriptorProto{{
+		Name: proto.String("Options"),
+		Field: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("targets"),
+			Number:   proto.Int32(1),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			JsonName: proto.String("targets"),
+		}},
+	}},
+	Extension: []*descriptorpb.FieldDescriptorProto{
+		optionsExtension("file", ".google.protobuf.FileOptions"),
+		optionsExtension("service", ".google.protobuf.ServiceOptions"),
+	},
+}
+
+func optionsExtension(name, extendee string) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(52101),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+		TypeName: proto.String(".kratos.ext.Options"),
+		Extendee: proto.String(extendee),
+		JsonName: proto.String(name),
+	}
+}
+
+// setTargets sets the targets of the option name of opts.
+func setTargets(t *testing.T, opts proto.Message, name protoreflect.Name, targets []string) {
+	fd, err := protodesc.NewFile(optionsProto, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := dynamicpb.NewMessage(fd.Messages().ByName("Options"))
+	list := msg.Mutable(msg.Descriptor().Fields().ByName("targets")).List()
+	for _, target := range targets {
+		list.Append(protoreflect.ValueOfString(target))
+	}
+	proto.SetExtension(opts, dynamicpb.NewExtensionType(fd.Extensions().ByName(name)), msg)
+}
+
+func newService(t *testing.T, name string, targets []string) *descriptorpb.ServiceDescriptorProto {
+	mo := &descriptorpb.MethodOptions{}
+	proto.SetExtension(mo, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/" + strings.ToLower(name) + "/{name}"},
+	})
+	so := &descriptorpb.ServiceOptions{}
+	if targets != nil {
+		setTargets(t, so, "service", targets)
+	}
+	return &descriptorpb.ServiceDescriptorProto{
+		Name:    proto.String(name),
+		Options: so,
+		Method: []*descriptorpb.MethodDescriptorProto{{
+			Name:       proto.String("SayHello"),
+			InputType:  proto.String(".hello.HelloRequest"),
+			OutputType: proto.String(".hello.HelloReply"),
+			Options:    mo,
+		}},
+	}
+}
+
+func newPlugin(t *testing.T, fileTargets []string, services ...*descriptorpb.ServiceDescriptorProto) *protogen.Plugin {
+	fo := &descriptorpb.FileOptions{GoPackage: proto.String("example.com/hello;hello")}
+	if fileTargets != nil {
+		setTargets(t, fo, "file", fileTargets)
+	}
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("hello/hello.proto"),
+		Package:    proto.String("hello"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/api/annotations.proto", "ext/options.proto"},
+		Options:    fo,
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("HelloRequest"), Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("name"), Number: proto.Int32(1), Type: str, JsonName: proto.String("name")},
+			}},
+			{Name: proto.String("HelloReply")},
+		},
+		Service: services,
+	}
+	gen, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"hello/hello.proto"},
+		Parameter:      proto.String("paths=source_relative"),
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_http_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_annotations_proto),
+			optionsProto,
+			file,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gen
+}
+
+func generated(gen *protogen.Plugin) map[string]string {
+	files := make(map[string]string)
+	for _, f := range gen.Response().File {
+		files[f.GetName()] = f.GetContent()
+	}
+	return files
+}
+
+func names(files map[string]string) []string {
+	res := make([]string, 0, len(files))
+	for name := range files {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestTargetsFlag(t *testing.T) {
+	var targets targetsFlag
+	set := paramFunc(func(name, value string) error {
+		if name != "targets" {
+			t.Fatalf("unexpected parameter %s", name)
+		}
+		return targets.Set(value)
+	})
+	// protogen hands targets=hertz,fiber,arpc over as three parameters
+	for _, p := range [][2]string{{"targets", "hertz"}, {"fiber", ""}, {"arpc", ""}} {
+		if err := set(p[0], p[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual([]string(targets), []string{"hertz", "fiber", "arpc"}) {
+		t.Fatalf("unexpected targets %v", targets)
+	}
+	if targets.String() != "hertz,fiber,arpc" {
+		t.Fatalf("unexpected targets %s", targets.String())
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	gen := newPlugin(t, nil, newService(t, "Greeter", nil))
+	if err := generate(gen, []string{"hertz", "fiber", "arpc"}); err != nil {
+		t.Fatal(err)
+	}
+	files := generated(gen)
+	want := []string{"hello/hello_arpc.pb.go", "hello/hello_fiber.pb.go", "hello/hello_hertz.pb.go"}
+	if !reflect.DeepEqual(names(files), want) {
+		t.Fatalf("expected %v got %v", want, names(files))
+	}
+	for name, content := range files {
+		if !strings.Contains(content, "// Code generated by protoc-gen-go-kratos-ext. DO NOT EDIT.") {
+			t.Errorf("%s should be generated by %s", name, plugin)
+		}
+	}
+	if !strings.Contains(files["hello/hello_fiber.pb.go"], "type GreeterFiberServer interface") {
+		t.Error("fiber server is not generated")
+	}
+}
+
+func TestGenerateOverrides(t *testing.T) {
+	gen := newPlugin(t, []string{"gin"},
+		newService(t, "Greeter", nil),
+		newService(t, "Admin", []string{"rpcx", "gin"}),
+		newService(t, "Internal", []string{}),
+	)
+	if err := generate(gen, []string{"hertz"}); err != nil {
+		t.Fatal(err)
+	}
+	files := generated(gen)
+	want := []string{"hello/hello_gin.pb.go", "hello/hello_rpcx.pb.go"}
+	if !reflect.DeepEqual(names(files), want) {
+		t.Fatalf("expected %v got %v", want, names(files))
+	}
+	gin := files["hello/hello_gin.pb.go"]
+	for _, svc := range []string{"GreeterGinServer", "AdminGinServer"} {
+		if !strings.Contains(gin, "type "+svc+" interface") {
+			t.Errorf("gin file should contain %s", svc)
+		}
+	}
+	if strings.Contains(gin, "InternalGinServer") {
+		t.Error("gin file should not contain the internal service")
+	}
+	rpcx := files["hello/hello_rpcx.pb.go"]
+	if !strings.Contains(rpcx, "type AdminRpcxServer interface") || strings.Contains(rpcx, "GreeterRpcxServer") {
+		t.Error("rpcx file should only contain the admin service")
+	}
+}
+
+func TestGenerateUnknownTarget(t *testing.T) {
+	gen := newPlugin(t, nil, newService(t, "Greeter", nil))
+	if err := generate(gen, []string{"hertz", "grpc"}); err == nil || !strings.Contains(err.Error(), `"grpc"`) {
+		t.Fatalf("expected unknown target error got %v", err)
+	}
+	gen = newPlugin(t, nil, newService(t, "Greeter", []string{"beego"}))
+	if err := generate(gen, []string{"hertz"}); err == nil || !strings.Contains(err.Error(), "hello.Greeter") {
+		t.Fatalf("expected unknown target error got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/cmd/internal/rpcxgen"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var (
+	showVersion = flag.Bool("version", false, "print the version and exit")
+)
+
+func main() {
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("protoc-gen-go-rpcx %v\n", release)
+		return
+	}
+
+	protogen.Options{
+		ParamFunc: flag.CommandLine.Set,
+	}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		g := rpcxgen.NewGenerator("protoc-gen-go-rpcx", release)
+		for _, f := range gen.Files {
+			if !f.Generate {
+				continue
+			}
+			g.GenerateFile(gen, f)
+		}
+		return nil
+	})
+
+}
@@ -2,4 +2,15 @@ module github.com/LiangQinghai/kratos-ext
 
 go 1.22
 
-require github.com/go-kratos/kratos/v2 v2.7.3
+require (
+	github.com/go-kratos/kratos/v2 v2.7.3
+	google.golang.org/protobuf v1.31.0
+)
+
+require (
+	github.com/go-playground/form/v4 v4.2.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230629202037-9506855d4529 // indirect
+	google.golang.org/grpc v1.56.3 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
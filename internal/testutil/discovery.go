@@ -0,0 +1,77 @@
+// Package testutil holds the fixtures shared by the tests of the transports.
+package testutil
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/registry"
+	"sync"
+	"sync/atomic"
+)
+
+var _ registry.Discovery = (*Discovery)(nil)
+
+// Discovery is a registry.Discovery of the instances given to NewDiscovery, its watchers
+// return them first, if any, then every snapshot sent with Update.
+type Discovery struct {
+	instances []*registry.ServiceInstance
+	snapshots chan []*registry.ServiceInstance
+	watchers  atomic.Int32
+}
+
+// NewDiscovery returns a Discovery of instances.
+func NewDiscovery(instances ...*registry.ServiceInstance) *Discovery {
+	return &Discovery{
+		instances: instances,
+		snapshots: make(chan []*registry.ServiceInstance),
+	}
+}
+
+func (d *Discovery) GetService(context.Context, string) ([]*registry.ServiceInstance, error) {
+	return d.instances, nil
+}
+
+func (d *Discovery) Watch(ctx context.Context, _ string) (registry.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	d.watchers.Add(1)
+	return &watcher{d: d, sent: len(d.instances) == 0, ctx: ctx, cancel: cancel}, nil
+}
+
+// Update sends a snapshot of instances to a watcher, it blocks until a watcher takes it.
+// Sending a snapshot twice makes sure the first one has been applied.
+func (d *Discovery) Update(instances []*registry.ServiceInstance) {
+	d.snapshots <- instances
+}
+
+// Watchers returns the number of the watchers not stopped yet.
+func (d *Discovery) Watchers() int {
+	return int(d.watchers.Load())
+}
+
+type watcher struct {
+	d      *Discovery
+	sent   bool
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   sync.Once
+}
+
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.sent {
+		w.sent = true
+		return w.d.instances, nil
+	}
+	select {
+	case instances := <-w.d.snapshots:
+		return instances, nil
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.stop.Do(func() {
+		w.cancel()
+		w.d.watchers.Add(-1)
+	})
+	return nil
+}
@@ -0,0 +1,109 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/pkg/binding"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/json"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// LibraryServer implements the generated Library servers, it echoes the fields bound from the path.
+type LibraryServer struct{}
+
+func (LibraryServer) GetBook(_ context.Context, in *GetBookRequest) (*Book, error) {
+	return &Book{Name: in.Name}, nil
+}
+
+func (LibraryServer) ListBooks(_ context.Context, in *ListBooksRequest) (*ListBooksReply, error) {
+	return &ListBooksReply{Parent: in.Parent, Limit: in.Limit}, nil
+}
+
+func (LibraryServer) GetFile(_ context.Context, in *GetFileRequest) (*File, error) {
+	return &File{Name: in.Name}, nil
+}
+
+// LibraryCall is a call of a Library method and the reply of LibraryServer.
+type LibraryCall struct {
+	Method   string
+	Template string // the path template of the method
+	In       proto.Message
+	Want     proto.Message
+}
+
+// LibraryCalls span the path templates whose vars have several segments.
+var LibraryCalls = []LibraryCall{
+	{
+		Method:   "GetBook",
+		Template: "/v1/{name=shelves/*/books/*}",
+		In:       &GetBookRequest{Name: "shelves/1/books/a b"},
+		Want:     &Book{Name: "shelves/1/books/a b"},
+	},
+	{
+		Method:   "ListBooks",
+		Template: "/v1/{parent=shelves/*}/books",
+		In:       &ListBooksRequest{Parent: "shelves/1", Limit: 10},
+		Want:     &ListBooksReply{Parent: "shelves/1", Limit: 10},
+	},
+	{
+		Method:   "GetFile",
+		Template: "/v1/{name=files/**}",
+		In:       &GetFileRequest{Name: "files/a/b.txt"},
+		Want:     &File{Name: "files/a/b.txt"},
+	},
+}
+
+// CheckLibrary makes the LibraryCalls with call and checks their replies.
+func CheckLibrary(t *testing.T, call func(c LibraryCall) (proto.Message, error)) {
+	t.Helper()
+	for _, c := range LibraryCalls {
+		reply, err := call(c)
+		if err != nil {
+			t.Errorf("%s: %v", c.Method, err)
+			continue
+		}
+		if !proto.Equal(reply, c.Want) {
+			t.Errorf("%s: expected %v got %v", c.Method, c.Want, reply)
+		}
+	}
+}
+
+// LibraryClient calls the methods of a generated Library client.
+func LibraryClient(ctx context.Context, client any) func(c LibraryCall) (proto.Message, error) {
+	return func(c LibraryCall) (proto.Message, error) {
+		out := reflect.ValueOf(client).MethodByName(c.Method).Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(c.In)})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		return out[0].Interface().(proto.Message), nil
+	}
+}
+
+// LibraryGet requests base with client and the path of the template expanded with the request,
+// as the generated clients do.
+func LibraryGet(client *http.Client, base string) func(c LibraryCall) (proto.Message, error) {
+	return func(c LibraryCall) (proto.Message, error) {
+		res, err := client.Get(base + binding.EncodeURL(c.Template, c.In, true))
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		data, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%d %s", res.StatusCode, data)
+		}
+		reply := c.Want.ProtoReflect().New().Interface()
+		if err = encoding.GetCodec(json.Name).Unmarshal(data, reply); err != nil {
+			return nil, err
+		}
+		return reply, nil
+	}
+}
@@ -0,0 +1,91 @@
+package binding
+
+import (
+	"github.com/go-kratos/kratos/v2/encoding/form"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var pathVarPattern = regexp.MustCompile(`(?i){([a-z.0-9_\s]*)=?([^{}]*)}`)
+
+// EncodeURL encode proto message to url path, both {var} and {var=pattern}
+// path templates are expanded with the field values of msg.
+// When needQuery is true, the fields which are neither bound to path vars
+// nor listed in excludes (for example the body field) are encoded as query params.
+func EncodeURL(pathTemplate string, msg interface{}, needQuery bool, excludes ...string) string {
+	if msg == nil || (reflect.ValueOf(msg).Kind() == reflect.Ptr && reflect.ValueOf(msg).IsNil()) {
+		return pathTemplate
+	}
+	var md protoreflect.MessageDescriptor
+	if m, ok := msg.(proto.Message); ok {
+		md = m.ProtoReflect().Descriptor()
+	}
+	queryParams, _ := form.EncodeValues(msg)
+	pathParams := make([]string, 0)
+	path := pathVarPattern.ReplaceAllStringFunc(pathTemplate, func(in string) string {
+		m := pathVarPattern.FindStringSubmatch(in)
+		key := fieldKey(md, strings.TrimSpace(m[1]))
+		pathParams = append(pathParams, key)
+		value := queryParams.Get(key)
+		if m[2] == "" {
+			return url.PathEscape(value)
+		}
+		// {var=pattern} may match several segments
+		segments := strings.Split(value, "/")
+		for i, s := range segments {
+			segments[i] = url.PathEscape(s)
+		}
+		return strings.Join(segments, "/")
+	})
+	if !needQuery || len(queryParams) == 0 {
+		return path
+	}
+	for _, key := range pathParams {
+		delete(queryParams, key)
+	}
+	for _, exclude := range excludes {
+		prefix := fieldKey(md, exclude)
+		for key := range queryParams {
+			if key == prefix || strings.HasPrefix(key, prefix+".") {
+				delete(queryParams, key)
+			}
+		}
+	}
+	if query := queryParams.Encode(); query != "" {
+		path += "?" + query
+	}
+	return path
+}
+
+// fieldKey converts a proto field path such as "page_size" or "inner.id"
+// to the key used by form encoding, which prefers the json name.
+func fieldKey(md protoreflect.MessageDescriptor, path string) string {
+	if md == nil {
+		return path
+	}
+	names := strings.Split(path, ".")
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		if md == nil {
+			return path
+		}
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return path
+		}
+		if fd.HasJSONName() {
+			keys = append(keys, fd.JSONName())
+		} else {
+			keys = append(keys, fd.TextName())
+		}
+		md = fd.Message()
+	}
+	return strings.Join(keys, ".")
+}
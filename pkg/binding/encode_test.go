@@ -0,0 +1,40 @@
+package binding
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"testing"
+)
+
+func TestEncodeURL(t *testing.T) {
+	msg := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("names/a b"),
+		JsonName: proto.String("json"),
+		Number:   proto.Int32(1),
+		Options:  &descriptorpb.FieldOptions{Packed: proto.Bool(true)},
+	}
+	tests := []struct {
+		name      string
+		template  string
+		needQuery bool
+		excludes  []string
+		want      string
+	}{
+		{"simple var", "/fields/{json_name}", false, nil, "/fields/json"},
+		{"pattern var", "/fields/{name=names/*}", false, nil, "/fields/names/a%20b"},
+		{"query", "/fields/{json_name}", true, nil, "/fields/json?name=names%2Fa+b&number=1&options.packed=true"},
+		{"exclude body", "/fields/{json_name}/{name=names/*}", true, []string{"options"}, "/fields/json/names/a%20b?number=1"},
+		{"nil", "/fields/{json_name}", true, nil, "/fields/{json_name}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in interface{} = msg
+			if tt.name == "nil" {
+				in = (*descriptorpb.FieldDescriptorProto)(nil)
+			}
+			if got := EncodeURL(tt.template, in, tt.needQuery, tt.excludes...); got != tt.want {
+				t.Errorf("EncodeURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package binding
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// pathTemplates caches the compiled path templates.
+var pathTemplates sync.Map
+
+type pathTemplate struct {
+	re    *regexp.Regexp
+	names []string
+}
+
+// PathVars matches the escaped path with a path template such as /v1/{name=shelves/*}/books,
+// which is what EncodeURL expands, and returns the unescaped values of its vars.
+// The value of a {var=pattern} var keeps every segment it matches, e.g. shelves/1.
+func PathVars(template, path string) (url.Values, error) {
+	pt, err := compilePathTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	m := pt.re.FindStringSubmatch(path)
+	if m == nil {
+		return nil, fmt.Errorf("path %s does not match %s", path, template)
+	}
+	vars := make(url.Values, len(pt.names))
+	for i, name := range pt.names {
+		segments := strings.Split(m[i+1], "/")
+		for j, s := range segments {
+			if segments[j], err = url.PathUnescape(s); err != nil {
+				return nil, err
+			}
+		}
+		vars.Set(name, strings.Join(segments, "/"))
+	}
+	return vars, nil
+}
+
+func compilePathTemplate(template string) (*pathTemplate, error) {
+	if pt, ok := pathTemplates.Load(template); ok {
+		return pt.(*pathTemplate), nil
+	}
+	var (
+		expr  strings.Builder
+		names []string
+		last  int
+	)
+	expr.WriteString("^")
+	for _, idx := range pathVarPattern.FindAllStringSubmatchIndex(template, -1) {
+		expr.WriteString(regexp.QuoteMeta(template[last:idx[0]]))
+		expr.WriteString("(" + segmentsExpr(template[idx[4]:idx[5]]) + ")")
+		names = append(names, strings.TrimSpace(template[idx[2]:idx[3]]))
+		last = idx[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(template[last:]) + "$")
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, err
+	}
+	pt, _ := pathTemplates.LoadOrStore(template, &pathTemplate{re: re, names: names})
+	return pt.(*pathTemplate), nil
+}
+
+// segmentsExpr converts the pattern of a var, * matches a segment and ** the rest of the path.
+func segmentsExpr(pattern string) string {
+	if pattern == "" {
+		return "[^/]+"
+	}
+	segments := strings.Split(pattern, "/")
+	for i, s := range segments {
+		switch s {
+		case "*":
+			segments[i] = "[^/]+"
+		case "**":
+			segments[i] = ".*"
+		default:
+			segments[i] = regexp.QuoteMeta(s)
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,43 @@
+package binding
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestPathVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		path     string
+		want     url.Values
+	}{
+		{"simple var", "/fields/{json_name}", "/fields/json", url.Values{"json_name": {"json"}}},
+		{"pattern var", "/fields/{name=names/*}", "/fields/names/a%20b", url.Values{"name": {"names/a b"}}},
+		{"pattern var in the middle", "/v1/{parent=shelves/*}/books/{id}", "/v1/shelves/1/books/2", url.Values{"parent": {"shelves/1"}, "id": {"2"}}},
+		{"multi segments", "/v1/{name=shelves/*/books/*}", "/v1/shelves/1/books/2", url.Values{"name": {"shelves/1/books/2"}}},
+		{"catch all", "/v1/{name=files/**}", "/v1/files/a/b%2Fc", url.Values{"name": {"files/a/b/c"}}},
+		{"verb", "/v1/{name=shelves/*}:clear", "/v1/shelves/1:clear", url.Values{"name": {"shelves/1"}}},
+		{"nested field", "/v1/{book.name=books/*}", "/v1/books/1", url.Values{"book.name": {"books/1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PathVars(tt.template, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PathVars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathVarsMismatch(t *testing.T) {
+	for _, path := range []string{"/v1/shelves/1", "/v1/books/1/x", "/v1/shelves/1/2/x"} {
+		if _, err := PathVars("/v1/{name=shelves/*}/x", path); err == nil {
+			t.Errorf("%s should not match", path)
+		}
+	}
+}
@@ -2,8 +2,12 @@ package endpoint
 
 import (
 	"net/url"
+	"strings"
 )
 
+// unixSuffix marks the scheme of an endpoint on a unix socket.
+const unixSuffix = "+unix"
+
 // NewEndpoint new an Endpoint URL.
 func NewEndpoint(scheme, host string) *url.URL {
 	return &url.URL{Scheme: scheme, Host: host}
@@ -32,3 +36,31 @@ func Scheme(scheme string, isSecure bool) string {
 	}
 	return scheme
 }
+
+// UnixScheme is the scheme of an endpoint on a unix socket.
+// examples: scheme="http" get "http+unix"
+func UnixScheme(scheme string) string {
+	return scheme + unixSuffix
+}
+
+// NewUnixEndpoint new an Endpoint URL of the unix socket at path, such as http+unix:///tmp/app.sock.
+func NewUnixEndpoint(scheme, path string) *url.URL {
+	if !strings.HasPrefix(path, "/") {
+		return &url.URL{Scheme: UnixScheme(scheme), Opaque: path}
+	}
+	return &url.URL{Scheme: UnixScheme(scheme), Path: path}
+}
+
+// UnixPath returns the socket path of a unix socket Endpoint URL and its scheme without
+// the unix suffix, such as /tmp/app.sock and http for http+unix:///tmp/app.sock.
+// An abstract socket such as http+unix:@app has no leading slash.
+func UnixPath(u *url.URL) (path string, scheme string, ok bool) {
+	scheme, ok = strings.CutSuffix(u.Scheme, unixSuffix)
+	if !ok {
+		return "", u.Scheme, false
+	}
+	if u.Opaque != "" {
+		return u.Opaque, scheme, true
+	}
+	return u.Host + u.Path, scheme, true
+}
@@ -124,3 +124,31 @@ func TestSchema(t *testing.T) {
 		}
 	}
 }
+
+func TestUnixEndpoint(t *testing.T) {
+	tests := []struct {
+		scheme string
+		path   string
+		want   string
+	}{
+		{"http", "/tmp/app.sock", "http+unix:///tmp/app.sock"},
+		{"arpcs", "/run/app/rpc.sock", "arpcs+unix:///run/app/rpc.sock"},
+		{"http", "@app", "http+unix:@app"},
+	}
+	for _, tt := range tests {
+		u := NewUnixEndpoint(tt.scheme, tt.path)
+		if got := u.String(); got != tt.want {
+			t.Errorf("NewUnixEndpoint() = %v, want %v", got, tt.want)
+		}
+		parsed, err := url.Parse(tt.want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path, scheme, ok := UnixPath(parsed); !ok || path != tt.path || scheme != tt.scheme {
+			t.Errorf("UnixPath(%s) = %v %v %v, want %v %v", tt.want, path, scheme, ok, tt.path, tt.scheme)
+		}
+	}
+	if _, scheme, ok := UnixPath(&url.URL{Scheme: "http", Host: "127.0.0.1:80"}); ok || scheme != "http" {
+		t.Errorf("UnixPath() = %v %v, want %v %v", scheme, ok, "http", false)
+	}
+}
@@ -30,7 +30,15 @@ func Port(lis net.Listener) (int, bool) {
 	return 0, false
 }
 
-// ExtractFromLis returns a private addr and port.
+// Unix returns the socket path of a unix listener.
+func Unix(lis net.Listener) (string, bool) {
+	if addr, ok := lis.Addr().(*net.UnixAddr); ok {
+		return addr.Name, true
+	}
+	return "", false
+}
+
+// ExtractFromLis returns a private addr and the port of lis.
 func ExtractFromLis(hostPort string, lis net.Listener) (string, error) {
 	addr, port, err := net.SplitHostPort(hostPort)
 	if err != nil && lis == nil {
@@ -45,6 +53,8 @@ func ExtractFromLis(hostPort string, lis net.Listener) (string, error) {
 		if len(addr) > 0 && (addr != "0.0.0.0" && addr != "[::]" && addr != "::") {
 			return net.JoinHostPort(addr, port), nil
 		}
+		// the port of the listener, hostPort may be :0 or not the listened address at all
+		return Extract(net.JoinHostPort(addr, port))
 	}
 	return Extract(hostPort)
 }
@@ -2,7 +2,9 @@ package host
 
 import (
 	"net"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -114,6 +116,43 @@ func TestPort(t *testing.T) {
 	}
 }
 
+func TestUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	if got, ok := Unix(lis); !ok || got != path {
+		t.Errorf("expected %s got %s", path, got)
+	}
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	if _, ok := Unix(tcp); ok {
+		t.Error("expected a tcp listener not to be a unix one")
+	}
+}
+
+func TestExtractFromLis(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	port, _ := Port(lis)
+	// the port of the listener in place of :0
+	res, err := ExtractFromLis("127.0.0.1:0", lis)
+	if want := net.JoinHostPort("127.0.0.1", strconv.Itoa(port)); err != nil || res != want {
+		t.Errorf("expected %s got %s %v", want, res, err)
+	}
+	if res, err = ExtractFromLis("127.0.0.1:9000", lis); err != nil || res != lis.Addr().String() {
+		t.Errorf("expected %s got %s %v", lis.Addr(), res, err)
+	}
+}
+
 func TestExtractHostPort(t *testing.T) {
 	host, port, err := ExtractHostPort("127.0.0.1:8000")
 	if err != nil {
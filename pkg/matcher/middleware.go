@@ -1,9 +1,6 @@
 package matcher
 
 import (
-	"sort"
-	"strings"
-
 	"github.com/go-kratos/kratos/v2/middleware"
 )
 
@@ -16,15 +13,12 @@ type Matcher interface {
 
 // New new a middleware matcher.
 func New() Matcher {
-	return &matcher{
-		matchs: make(map[string][]middleware.Middleware),
-	}
+	return &matcher{}
 }
 
 type matcher struct {
-	prefix   []string
 	defaults []middleware.Middleware
-	matchs   map[string][]middleware.Middleware
+	selector Selector[[]middleware.Middleware]
 }
 
 func (m *matcher) Use(ms ...middleware.Middleware) {
@@ -32,17 +26,7 @@ func (m *matcher) Use(ms ...middleware.Middleware) {
 }
 
 func (m *matcher) Add(selector string, ms ...middleware.Middleware) {
-	if strings.HasSuffix(selector, "*") {
-		selector = strings.TrimSuffix(selector, "*")
-		m.prefix = append(m.prefix, selector)
-		// sort the prefix:
-		//  - /foo/bar
-		//  - /foo
-		sort.Slice(m.prefix, func(i, j int) bool {
-			return m.prefix[i] > m.prefix[j]
-		})
-	}
-	m.matchs[selector] = ms
+	m.selector.Add(selector, ms)
 }
 
 func (m *matcher) Match(operation string) []middleware.Middleware {
@@ -50,13 +34,8 @@ func (m *matcher) Match(operation string) []middleware.Middleware {
 	if len(m.defaults) > 0 {
 		ms = append(ms, m.defaults...)
 	}
-	if next, ok := m.matchs[operation]; ok {
+	if next, ok := m.selector.Match(operation); ok {
 		return append(ms, next...)
 	}
-	for _, prefix := range m.prefix {
-		if strings.HasPrefix(operation, prefix) {
-			return append(ms, m.matchs[prefix]...)
-		}
-	}
 	return ms
 }
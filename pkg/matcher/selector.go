@@ -0,0 +1,46 @@
+package matcher
+
+import (
+	"sort"
+	"strings"
+)
+
+// Selector matches operations with selectors, a selector ending with * matches the operations
+// it prefixes. An exact selector wins over the prefixes, which are tried from the longest one.
+// The zero value is ready to use.
+type Selector[T any] struct {
+	prefix []string
+	matchs map[string]T
+}
+
+// Add adds v for selector.
+func (s *Selector[T]) Add(selector string, v T) {
+	if s.matchs == nil {
+		s.matchs = make(map[string]T)
+	}
+	if strings.HasSuffix(selector, "*") {
+		selector = strings.TrimSuffix(selector, "*")
+		s.prefix = append(s.prefix, selector)
+		// sort the prefix:
+		//  - /foo/bar
+		//  - /foo
+		sort.Slice(s.prefix, func(i, j int) bool {
+			return s.prefix[i] > s.prefix[j]
+		})
+	}
+	s.matchs[selector] = v
+}
+
+// Match returns the value of the selector matching operation.
+func (s *Selector[T]) Match(operation string) (T, bool) {
+	if v, ok := s.matchs[operation]; ok {
+		return v, true
+	}
+	for _, prefix := range s.prefix {
+		if strings.HasPrefix(operation, prefix) {
+			return s.matchs[prefix], true
+		}
+	}
+	var zero T
+	return zero, false
+}
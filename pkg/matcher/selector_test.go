@@ -0,0 +1,30 @@
+package matcher
+
+import (
+	"testing"
+)
+
+func TestSelector(t *testing.T) {
+	var s Selector[int]
+	if _, ok := s.Match("/foo"); ok {
+		t.Fatal("the zero selector should not match")
+	}
+	s.Add("/foo/*", 1)
+	s.Add("/foo/bar/*", 2)
+	s.Add("/foo/bar", 3)
+	tests := []struct {
+		operation string
+		want      int
+		ok        bool
+	}{
+		{"/foo/baz", 1, true},
+		{"/foo/bar/baz", 2, true},
+		{"/foo/bar", 3, true},
+		{"/bar", 0, false},
+	}
+	for _, tt := range tests {
+		if v, ok := s.Match(tt.operation); v != tt.want || ok != tt.ok {
+			t.Errorf("%s: expected %d %v got %d %v", tt.operation, tt.want, tt.ok, v, ok)
+		}
+	}
+}
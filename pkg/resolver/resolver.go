@@ -0,0 +1,138 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target is resolver target
+type Target struct {
+	Scheme    string
+	Authority string
+	Endpoint  string
+}
+
+// ParseTarget parses an endpoint such as "127.0.0.1:8000", "direct:///127.0.0.1:8000"
+// or "discovery:///svc", scheme is used when endpoint does not declare it.
+func ParseTarget(endpoint string, scheme string) (*Target, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = scheme + "://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	target := &Target{Scheme: u.Scheme, Authority: u.Host}
+	if len(u.Path) > 1 {
+		target.Endpoint = u.Path[1:]
+	}
+	return target, nil
+}
+
+// Resolver watches the service instances of target and applies them to the rebalancer.
+type Resolver struct {
+	rebalancer selector.Rebalancer
+	target     *Target
+	watcher    registry.Watcher
+	scheme     string
+	once       sync.Once
+}
+
+// New new a resolver, scheme is the endpoint scheme of the instances to pick, for example "http" or "https".
+// When block is true, New waits until the first non-empty snapshot is applied or ctx is done.
+func New(ctx context.Context, discovery registry.Discovery, target *Target,
+	rebalancer selector.Rebalancer, block bool, scheme string,
+) (*Resolver, error) {
+	watcher, err := discovery.Watch(ctx, target.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	r := &Resolver{
+		target:     target,
+		watcher:    watcher,
+		rebalancer: rebalancer,
+		scheme:     scheme,
+	}
+	if block {
+		done := make(chan error, 1)
+		go func() {
+			for {
+				services, err := watcher.Next()
+				if err != nil {
+					done <- err
+					return
+				}
+				if r.update(services) {
+					done <- nil
+					return
+				}
+			}
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				_ = r.Close()
+				return nil, err
+			}
+		case <-ctx.Done():
+			log.Errorf("[resolver] watch service %v reaching context deadline!", target)
+			_ = r.Close()
+			return nil, ctx.Err()
+		}
+	}
+	go r.watch()
+	return r, nil
+}
+
+func (r *Resolver) watch() {
+	for {
+		services, err := r.watcher.Next()
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			log.Errorf("[resolver] watch service %v got unexpected error:=%v", r.target, err)
+			time.Sleep(time.Second)
+			continue
+		}
+		r.update(services)
+	}
+}
+
+func (r *Resolver) update(services []*registry.ServiceInstance) bool {
+	nodes := make([]selector.Node, 0, len(services))
+	for _, ins := range services {
+		ept, err := endpoint.ParseEndpoint(ins.Endpoints, r.scheme)
+		if err != nil {
+			log.Errorf("[resolver] failed to parse (%v) discovery endpoint: %v error %v", r.target, ins.Endpoints, err)
+			continue
+		}
+		if ept == "" {
+			continue
+		}
+		nodes = append(nodes, selector.NewNode(r.scheme, ept, ins))
+	}
+	if len(nodes) == 0 {
+		log.Warnf("[resolver] zero endpoint found, refused to write, set: %s ins: %v", r.target.Endpoint, services)
+		return false
+	}
+	r.rebalancer.Apply(nodes)
+	return true
+}
+
+// Close stops the watcher.
+func (r *Resolver) Close() error {
+	var err error
+	r.once.Do(func() {
+		err = r.watcher.Stop()
+	})
+	return err
+}
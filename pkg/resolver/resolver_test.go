@@ -0,0 +1,117 @@
+package resolver
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockRebalancer struct {
+	mu    sync.Mutex
+	nodes []selector.Node
+}
+
+func (m *mockRebalancer) Apply(nodes []selector.Node) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nodes = nodes
+}
+
+func (m *mockRebalancer) addresses() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]string, 0, len(m.nodes))
+	for _, n := range m.nodes {
+		res = append(res, n.Address())
+	}
+	return res
+}
+
+type mockDiscovery struct {
+	ch chan []*registry.ServiceInstance
+}
+
+func (d *mockDiscovery) GetService(_ context.Context, _ string) ([]*registry.ServiceInstance, error) {
+	return nil, nil
+}
+
+func (d *mockDiscovery) Watch(ctx context.Context, _ string) (registry.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &mockWatcher{ch: d.ch, ctx: ctx, cancel: cancel}, nil
+}
+
+type mockWatcher struct {
+	ch     chan []*registry.ServiceInstance
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (w *mockWatcher) Next() ([]*registry.ServiceInstance, error) {
+	select {
+	case ins := <-w.ch:
+		return ins, nil
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	}
+}
+
+func (w *mockWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     *Target
+	}{
+		{"127.0.0.1:8000", &Target{Scheme: "http", Authority: "127.0.0.1:8000"}},
+		{"direct:///127.0.0.1:8000", &Target{Scheme: "direct", Endpoint: "127.0.0.1:8000"}},
+		{"discovery:///helloworld", &Target{Scheme: "discovery", Endpoint: "helloworld"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTarget(tt.endpoint, "http")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTarget(%s) = %v, want %v", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	d := &mockDiscovery{ch: make(chan []*registry.ServiceInstance, 1)}
+	b := &mockRebalancer{}
+	d.ch <- []*registry.ServiceInstance{
+		{ID: "1", Name: "helloworld", Endpoints: []string{"http://127.0.0.1:8000", "grpc://127.0.0.1:9000"}},
+		{ID: "2", Name: "helloworld", Endpoints: []string{"grpc://127.0.0.1:9001"}},
+	}
+	r, err := New(context.Background(), d, &Target{Scheme: "discovery", Endpoint: "helloworld"}, b, true, "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.addresses(); !reflect.DeepEqual(got, []string{"127.0.0.1:8000"}) {
+		t.Errorf("expected [127.0.0.1:8000] got %v", got)
+	}
+	// empty snapshot keeps the current nodes
+	d.ch <- []*registry.ServiceInstance{}
+	time.Sleep(100 * time.Millisecond)
+	if got := b.addresses(); !reflect.DeepEqual(got, []string{"127.0.0.1:8000"}) {
+		t.Errorf("expected [127.0.0.1:8000] got %v", got)
+	}
+	d.ch <- []*registry.ServiceInstance{
+		{ID: "3", Name: "helloworld", Endpoints: []string{"http://127.0.0.1:8001"}},
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := b.addresses(); !reflect.DeepEqual(got, []string{"127.0.0.1:8001"}) {
+		t.Errorf("expected [127.0.0.1:8001] got %v", got)
+	}
+	if err = r.Close(); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package sse
+
+import (
+	"bytes"
+	"io"
+)
+
+const (
+	// ContentType is the content type of an event stream.
+	ContentType = "text/event-stream"
+	// LastEventIDHeader is sent by a reconnecting client with the id of the last event it received.
+	LastEventIDHeader = "Last-Event-ID"
+)
+
+// Heartbeat is a comment line that keeps the connection alive,
+// a failed heartbeat write reveals a disconnected client.
+var Heartbeat = []byte(": heartbeat\n\n")
+
+// Event is a server-sent event.
+type Event struct {
+	ID    string
+	Event string
+	Data  []byte
+}
+
+// Encode writes the event in text/event-stream format,
+// data with line breaks is split into multiple data fields.
+func Encode(w io.Writer, e *Event) error {
+	var buf bytes.Buffer
+	if e.ID != "" {
+		buf.WriteString("id: ")
+		buf.WriteString(e.ID)
+		buf.WriteByte('\n')
+	}
+	if e.Event != "" {
+		buf.WriteString("event: ")
+		buf.WriteString(e.Event)
+		buf.WriteByte('\n')
+	}
+	data := bytes.ReplaceAll(e.Data, []byte("\r\n"), []byte("\n"))
+	data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
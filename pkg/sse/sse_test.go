@@ -0,0 +1,125 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json"
+	_ "github.com/go-kratos/kratos/v2/encoding/xml"
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *Event
+		want  string
+	}{
+		{"data", &Event{Data: []byte(`{"a":1}`)}, "data: {\"a\":1}\n\n"},
+		{"id", &Event{ID: "1", Data: []byte("a")}, "id: 1\ndata: a\n\n"},
+		{"event", &Event{Event: "error", Data: []byte("a")}, "event: error\ndata: a\n\n"},
+		{"lines", &Event{Data: []byte("a\nb\r\nc\rd")}, "data: a\ndata: b\ndata: c\ndata: d\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, tt.event); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("expected %q got %q", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestCodecForAccept(t *testing.T) {
+	tests := []struct {
+		accepts []string
+		want    string
+	}{
+		{nil, "json"},
+		{[]string{"text/event-stream"}, "json"},
+		{[]string{"text/event-stream, application/xml"}, "xml"},
+		{[]string{"text/event-stream", "application/json;q=0.9"}, "json"},
+	}
+	for _, tt := range tests {
+		if got := CodecForAccept(tt.accepts...).Name(); got != tt.want {
+			t.Errorf("%v: expected %s got %s", tt.accepts, tt.want, got)
+		}
+	}
+}
+
+type testWriter struct {
+	bytes.Buffer
+	err error
+}
+
+func (w *testWriter) Flush() error {
+	return w.err
+}
+
+func TestStream(t *testing.T) {
+	codec := encoding.GetCodec("json")
+	s := NewStream(context.Background(), codec, "2", time.Second)
+	if s.LastEventID() != "2" {
+		t.Errorf("expected %s got %s", "2", s.LastEventID())
+	}
+	err := s.Run(func() error {
+		for _, v := range []string{"a", "b"} {
+			if err := s.Send(map[string]string{"v": v}); err != nil {
+				return err
+			}
+		}
+		return kratoserrors.BadRequest("BAD", "bad")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w testWriter
+	if err = s.WriteTo(&w); err != nil {
+		t.Fatal(err)
+	}
+	want := "id: 3\ndata: {\"v\":\"a\"}\n\nid: 4\ndata: {\"v\":\"b\"}\n\nevent: error\ndata: "
+	if !strings.HasPrefix(w.String(), want) || !strings.Contains(w.String(), `"reason":"BAD"`) {
+		t.Errorf("unexpected stream %q", w.String())
+	}
+	if s.Context().Err() == nil {
+		t.Error("expected the stream context to be canceled")
+	}
+}
+
+func TestStreamEarlyError(t *testing.T) {
+	s := NewStream(context.Background(), encoding.GetCodec("json"), "", time.Second)
+	err := s.Run(func() error {
+		return kratoserrors.Unauthorized("UNAUTHORIZED", "")
+	})
+	if !kratoserrors.IsUnauthorized(err) {
+		t.Errorf("expected unauthorized got %v", err)
+	}
+}
+
+func TestStreamDisconnect(t *testing.T) {
+	s := NewStream(context.Background(), encoding.GetCodec("json"), "", 10*time.Millisecond)
+	sent := make(chan error, 1)
+	err := s.Run(func() error {
+		<-s.Context().Done()
+		err := s.Send("a")
+		sent <- err
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	disconnected := errors.New("disconnected")
+	if err = s.WriteTo(&testWriter{err: disconnected}); err != disconnected {
+		t.Errorf("expected %v got %v", disconnected, err)
+	}
+	if err = <-sent; err != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,180 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/pkg/httputil"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CodecForAccept returns the codec of the first media type in the Accept headers
+// that a codec is registered for, text/event-stream itself is skipped. It falls back to json.
+func CodecForAccept(accepts ...string) encoding.Codec {
+	for _, accept := range accepts {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+			if strings.HasPrefix(mediaType, ContentType) {
+				continue
+			}
+			if codec := encoding.GetCodec(httputil.ContentSubtype(mediaType)); codec != nil {
+				return codec
+			}
+		}
+	}
+	return encoding.GetCodec("json")
+}
+
+// Writer is the flushable response body writer of a stream.
+type Writer interface {
+	Write(p []byte) (int, error)
+	Flush() error
+}
+
+// Stream writes the messages of a server-streaming handler as events,
+// the handler runs in its own goroutine while the events are written by WriteTo.
+type Stream struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	codec       encoding.Codec
+	heartbeat   time.Duration
+	lastEventID string
+	seq         uint64
+	events      chan *Event
+	done        chan error
+	first       *Event
+}
+
+// NewStream returns a stream encoding messages with codec, the event ids continue
+// from lastEventID if it is numeric. A heartbeat is written every heartbeat interval
+// unless it is zero. The operation timeouts do not apply to the handler of the stream.
+func NewStream(ctx context.Context, codec encoding.Codec, lastEventID string, heartbeat time.Duration) *Stream {
+	ctx, cancel := context.WithCancel(timeout.WithStream(ctx))
+	s := &Stream{
+		ctx:         ctx,
+		cancel:      cancel,
+		codec:       codec,
+		heartbeat:   heartbeat,
+		lastEventID: lastEventID,
+		events:      make(chan *Event),
+		done:        make(chan error, 1),
+	}
+	if id, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+		s.seq = id
+	}
+	return s
+}
+
+// Context returns the stream context, it is canceled once the client disconnects.
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+// LastEventID returns the Last-Event-ID sent by a reconnecting client.
+func (s *Stream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Send encodes v as the data of a new event and waits until the event is taken
+// by the writer, it must not be called concurrently.
+func (s *Stream) Send(v any) error {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.seq++
+	e := &Event{ID: strconv.FormatUint(s.seq, 10), Data: data}
+	select {
+	case s.events <- e:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// Run runs handler in a new goroutine and waits for its first event, or for the first
+// heartbeat interval to elapse. It returns the handler error if the handler fails before
+// sending any event, so that it can be written as a plain error response.
+func (s *Stream) Run(handler func() error) error {
+	go func() {
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = errors.InternalServer("PANIC", fmt.Sprintf("%v", r))
+			}
+			s.done <- err
+		}()
+		err = handler()
+	}()
+	var timeout <-chan time.Time
+	if s.heartbeat > 0 {
+		timer := time.NewTimer(s.heartbeat)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case err := <-s.done:
+		if err != nil {
+			s.cancel()
+			return err
+		}
+		s.done <- nil
+	case e := <-s.events:
+		s.first = e
+	case <-timeout:
+	}
+	return nil
+}
+
+// WriteTo writes the events to w until the handler returns, a handler error is written
+// as an "error" event. It returns the write error once the client disconnects.
+func (s *Stream) WriteTo(w Writer) error {
+	defer s.cancel()
+	first := s.first
+	if first == nil {
+		if _, err := w.Write(Heartbeat); err != nil {
+			return err
+		}
+	} else if err := Encode(w, first); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	var tick <-chan time.Time
+	if s.heartbeat > 0 {
+		ticker := time.NewTicker(s.heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case e := <-s.events:
+			if err := Encode(w, e); err != nil {
+				return err
+			}
+		case <-tick:
+			if _, err := w.Write(Heartbeat); err != nil {
+				return err
+			}
+		case err := <-s.done:
+			if err != nil {
+				data, e := s.codec.Marshal(errors.FromError(err))
+				if e != nil {
+					return e
+				}
+				if e = Encode(w, &Event{Event: "error", Data: data}); e != nil {
+					return e
+				}
+			}
+			return w.Flush()
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package timeout
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/LiangQinghai/kratos-ext/pkg/matcher"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// GrpcTimeoutHeader carries the budget of the caller in the grpc format, e.g. 100m.
+	GrpcTimeoutHeader = "Grpc-Timeout"
+	// RequestTimeoutHeader carries the budget of the caller as a duration, e.g. 1.5s,
+	// or as a number of seconds.
+	RequestTimeoutHeader = "X-Request-Timeout"
+)
+
+// DeadlineExceededError is returned once the budget of a request has run out.
+var DeadlineExceededError = errors.GatewayTimeout("DEADLINE_EXCEEDED", "deadline exceeded")
+
+// Header is the request header the budget of the caller is read from.
+type Header interface {
+	Get(key string) string
+}
+
+// FromHeader returns the budget of the caller, Grpc-Timeout takes precedence over X-Request-Timeout.
+// Malformed values are ignored.
+func FromHeader(h Header) (time.Duration, bool) {
+	if v := h.Get(GrpcTimeoutHeader); v != "" {
+		if d, err := ParseGrpcTimeout(v); err == nil {
+			return d, true
+		}
+	}
+	if v := h.Get(RequestTimeoutHeader); v != "" {
+		if d, err := ParseRequestTimeout(v); err == nil {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// ParseGrpcTimeout parses a timeout in the grpc format, up to 8 digits followed by one of
+// the units H, M, S, m, u and n.
+func ParseGrpcTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("timeout: malformed grpc timeout %q", s)
+	}
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("timeout: unknown grpc timeout unit in %q", s)
+	}
+	n, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("timeout: malformed grpc timeout %q", s)
+	}
+	if n > uint64(math.MaxInt64/unit) {
+		return math.MaxInt64, nil
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// ParseRequestTimeout parses a duration such as 500ms, or a number of seconds.
+func ParseRequestTimeout(s string) (time.Duration, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		if f < 0 || math.IsNaN(f) {
+			return 0, fmt.Errorf("timeout: malformed request timeout %q", s)
+		}
+		if f > float64(math.MaxInt64/time.Second) {
+			return math.MaxInt64, nil
+		}
+		return time.Duration(f * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("timeout: malformed request timeout %q", s)
+	}
+	return d, nil
+}
+
+// Budget returns d shortened by the budget of the caller, a zero d means no timeout.
+func Budget(d time.Duration, h Header) time.Duration {
+	if b, ok := FromHeader(h); ok && (d <= 0 || b < d) {
+		// an exhausted budget must not read as no timeout
+		return max(b, time.Nanosecond)
+	}
+	return d
+}
+
+// WithTimeout returns a copy of ctx whose deadline is d from now in place of the deadline of ctx,
+// so that it may outlast it, a zero d leaves it without deadline. The cancellation of ctx is still passed on.
+func WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	var (
+		tctx   context.Context
+		cancel context.CancelFunc
+	)
+	if d > 0 {
+		tctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), d)
+	} else {
+		tctx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+	}
+	stop := context.AfterFunc(ctx, func() {
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			cancel()
+		}
+	})
+	return tctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Error returns DeadlineExceededError if the budget of ctx has run out, err otherwise.
+func Error(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return DeadlineExceededError
+	}
+	return err
+}
+
+// Handler returns h bounded by the timeout m matches for operation in place of the deadline set by the
+// server, the budget of the caller still applies. Without a match the deadline is left as is, the handlers
+// of streams are left without timeout. The handler fails with DeadlineExceededError as soon as its budget
+// has run out, without waiting for h to return.
+func Handler(m Matcher, operation string, h middleware.Handler) middleware.Handler {
+	d, override := m.Match(operation)
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		if override && !isStream(ctx) {
+			budget := d
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				budget = Budget(d, tr.RequestHeader())
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = WithTimeout(ctx, budget)
+			defer cancel()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, Error(ctx, err)
+		}
+		reply, err := run(ctx, h, req)
+		if err != nil {
+			return nil, Error(ctx, err)
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, DeadlineExceededError
+		}
+		return reply, nil
+	}
+}
+
+// run returns the result of h, or the error of ctx once it is done while h is still running.
+// A panic of h is raised again in the calling goroutine.
+func run(ctx context.Context, h middleware.Handler, req interface{}) (interface{}, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return h(ctx, req)
+	}
+	type result struct {
+		reply interface{}
+		err   error
+		panic interface{}
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{panic: r}
+			}
+		}()
+		reply, err := h(ctx, req)
+		done <- result{reply: reply, err: err}
+	}()
+	select {
+	case r := <-done:
+		if r.panic != nil {
+			panic(r.panic)
+		}
+		return r.reply, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type streamKey struct{}
+
+// WithStream marks ctx as the context of a stream, Handler leaves it without timeout.
+func WithStream(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamKey{}, true)
+}
+
+func isStream(ctx context.Context) bool {
+	_, ok := ctx.Value(streamKey{}).(bool)
+	return ok
+}
+
+// Matcher is a timeout matcher, the selectors are matched the same way as the middleware ones.
+type Matcher interface {
+	Add(selector string, d time.Duration)
+	Match(operation string) (time.Duration, bool)
+}
+
+// New new a timeout matcher.
+func New() Matcher {
+	return &durationMatcher{}
+}
+
+type durationMatcher struct {
+	selector matcher.Selector[time.Duration]
+}
+
+func (m *durationMatcher) Add(selector string, d time.Duration) {
+	m.selector.Add(selector, d)
+}
+
+func (m *durationMatcher) Match(operation string) (time.Duration, bool) {
+	return m.selector.Match(operation)
+}
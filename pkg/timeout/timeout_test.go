@@ -0,0 +1,220 @@
+package timeout
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestParseGrpcTimeout(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{"1H", time.Hour, false},
+		{"2M", 2 * time.Minute, false},
+		{"3S", 3 * time.Second, false},
+		{"100m", 100 * time.Millisecond, false},
+		{"5u", 5 * time.Microsecond, false},
+		{"7n", 7, false},
+		{"99999999H", 1<<63 - 1, false},
+		{"m", 0, true},
+		{"10", 0, true},
+		{"10s", 0, true},
+		{"-1S", 0, true},
+		{"123456789S", 0, true},
+	}
+	for _, tt := range tests {
+		d, err := ParseGrpcTimeout(tt.in)
+		if (err != nil) != tt.err || d != tt.want {
+			t.Errorf("%s: expected %v %v got %v %v", tt.in, tt.want, tt.err, d, err)
+		}
+	}
+}
+
+func TestParseRequestTimeout(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{"2", 2 * time.Second, false},
+		{"0.5", 500 * time.Millisecond, false},
+		{"250ms", 250 * time.Millisecond, false},
+		{"-1", 0, true},
+		{"-1s", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		d, err := ParseRequestTimeout(tt.in)
+		if (err != nil) != tt.err || d != tt.want {
+			t.Errorf("%s: expected %v %v got %v %v", tt.in, tt.want, tt.err, d, err)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		d      time.Duration
+		header http.Header
+		want   time.Duration
+	}{
+		{"none", time.Second, http.Header{}, time.Second},
+		{"shorter", time.Second, http.Header{"Grpc-Timeout": {"100m"}}, 100 * time.Millisecond},
+		{"longer", time.Second, http.Header{"X-Request-Timeout": {"5s"}}, time.Second},
+		{"unbounded", 0, http.Header{"X-Request-Timeout": {"5s"}}, 5 * time.Second},
+		{"precedence", time.Second, http.Header{"Grpc-Timeout": {"200m"}, "X-Request-Timeout": {"100ms"}}, 200 * time.Millisecond},
+		{"malformed", time.Second, http.Header{"Grpc-Timeout": {"x"}, "X-Request-Timeout": {"100ms"}}, 100 * time.Millisecond},
+		{"exhausted", time.Second, http.Header{"Grpc-Timeout": {"0n"}}, time.Nanosecond},
+	}
+	for _, tt := range tests {
+		if got := Budget(tt.d, tt.header); got != tt.want {
+			t.Errorf("%s: expected %v got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	ctx, stop := WithTimeout(parent, time.Minute)
+	defer stop()
+	<-parent.Done()
+	time.Sleep(10 * time.Millisecond)
+	if ctx.Err() != nil {
+		t.Errorf("expected the deadline of the parent to be replaced got %v", ctx.Err())
+	}
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) < 50*time.Second {
+		t.Errorf("unexpected deadline %v", deadline)
+	}
+
+	parent, cancel = context.WithCancel(context.Background())
+	ctx, stop = WithTimeout(parent, time.Minute)
+	defer stop()
+	cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("expected the cancellation of the parent to be passed on")
+	}
+}
+
+func TestError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	if err := Error(ctx, errors.BadRequest("BAD", "")); errors.Reason(err) != "DEADLINE_EXCEEDED" || errors.Code(err) != 504 {
+		t.Errorf("expected %s got %v", "DEADLINE_EXCEEDED", err)
+	}
+	if err := Error(context.Background(), context.DeadlineExceeded); errors.Reason(err) != "DEADLINE_EXCEEDED" {
+		t.Errorf("expected %s got %v", "DEADLINE_EXCEEDED", err)
+	}
+	if err := Error(context.Background(), errors.BadRequest("BAD", "")); errors.Reason(err) != "BAD" {
+		t.Errorf("expected %s got %v", "BAD", err)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	m := New()
+	m.Add("/foo/*", time.Second)
+	m.Add("/foo/bar/*", 2*time.Second)
+	m.Add("/foo/bar", 3*time.Second)
+	tests := []struct {
+		operation string
+		want      time.Duration
+		ok        bool
+	}{
+		{"/", 0, false},
+		{"/foo/x", time.Second, true},
+		{"/foo/bar", 3 * time.Second, true},
+		{"/foo/bar/x", 2 * time.Second, true},
+	}
+	for _, tt := range tests {
+		if d, ok := m.Match(tt.operation); d != tt.want || ok != tt.ok {
+			t.Errorf("%s: expected %v %v got %v %v", tt.operation, tt.want, tt.ok, d, ok)
+		}
+	}
+}
+
+type testTransport struct {
+	transport.Transporter
+	header transport.Header
+}
+
+func (tr *testTransport) RequestHeader() transport.Header {
+	return tr.header
+}
+
+type testHeader struct {
+	http.Header
+}
+
+func (h testHeader) Keys() []string {
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.Add("/slow", time.Minute)
+	m.Add("/fast", 10*time.Millisecond)
+	wait := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+			return "ok", nil
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if reply, err := Handler(m, "/slow", wait)(ctx, nil); err != nil || reply != "ok" {
+		t.Errorf("expected the operation timeout to outlast the server one got %v %v", reply, err)
+	}
+	if _, err := Handler(m, "/fast", wait)(context.Background(), nil); errors.Reason(err) != "DEADLINE_EXCEEDED" {
+		t.Errorf("expected %s got %v", "DEADLINE_EXCEEDED", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := Handler(m, "/other", wait)(ctx, nil); errors.Reason(err) != "DEADLINE_EXCEEDED" {
+		t.Errorf("expected %s got %v", "DEADLINE_EXCEEDED", err)
+	}
+	tr := &testTransport{header: testHeader{http.Header{"Grpc-Timeout": {"10m"}}}}
+	ctx = transport.NewServerContext(context.Background(), tr)
+	if _, err := Handler(m, "/slow", wait)(ctx, nil); errors.Reason(err) != "DEADLINE_EXCEEDED" {
+		t.Errorf("expected the budget of the caller to apply got %v", err)
+	}
+	late := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return "late", nil
+	}
+	if _, err := Handler(m, "/fast", late)(context.Background(), nil); errors.Reason(err) != "DEADLINE_EXCEEDED" {
+		t.Errorf("expected a late reply to be dropped got %v", err)
+	}
+	release := make(chan struct{})
+	defer close(release)
+	stuck := func(context.Context, interface{}) (interface{}, error) {
+		<-release
+		return "stuck", nil
+	}
+	start := time.Now()
+	if _, err := Handler(m, "/fast", stuck)(context.Background(), nil); errors.Reason(err) != "DEADLINE_EXCEEDED" || time.Since(start) > 50*time.Millisecond {
+		t.Errorf("expected a handler ignoring ctx not to be waited for got %v after %v", err, time.Since(start))
+	}
+	if reply, err := Handler(m, "/fast", wait)(WithStream(context.Background()), nil); err != nil || reply != "ok" {
+		t.Errorf("expected a stream not to be bound by the operation timeout got %v %v", reply, err)
+	}
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("expected the panic of the handler got %v", r)
+		}
+	}()
+	_, _ = Handler(m, "/slow", func(context.Context, interface{}) (interface{}, error) {
+		panic("boom")
+	})(ctx, nil)
+}
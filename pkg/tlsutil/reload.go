@@ -0,0 +1,99 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/go-kratos/kratos/v2/log"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader keeps a certificate loaded from disk up to date, it reloads the certificate
+// once the files change so that a renewed certificate is served without a restart.
+// Set its GetCertificate in the tls.Config of a server, or its GetClientCertificate in the one of a client.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	stamp   string
+	checked time.Time
+}
+
+// NewCertReloader loads the certificate from certFile and keyFile, the files are checked
+// for changes on handshakes at most once per interval. A zero interval checks them on every handshake.
+func NewCertReloader(certFile, keyFile string, interval time.Duration) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the certificate again if the files changed since it was last loaded.
+// The current certificate is kept when the files fail to load.
+func (r *CertReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reload()
+}
+
+// Certificate returns the current certificate.
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checked) >= r.interval {
+		if err := r.reload(); err != nil {
+			log.Warnf("[TLS] failed to reload certificate %s: %v", r.certFile, err)
+		}
+	}
+	return r.cert
+}
+
+// GetCertificate serves the current certificate, it is a tls.Config GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// GetClientCertificate presents the current certificate, it is a tls.Config GetClientCertificate.
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+func (r *CertReloader) reload() error {
+	r.checked = time.Now()
+	stamp, err := fileStamp(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	if stamp == r.stamp {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.stamp = stamp
+	return nil
+}
+
+// fileStamp identifies the version of the files by their size and modification time.
+func fileStamp(files ...string) (string, error) {
+	var stamp string
+	for _, file := range files {
+		fi, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%d-%d;", fi.Size(), fi.ModTime().UnixNano())
+	}
+	return stamp, nil
+}
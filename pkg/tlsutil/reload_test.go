@@ -0,0 +1,107 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self-signed certificate for name and its key as pem files.
+func writeCertificate(t *testing.T, certFile, keyFile, name string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func commonName(t *testing.T, r *CertReloader) string {
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if _, err := NewCertReloader(certFile, keyFile, 0); err == nil {
+		t.Fatal("expected an error for missing files")
+	}
+	writeCertificate(t, certFile, keyFile, "first")
+	r, err := NewCertReloader(certFile, keyFile, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cn := commonName(t, r); cn != "first" {
+		t.Fatalf("expected first, got %s", cn)
+	}
+
+	// within the interval the files are not checked
+	writeCertificate(t, certFile, keyFile, "second")
+	later := time.Now().Add(time.Second)
+	for _, file := range []string{certFile, keyFile} {
+		if err = os.Chtimes(file, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cn := commonName(t, r); cn != "first" {
+		t.Fatalf("expected first, got %s", cn)
+	}
+	if err = r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cn := commonName(t, r); cn != "second" {
+		t.Fatalf("expected second, got %s", cn)
+	}
+
+	// a broken key keeps the current certificate
+	r.interval = 0
+	if err = os.WriteFile(keyFile, []byte("broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Reload(); err == nil {
+		t.Fatal("expected an error for a broken key")
+	}
+	if cn := commonName(t, r); cn != "second" {
+		t.Fatalf("expected second, got %s", cn)
+	}
+
+	// once fixed, the next handshake picks the new certificate up
+	writeCertificate(t, certFile, keyFile, "third")
+	if cn := commonName(t, r); cn != "third" {
+		t.Fatalf("expected third, got %s", cn)
+	}
+}
@@ -2,24 +2,25 @@ package tarpc
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
+	"github.com/LiangQinghai/kratos-ext/pkg/resolver"
+	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/selector/wrr"
 	"github.com/go-kratos/kratos/v2/transport"
-	"github.com/lesismal/arpc/codec"
-	"github.com/lesismal/arpc/extension/micro"
-	"github.com/lesismal/arpc/util"
-	"net"
+	"github.com/lesismal/arpc"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-const (
-	balancerName = "selector"
-)
-
 func init() {
 	if selector.GlobalSelector() == nil {
 		selector.SetGlobalSelector(wrr.NewBuilder())
@@ -29,7 +30,8 @@ func init() {
 // ClientOption is arpc client option.
 type ClientOption func(o *clientOptions)
 
-// WithEndpoint with client endpoint.
+// WithEndpoint with client endpoint, such as "127.0.0.1:9090", "direct:///127.0.0.1:9090", "discovery:///svc"
+// or the endpoint of a server on a unix socket, such as "arpc+unix:///tmp/app.sock".
 func WithEndpoint(endpoint string) ClientOption {
 	return func(o *clientOptions) {
 		o.endpoint = endpoint
@@ -64,95 +66,235 @@ func WithNodeFilter(filters ...selector.NodeFilter) ClientOption {
 	}
 }
 
+// WithSelector with node selector builder, such as wrr, p2c or random,
+// selector.GlobalSelector is used by default.
+func WithSelector(b selector.Builder) ClientOption {
+	return func(o *clientOptions) {
+		o.selector = b
+	}
+}
+
+// WithTLSConfig with the tls config of the connections, the arpcs endpoint of the instances
+// is picked from the discovery then. Set Certificates for mutual tls, and ServerName for unix sockets.
+func WithTLSConfig(c *tls.Config) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.tlsConf = c
+	}
+}
+
+// WithPoolSize with the max number of connections per node, 1 by default.
+// Another connection is dialed while every one has calls in flight.
+func WithPoolSize(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.size = n
+	}
+}
+
+// WithDialTimeout with the timeout of a dial, it bounds the heartbeats as well.
+func WithDialTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.dialTimeout = d
+	}
+}
+
+// WithKeepalive with the tcp keepalive period, a heartbeat is sent on a connection
+// without calls for that long as well and the connection dropped unless it is answered.
+func WithKeepalive(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.keepalive = d
+	}
+}
+
+// WithIdleTimeout with how long a connection without calls is kept, forever by default.
+func WithIdleTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.idleTimeout = d
+	}
+}
+
+// WithMaxInFlight with the max number of concurrent calls and streams per connection, unlimited by default.
+// A call fails with NodeBusyError once every connection of the node picked has reached it.
+func WithMaxInFlight(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.maxInFlight = n
+	}
+}
+
+// WithReconnectBackoff with the delay before redialing a node after a failed dial,
+// it doubles with every failure from base up to max.
+func WithReconnectBackoff(base, max time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.pool.backoffBase = base
+		o.pool.backoffMax = max
+	}
+}
+
+// WithStreamWindow with the number of frames a stream receives before the server waits for acknowledgement.
+func WithStreamWindow(n uint32) ClientOption {
+	return func(o *clientOptions) {
+		o.streamWindow = n
+	}
+}
+
+// WithCodec with the codec of the request and reply messages, json by default.
+// The server must use the same codec.
+func WithCodec(c encoding.Codec) ClientOption {
+	return func(o *clientOptions) {
+		o.codec = c
+	}
+}
+
 // clientOptions is arpc client config
 type clientOptions struct {
 	endpoint     string
 	timeout      time.Duration
 	discovery    registry.Discovery
 	middleware   []middleware.Middleware
-	balancerName string
 	filters      []selector.NodeFilter
+	selector     selector.Builder
+	streamWindow uint32
+	codec        encoding.Codec
+	pool         poolOptions
 }
 
 func Dail(ctx context.Context, opts ...ClientOption) (*Client, error) {
 	options := clientOptions{
 		timeout:      time.Second * 5,
-		balancerName: balancerName,
+		streamWindow: defaultStreamWindow,
+		codec:        encoding.GetCodec(defaultCodecName),
+		pool:         poolOptions{dialTimeout: defaultDialTimeout},
 	}
 	for _, opt := range opts {
 		opt(&options)
 	}
-	serviceManager := micro.NewServiceManager(
-		func(addr string) (net.Conn, error) {
-			return net.Dial("tcp", addr)
-		})
-	if options.discovery != nil {
-		watch, err := options.discovery.Watch(ctx, options.endpoint[13:])
-		if err != nil {
-			return nil, err
+	scheme := endpoint.Scheme("arpc", options.pool.tlsConf != nil)
+	target, err := resolver.ParseTarget(options.endpoint, scheme)
+	if err != nil {
+		return nil, err
+	}
+	builder := options.selector
+	if builder == nil {
+		builder = selector.GlobalSelector()
+	}
+	c := &Client{
+		opts:     &options,
+		envelope: uint32(envelopeV1),
+		selector: builder.Build(),
+		pool:     newConnPool(options.pool),
+	}
+	rb := &rebalancer{selector: c.selector, pool: c.pool}
+	if target.Scheme == "discovery" {
+		if options.discovery == nil {
+			c.pool.close()
+			return nil, fmt.Errorf("tarpc: discovery endpoint %s without discovery", options.endpoint)
 		}
-		d := &discovery{
-			w:                watch,
-			target:           options.endpoint[13:],
-			serviceNamespace: "defaultServiceNamespace",
-			serviceManager:   serviceManager,
-			ctx:              ctx,
+		if c.r, err = resolver.New(ctx, options.discovery, target, rb, false, scheme); err != nil {
+			c.pool.close()
+			return nil, err
 		}
-		go d.watch()
 	} else {
-		serviceManager.AddServiceNodes(fmt.Sprintf("defaultServiceNamespace/%s/%s", options.endpoint, options.endpoint), "10")
+		addr := target.Authority
+		if target.Scheme == "direct" {
+			addr = target.Endpoint
+		}
+		ept := endpoint.NewEndpoint(scheme, addr)
+		if u, err := url.Parse(options.endpoint); err == nil {
+			if path, _, ok := endpoint.UnixPath(u); ok {
+				addr, ept = unixPrefix+path, u
+			}
+		}
+		rb.Apply([]selector.Node{selector.NewNode(scheme, addr, &registry.ServiceInstance{
+			Endpoints: []string{ept.String()},
+		})})
 	}
-	return &Client{
-		opts:           &options,
-		serviceManager: serviceManager,
-	}, nil
+	return c, nil
 }
 
 type Client struct {
-	opts           *clientOptions
-	serviceManager micro.ServiceManager
+	opts *clientOptions
+	// envelope is the envelope version of the requests
+	envelope  uint32
+	selector  selector.Selector
+	pool      *connPool
+	r         *resolver.Resolver
+	closeOnce sync.Once
+}
+
+// Close stops watching the discovery and closes the connections of the client.
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		if c.r != nil {
+			err = c.r.Close()
+		}
+		c.pool.close()
+	})
+	return err
+}
+
+// Stats returns a snapshot of the connections of the client.
+func (c *Client) Stats() PoolStats {
+	return c.pool.stats()
+}
+
+// pick selects a node with the selector and returns a connection of it, unless an error is returned
+// the connection must be released and done called with the outcome of the call.
+func (c *Client) pick(ctx context.Context) (*poolConn, selector.DoneFunc, error) {
+	node, done, err := c.selector.Select(ctx, selector.WithNodeFilter(c.opts.filters...))
+	if err != nil {
+		return nil, nil, errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
+	}
+	pc, err := c.pool.get(node.Address())
+	if err != nil {
+		done(ctx, selector.DoneInfo{Err: err})
+		return nil, nil, err
+	}
+	return pc, done, nil
 }
 
 func (c *Client) Call(ctx context.Context, method string, req any, resp any) error {
 
-	ctx = transport.NewClientContext(ctx, &Transport{
-		operation: method,
-		reqHeader: headerCarrier{},
-	})
+	tr := &Transport{
+		operation:   method,
+		reqHeader:   headerCarrier{},
+		replyHeader: headerCarrier{},
+		nodeFilters: c.opts.filters,
+	}
+	ctx = transport.NewClientContext(ctx, tr)
 
 	var h middleware.Handler = func(ctx context.Context, req interface{}) (interface{}, error) {
-		reqMsg := c.newMessage(ctx, req)
-		var replyMsg MessageWrapper
-		var err error
-		ac, err := c.serviceManager.ClientBy(c.opts.endpoint[13:])
+		timeout, err := callTimeout(ctx, c.opts.timeout)
 		if err != nil {
 			return nil, err
 		}
-		if c.opts.timeout > 0 {
-			err = ac.Call(
-				method,
-				reqMsg,
-				&replyMsg,
-				c.opts.timeout,
-			)
-		} else {
-			err = ac.CallWith(
-				ctx,
-				method,
-				reqMsg,
-				&replyMsg,
-			)
+		reqMsg, err := c.newMessage(ctx, req)
+		if err != nil {
+			return nil, err
 		}
+		reqMsg.Timeout = timeout
+		var replyMsg MessageWrapper
+		pc, done, err := c.pick(ctx)
 		if err != nil {
 			return nil, err
 		}
-		if replyMsg.Err != nil {
-			return nil, replyMsg.Err
+		_, err = c.invoke(ctx, pc.client, method, timeout, reqMsg, &replyMsg)
+		if err == nil {
+			for k, v := range replyMsg.Headers {
+				tr.replyHeader[strings.ToLower(k)] = v
+			}
+			if replyMsg.Err != nil {
+				err = replyMsg.Err
+			}
+		}
+		pc.release()
+		done(ctx, selector.DoneInfo{Err: err, ReplyMD: tr.replyHeader})
+		if err != nil {
+			return nil, err
 		}
 		if replyMsg.Data == nil {
 			return nil, nil
 		}
-		err = util.BytesToValue(codec.DefaultCodec, replyMsg.Data, resp)
+		err = decodeData(c.opts.codec, &replyMsg, resp)
 		if err != nil {
 			return nil, err
 		}
@@ -167,15 +309,51 @@ func (c *Client) Call(ctx context.Context, method string, req any, resp any) err
 	return err
 }
 
-func (c *Client) newMessage(ctx context.Context, data any) *MessageWrapper {
+// invoke calls method with req in the negotiated envelope and returns the version used.
+// It falls back to the json envelope for good once a server answers with it,
+// such a server predates the binary envelope and failed to decode the request.
+// The call is aborted once ctx is done or timeout, if positive, has elapsed.
+func (c *Client) invoke(ctx context.Context, ac *arpc.Client, method string, timeout time.Duration, req, reply *MessageWrapper) (byte, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	for {
+		version := byte(atomic.LoadUint32(&c.envelope))
+		data, err := marshalEnvelope(version, req)
+		if err != nil {
+			return version, err
+		}
+		var rsp []byte
+		if err = ac.CallWith(ctx, method, data, &rsp); err != nil {
+			return version, contextError(ctx, err)
+		}
+		replyVersion, err := unmarshalEnvelope(rsp, reply)
+		if err != nil {
+			return version, err
+		}
+		if version != envelopeJSON && replyVersion == envelopeJSON {
+			atomic.StoreUint32(&c.envelope, uint32(envelopeJSON))
+			*reply = MessageWrapper{}
+			continue
+		}
+		return version, nil
+	}
+}
+
+func (c *Client) newMessage(ctx context.Context, data any) (*MessageWrapper, error) {
 	if err, ok := data.(error); ok {
-		return c.errorEncode(ctx, err)
+		return c.errorEncode(ctx, err), nil
 	}
-	headers := c.parseHeader(ctx)
-	return &MessageWrapper{
-		Headers: headers,
-		Data:    util.ValueToBytes(codec.DefaultCodec, data),
+	mw := &MessageWrapper{Headers: c.parseHeader(ctx)}
+	if data == nil {
+		return mw, nil
+	}
+	if err := encodeData(c.opts.codec, mw, data); err != nil {
+		return nil, err
 	}
+	return mw, nil
 }
 
 func (c *Client) parseHeader(ctx context.Context) map[string][]string {
@@ -0,0 +1,353 @@
+package tarpc
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/random"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testInstance(addr string, weight string) *registry.ServiceInstance {
+	return &registry.ServiceInstance{
+		Endpoints: []string{"http://" + addr, "arpc://" + addr},
+		Metadata:  map[string]string{"weight": weight},
+	}
+}
+
+func (p *connPool) addrs() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	addrs := make([]string, 0, len(p.nodes))
+	for addr := range p.nodes {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+func TestClientDiscovery(t *testing.T) {
+	d := testutil.NewDiscovery()
+	client, err := Dail(context.Background(), WithEndpoint("discovery:///svc"), WithDiscovery(d), WithSelector(random.NewBuilder()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name      string
+		instances []*registry.ServiceInstance
+		want      []string
+	}{
+		{"add", []*registry.ServiceInstance{testInstance("a:1", "10"), testInstance("b:1", "20")}, []string{"a:1", "b:1"}},
+		{"remove", []*registry.ServiceInstance{testInstance("b:1", "20")}, []string{"b:1"}},
+		{"empty", nil, []string{"b:1"}},
+		{"no arpc endpoint", []*registry.ServiceInstance{{Endpoints: []string{"http://c:1"}}}, []string{"b:1"}},
+		{"replace", []*registry.ServiceInstance{testInstance("c:1", "10")}, []string{"c:1"}},
+	}
+	for _, step := range steps {
+		d.Update(step.instances)
+		// the next send waits for the snapshot to be applied
+		d.Update(step.instances)
+		if got := client.pool.addrs(); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: expected %v got %v", step.name, step.want, got)
+		}
+	}
+
+	if err = client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Watchers() != 0 {
+		t.Error("expected the watcher to be stopped")
+	}
+	if got := client.pool.addrs(); len(got) != 0 {
+		t.Errorf("expected the nodes to be removed got %v", got)
+	}
+	client.pool.add([]string{"d:1"})
+	if got := client.pool.addrs(); len(got) != 0 {
+		t.Errorf("expected a closed pool to ignore nodes got %v", got)
+	}
+	if err = client.Close(); err != nil {
+		t.Errorf("expected close to be idempotent got %v", err)
+	}
+	if _, err = Dail(context.Background(), WithEndpoint("discovery:///svc")); err == nil {
+		t.Error("expected an error without discovery")
+	}
+}
+
+func TestClientEndpoint(t *testing.T) {
+	for endpoint, want := range map[string]string{
+		"127.0.0.1:9090":            "127.0.0.1:9090",
+		"direct:///127.0.0.1:9090":  "127.0.0.1:9090",
+		"arpc+unix:///tmp/app.sock": "unix:/tmp/app.sock",
+	} {
+		client, err := Dail(context.Background(), WithEndpoint(endpoint))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := client.pool.addrs(); !reflect.DeepEqual(got, []string{want}) {
+			t.Errorf("%s: expected %s got %v", endpoint, want, got)
+		}
+		_ = client.Close()
+	}
+}
+
+func TestClientTLSEndpoint(t *testing.T) {
+	d := testutil.NewDiscovery()
+	client, err := Dail(context.Background(), WithEndpoint("discovery:///svc"), WithDiscovery(d), WithTLSConfig(&tls.Config{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	instances := []*registry.ServiceInstance{
+		{Endpoints: []string{"arpc://a:1", "arpcs://a:2"}},
+		{Endpoints: []string{"arpc://b:1"}},
+	}
+	d.Update(instances)
+	d.Update(instances)
+	if got := client.pool.addrs(); !reflect.DeepEqual(got, []string{"a:2"}) {
+		t.Errorf("expected the secure endpoints got %v", got)
+	}
+}
+
+func TestClientPick(t *testing.T) {
+	d := testutil.NewDiscovery()
+	onlyB := func(_ context.Context, nodes []selector.Node) []selector.Node {
+		filtered := make([]selector.Node, 0, len(nodes))
+		for _, node := range nodes {
+			if node.Address() == "b:1" {
+				filtered = append(filtered, node)
+			}
+		}
+		return filtered
+	}
+	client, err := Dail(context.Background(), WithEndpoint("discovery:///svc"), WithDiscovery(d),
+		WithSelector(random.NewBuilder()), WithNodeFilter(onlyB), WithReconnectBackoff(time.Nanosecond, time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	dialed := make(chan string, 1)
+	client.pool.dial = func(addr string) (net.Conn, error) {
+		dialed <- addr
+		return nil, errors.New(500, "DIAL", "refused")
+	}
+
+	if _, _, err = client.pick(context.Background()); errors.Reason(err) != "NODE_NOT_FOUND" {
+		t.Errorf("expected %s before any node got %v", "NODE_NOT_FOUND", err)
+	}
+	d.Update([]*registry.ServiceInstance{testInstance("a:1", "10")})
+	d.Update(nil)
+	if _, _, err = client.pick(context.Background()); errors.Reason(err) != "NODE_NOT_FOUND" {
+		t.Errorf("expected the filter to exclude the node got %v", err)
+	}
+	d.Update([]*registry.ServiceInstance{testInstance("a:1", "10"), testInstance("b:1", "10")})
+	d.Update(nil)
+	var p selector.Peer
+	for i := 0; i < 10; i++ {
+		_, _, err = client.pick(selector.NewPeerContext(context.Background(), &p))
+		if errors.Reason(err) != "NODE_UNREACHABLE" {
+			t.Fatalf("expected %s got %v", "NODE_UNREACHABLE", err)
+		}
+		if addr := <-dialed; addr != "b:1" || p.Node == nil || p.Node.Address() != "b:1" {
+			t.Fatalf("expected b:1 to be picked got %s %v", addr, p.Node)
+		}
+	}
+}
+
+func TestConnPool(t *testing.T) {
+	p := newConnPool(poolOptions{})
+	defer p.close()
+	p.dial = func(addr string) (net.Conn, error) {
+		return nil, errors.New(500, "DIAL", "refused")
+	}
+	if _, err := p.get("a:1"); !errors.Is(err, NodeGoneError) {
+		t.Errorf("expected %v got %v", NodeGoneError, err)
+	}
+	p.add([]string{"a:1", "b:1"})
+	p.add([]string{"b:1"})
+	if got := p.addrs(); !reflect.DeepEqual(got, []string{"a:1", "b:1"}) {
+		t.Errorf("expected the nodes to be added got %v", got)
+	}
+	p.retain([]string{"b:1", "c:1"})
+	if got := p.addrs(); !reflect.DeepEqual(got, []string{"b:1"}) {
+		t.Errorf("expected the departed nodes to be removed got %v", got)
+	}
+	if _, err := p.get("a:1"); !errors.Is(err, NodeGoneError) {
+		t.Errorf("expected %v got %v", NodeGoneError, err)
+	}
+	if _, err := p.get("b:1"); errors.Reason(err) != "NODE_UNREACHABLE" {
+		t.Errorf("expected %s got %v", "NODE_UNREACHABLE", err)
+	}
+}
+
+func pipeDial(string) (net.Conn, error) {
+	conn, _ := net.Pipe()
+	return conn, nil
+}
+
+func TestConnPoolGrow(t *testing.T) {
+	p := newConnPool(poolOptions{size: 2, maxInFlight: 1})
+	defer p.close()
+	p.dial = pipeDial
+	p.add([]string{"a:1"})
+
+	first, err := p.get("a:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the only connection is busy, another one is dialed in the background
+	if _, err = p.get("a:1"); !errors.Is(err, NodeBusyError) {
+		t.Errorf("expected %v got %v", NodeBusyError, err)
+	}
+	for i := 0; i < 100 && p.stats().Nodes[0].Conns < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	second, err := p.get("a:1")
+	if err != nil || second == first {
+		t.Fatalf("expected the second connection got %v", err)
+	}
+	if _, err = p.get("a:1"); !errors.Is(err, NodeBusyError) {
+		t.Errorf("expected %v with a full pool got %v", NodeBusyError, err)
+	}
+	first.release()
+	if pc, err := p.get("a:1"); err != nil || pc != first {
+		t.Errorf("expected the released connection got %v", err)
+	}
+	stats := p.stats()
+	want := NodeStats{Address: "a:1", Conns: 2, InFlight: 2}
+	if stats.Dials != 2 || stats.Rejected != 2 || !reflect.DeepEqual(stats.Nodes, []NodeStats{want}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestConnPoolFirstDial(t *testing.T) {
+	p := newConnPool(poolOptions{})
+	defer p.close()
+	dialing, release := make(chan struct{}), make(chan struct{})
+	var dials int32
+	p.dial = func(addr string) (net.Conn, error) {
+		if atomic.AddInt32(&dials, 1) == 1 {
+			close(dialing)
+		}
+		<-release
+		return pipeDial(addr)
+	}
+	p.add([]string{"a:1"})
+	var wg sync.WaitGroup
+	conns := make([]*poolConn, 3)
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pc, err := p.get("a:1")
+			if err != nil {
+				t.Error(err)
+			}
+			conns[i] = pc
+		}(i)
+	}
+	<-dialing
+	// the node is not locked during the dial
+	if ns := p.stats().Nodes[0]; ns.Dialing != 1 || ns.Conns != 0 {
+		t.Errorf("expected the first connection to be dialing got %+v", ns)
+	}
+	close(release)
+	wg.Wait()
+	if atomic.LoadInt32(&dials) != 1 || conns[0] == nil || conns[1] != conns[0] || conns[2] != conns[0] {
+		t.Errorf("expected the calls to share the first connection got %d dials", dials)
+	}
+}
+
+func TestConnPoolBackoff(t *testing.T) {
+	p := newConnPool(poolOptions{backoffBase: time.Minute, backoffMax: time.Hour})
+	defer p.close()
+	var dials int
+	p.dial = func(string) (net.Conn, error) {
+		dials++
+		return nil, errors.New(500, "DIAL", "refused")
+	}
+	p.add([]string{"a:1"})
+	for i := 0; i < 2; i++ {
+		if _, err := p.get("a:1"); errors.Reason(err) != "NODE_UNREACHABLE" {
+			t.Errorf("expected %s got %v", "NODE_UNREACHABLE", err)
+		}
+	}
+	if dials != 1 {
+		t.Errorf("expected no dial before the backoff elapsed got %d dials", dials)
+	}
+	stats := p.stats()
+	if ns := stats.Nodes[0]; ns.Failures != 1 || time.Until(ns.RetryAt) < 29*time.Second || stats.DialFailures != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	for failures, limit := range map[int]time.Duration{1: time.Minute, 3: 4 * time.Minute, 100: time.Hour} {
+		if d := p.backoff(failures); d < limit/2 || d > limit {
+			t.Errorf("%d failures: expected a backoff within [%v, %v] got %v", failures, limit/2, limit, d)
+		}
+	}
+}
+
+func TestConnPoolDrop(t *testing.T) {
+	p := newConnPool(poolOptions{idleTimeout: time.Hour})
+	defer p.close()
+	p.dial = pipeDial
+	p.add([]string{"a:1"})
+
+	pc, err := p.get("a:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.sweep(time.Now().Add(2 * time.Hour))
+	if stats := p.stats(); stats.Nodes[0].Conns != 1 {
+		t.Errorf("expected a busy connection to be kept got %+v", stats)
+	}
+	pc.release()
+	p.sweep(time.Now().Add(2 * time.Hour))
+	if stats := p.stats(); stats.Nodes[0].Conns != 0 || stats.Reaped != 1 {
+		t.Errorf("expected the idle connection to be reaped got %+v", stats)
+	}
+
+	if pc, err = p.get("a:1"); err != nil {
+		t.Fatal(err)
+	}
+	handleDisconnected(pc.client)
+	handleDisconnected(pc.client)
+	if stats := p.stats(); stats.Nodes[0].Conns != 0 || stats.Disconnects != 1 {
+		t.Errorf("expected the disconnected connection to be dropped got %+v", stats)
+	}
+	if _, err = p.get("a:1"); err != nil {
+		t.Errorf("expected the node to be redialed got %v", err)
+	}
+	if stats := p.stats(); stats.Dials != 3 {
+		t.Errorf("expected %d dials got %d", 3, stats.Dials)
+	}
+}
+
+func TestConnPoolUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	p := newConnPool(poolOptions{dialTimeout: time.Second})
+	defer p.close()
+	conn, err := p.dial(unixPrefix + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if conn.RemoteAddr().Network() != "unix" {
+		t.Errorf("expected a unix connection got %s", conn.RemoteAddr().Network())
+	}
+}
@@ -2,14 +2,66 @@ package tarpc
 
 import (
 	"context"
+	"fmt"
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json"
 	"github.com/go-kratos/kratos/v2/errors"
 	"google.golang.org/grpc/metadata"
+	"time"
 )
 
+// defaultCodecName is the codec of the messages without a codec name.
+const defaultCodecName = "json"
+
 type MessageWrapper struct {
 	Data    []byte              `json:"data"`
 	Headers map[string][]string `json:"headers"`
 	Err     *errors.Error       `json:"error"`
+	// Stream is the id of the stream the frame belongs to, zero for unary calls.
+	Stream uint64 `json:"stream,omitempty"`
+	// End marks the last frame of a stream side, Err carries the status of the server side.
+	End bool `json:"end,omitempty"`
+	// Window is the receive window advertised or the frames acknowledged by the peer.
+	Window uint32 `json:"window,omitempty"`
+	// Codec is the name of the kratos codec Data is encoded with.
+	Codec string `json:"codec,omitempty"`
+	// Timeout is the time left to the deadline of the caller, zero for none.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// encodeData encodes v with c as the data of the message.
+func encodeData(c encoding.Codec, mw *MessageWrapper, v any) error {
+	data, err := c.Marshal(v)
+	if err != nil {
+		return err
+	}
+	mw.Data = data
+	mw.Codec = c.Name()
+	return nil
+}
+
+// checkCodec fails if the data of the message is not encoded with c,
+// a peer with another codec would decode garbage otherwise.
+func checkCodec(c encoding.Codec, mw *MessageWrapper) error {
+	if len(mw.Data) == 0 {
+		return nil
+	}
+	name := mw.Codec
+	if name == "" {
+		name = defaultCodecName
+	}
+	if name != c.Name() {
+		return errors.BadRequest("CODEC_MISMATCH", fmt.Sprintf("message encoded with %s codec, expected %s", name, c.Name()))
+	}
+	return nil
+}
+
+// decodeData decodes the data of the message with c.
+func decodeData(c encoding.Codec, mw *MessageWrapper, v any) error {
+	if err := checkCodec(c, mw); err != nil || len(mw.Data) == 0 {
+		return err
+	}
+	return c.Unmarshal(mw.Data, v)
 }
 
 var defaultErrorEncoder EncodeErrorFunc = DefaultErrorEncoder
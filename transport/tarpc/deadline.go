@@ -0,0 +1,53 @@
+package tarpc
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
+	"github.com/go-kratos/kratos/v2/errors"
+	"time"
+)
+
+var CanceledError = errors.New(499, "CANCELED", "call canceled by the caller")
+
+// deadlineKey is the context key of the caller deadline on the server side.
+type deadlineKey struct{}
+
+// callTimeout returns the time left to the deadline of ctx, bounded by d when it is positive.
+// It fails once the deadline has passed, such a call is not worth sending.
+func callTimeout(ctx context.Context, d time.Duration) (time.Duration, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		left := time.Until(deadline)
+		if left <= 0 {
+			return 0, timeout.DeadlineExceededError
+		}
+		if d <= 0 || left < d {
+			d = left
+		}
+	}
+	return d, nil
+}
+
+// contextError returns the kratos error of a call aborted by ctx, err otherwise.
+func contextError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return timeout.DeadlineExceededError
+	case context.Canceled:
+		return CanceledError
+	}
+	return err
+}
+
+// withCallerDeadline records the deadline of a caller with timeout left on the server side.
+func withCallerDeadline(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, deadlineKey{}, time.Now().Add(timeout))
+}
+
+// callerDeadline returns the deadline recorded by withCallerDeadline.
+func callerDeadline(ctx context.Context) (time.Time, bool) {
+	deadline, ok := ctx.Value(deadlineKey{}).(time.Time)
+	return deadline, ok
+}
@@ -1,63 +1,23 @@
 package tarpc
 
 import (
-	"context"
-	"fmt"
-	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
-	"github.com/go-kratos/kratos/v2/errors"
-	"github.com/go-kratos/kratos/v2/log"
-	"github.com/go-kratos/kratos/v2/registry"
-	"github.com/lesismal/arpc/extension/micro"
-	"strconv"
-	"time"
+	"github.com/go-kratos/kratos/v2/selector"
 )
 
-type discovery struct {
-	w                registry.Watcher
-	target           string
-	serviceNamespace string
-	serviceManager   micro.ServiceManager
-	ctx              context.Context
+// rebalancer applies the nodes of the discovery to the selector and to the pool.
+type rebalancer struct {
+	selector selector.Rebalancer
+	pool     *connPool
 }
 
-func (d *discovery) watch() {
-	for {
-		select {
-		case <-d.ctx.Done():
-			return
-		default:
-
-		}
-		instances, err := d.w.Next()
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				return
-			}
-			log.Errorf("[resolver] Failed to watch discovery endpoint: %v", err)
-			time.Sleep(time.Second)
-			continue
-		}
-		d.update(instances)
-	}
-}
-
-func (d *discovery) update(serviceInstances []*registry.ServiceInstance) {
-	for _, instance := range serviceInstances {
-		ept, _ := endpoint.ParseEndpoint(instance.Endpoints, endpoint.Scheme("arpc", false))
-		path := fmt.Sprintf("%s/%s/%s", d.serviceNamespace, d.target, ept)
-		sweight := 10
-		if str, ok := instance.Metadata["weight"]; ok {
-			if weight, err := strconv.ParseInt(str, 10, 64); err == nil {
-				sweight = int(weight)
-			}
-		}
-		d.serviceManager.AddServiceNodes(path, strconv.Itoa(sweight))
-	}
-}
-
-func (d *discovery) Close() {
-	err := d.w.Stop()
-	if err != nil {
-		log.Errorf("[resolver] failed to watch top: %s", err)
+func (r *rebalancer) Apply(nodes []selector.Node) {
+	addrs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		addrs = append(addrs, node.Address())
 	}
+	// the new nodes are in the pool before they can be picked,
+	// the departed ones leave it once they can no longer be
+	r.pool.add(addrs)
+	r.selector.Apply(nodes)
+	r.pool.retain(addrs)
 }
@@ -0,0 +1,69 @@
+package tarpc
+
+import (
+	"github.com/go-kratos/kratos/v2/errors"
+	"sync"
+)
+
+var DrainingError = errors.ServiceUnavailable("SERVER_DRAINING", "server is shutting down")
+
+// drain counts the in-flight calls and streams so that Stop can wait for them.
+type drain struct {
+	mu       sync.Mutex
+	draining bool
+	active   int
+	idle     chan struct{}
+}
+
+// acquire counts a new call, it fails once the server is draining.
+func (d *drain) acquire() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.active++
+	return true
+}
+
+// hold counts the stream opened by an in-flight call, even while draining.
+func (d *drain) hold() {
+	d.mu.Lock()
+	d.active++
+	d.mu.Unlock()
+}
+
+func (d *drain) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active--
+	if d.draining && d.active == 0 {
+		close(d.idle)
+	}
+}
+
+// start rejects the new calls and returns a channel closed once none is in flight.
+func (d *drain) start() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.draining {
+		d.draining = true
+		d.idle = make(chan struct{})
+		if d.active == 0 {
+			close(d.idle)
+		}
+	}
+	return d.idle
+}
+
+// track counts the calls of handler, the calls received while draining are rejected.
+func (s *Server) track(handler HandlerFunc) HandlerFunc {
+	return func(c *Ctx) {
+		if !s.drain.acquire() {
+			s.Write(c, s.ene(c, DrainingError))
+			return
+		}
+		defer s.drain.release()
+		handler(c)
+	}
+}
@@ -0,0 +1,103 @@
+package tarpc
+
+import (
+	"encoding/json"
+	"github.com/LiangQinghai/kratos-ext/transport/tarpc/internal/envelope"
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/proto"
+	"time"
+)
+
+// the first byte of a message is the version of its envelope, the server answers
+// with the envelope of the request so that peers of both versions interoperate.
+const (
+	// envelopeJSON is the legacy json encoded MessageWrapper, starting with '{'.
+	envelopeJSON byte = '{'
+	// envelopeV1 is followed by a protobuf encoded envelope.Envelope.
+	envelopeV1 byte = 1
+)
+
+var EnvelopeVersionError = errors.New(400, "ENVELOPE_VERSION", "unsupported envelope version")
+
+// marshalEnvelope encodes mw in the envelope of version.
+func marshalEnvelope(version byte, mw *MessageWrapper) ([]byte, error) {
+	if version == envelopeJSON {
+		return json.Marshal(mw)
+	}
+	env := &envelope.Envelope{
+		Data:    mw.Data,
+		Stream:  mw.Stream,
+		End:     mw.End,
+		Window:  mw.Window,
+		Codec:   mw.Codec,
+		Timeout: int64(mw.Timeout),
+	}
+	if len(mw.Headers) > 0 {
+		env.Headers = make(map[string]*envelope.Values, len(mw.Headers))
+		for k, v := range mw.Headers {
+			env.Headers[k] = &envelope.Values{Values: v}
+		}
+	}
+	if mw.Err != nil {
+		env.Status = &envelope.Status{
+			Code:     mw.Err.Code,
+			Reason:   mw.Err.Reason,
+			Message:  mw.Err.Message,
+			Metadata: mw.Err.Metadata,
+		}
+	}
+	buf := make([]byte, 1, 1+proto.Size(env))
+	buf[0] = envelopeV1
+	return proto.MarshalOptions{}.MarshalAppend(buf, env)
+}
+
+// unmarshalEnvelope decodes data into mw and returns the version of its envelope.
+func unmarshalEnvelope(data []byte, mw *MessageWrapper) (byte, error) {
+	if len(data) == 0 {
+		return envelopeV1, nil
+	}
+	switch data[0] {
+	case envelopeJSON:
+		return envelopeJSON, json.Unmarshal(data, mw)
+	case envelopeV1:
+		var env envelope.Envelope
+		if err := proto.Unmarshal(data[1:], &env); err != nil {
+			return envelopeV1, err
+		}
+		mw.Data = env.Data
+		mw.Stream = env.Stream
+		mw.End = env.End
+		mw.Window = env.Window
+		mw.Codec = env.Codec
+		mw.Timeout = time.Duration(env.Timeout)
+		if len(env.Headers) > 0 {
+			mw.Headers = make(map[string][]string, len(env.Headers))
+			for k, v := range env.Headers {
+				mw.Headers[k] = v.GetValues()
+			}
+		}
+		if st := env.Status; st != nil {
+			mw.Err = errors.New(int(st.Code), st.Reason, st.Message).WithMetadata(st.Metadata)
+		}
+		return envelopeV1, nil
+	default:
+		return data[0], EnvelopeVersionError
+	}
+}
+
+// envelopeOf returns the envelope version of the request, the reply is written with it.
+func envelopeOf(c *Ctx) byte {
+	if body := c.Body(); len(body) > 0 && body[0] == envelopeJSON {
+		return envelopeJSON
+	}
+	return envelopeV1
+}
+
+// writeMessage writes mw as the reply of c in the envelope of the request.
+func writeMessage(c *Ctx, mw *MessageWrapper) error {
+	data, err := marshalEnvelope(envelopeOf(c), mw)
+	if err != nil {
+		return err
+	}
+	return c.Write(data)
+}
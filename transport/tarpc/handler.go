@@ -13,7 +13,7 @@ func RecoveryHandler() HandlerFunc {
 			if err := recover(); err != nil {
 				log.Errorf("panic recovery from %v", err)
 				mw := defaultErrorEncoder(ctx, err.(error))
-				e := ctx.Write(mw)
+				e := writeMessage(ctx, mw)
 				if e != nil {
 					log.Errorf("recover write err:%v", e)
 				}
@@ -0,0 +1,102 @@
+package tarpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kratos/kratos/v2/errors"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"strings"
+	"sync"
+)
+
+// HealthCheckMethod is the arpc method of the health service, it mirrors the grpc one.
+const HealthCheckMethod = "/grpc.health.v1.Health/Check"
+
+// health holds the serving status by service name, the empty name stands for the whole server.
+type health struct {
+	mu       sync.Mutex
+	shutdown bool
+	statuses map[string]healthpb.HealthCheckResponse_ServingStatus
+}
+
+func newHealth() *health {
+	return &health{
+		statuses: map[string]healthpb.HealthCheckResponse_ServingStatus{
+			"": healthpb.HealthCheckResponse_SERVING,
+		},
+	}
+}
+
+// add registers the service of method as serving, e.g. helloworld.Greeter for /helloworld.Greeter/SayHello.
+func (h *health) add(method string) {
+	service, _, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if !ok || service == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.statuses[service]; !ok {
+		h.statuses[service] = healthpb.HealthCheckResponse_SERVING
+		if h.shutdown {
+			h.statuses[service] = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+}
+
+func (h *health) set(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.shutdown {
+		return
+	}
+	h.statuses[service] = status
+}
+
+// stop marks every service as not serving for good.
+func (h *health) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.shutdown = true
+	for service := range h.statuses {
+		h.statuses[service] = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+}
+
+func (h *health) check(service string) (*healthpb.HealthCheckResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status, ok := h.statuses[service]
+	if !ok {
+		return nil, errors.NotFound("SERVICE_UNKNOWN", fmt.Sprintf("unknown service %s", service))
+	}
+	return &healthpb.HealthCheckResponse{Status: status}, nil
+}
+
+// SetServingStatus sets the serving status of service, the empty name stands for the whole server.
+// The services of the handled methods are serving by default, every service stops serving once
+// the server is stopping and the status can no longer be changed.
+func (s *Server) SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	s.health.set(service, status)
+}
+
+func (s *Server) handleHealthCheck(c *Ctx) {
+	ctx, data, err := s.DecodeRequest(c)
+	var in healthpb.HealthCheckRequest
+	if err == nil {
+		err = s.DecodeData(data, &in)
+	}
+	var reply *healthpb.HealthCheckResponse
+	if err == nil {
+		reply, err = s.health.check(in.Service)
+	}
+	s.Write(c, s.EncodeResponse(ctx, reply, err))
+}
+
+// HealthCheck returns the serving status of service, the empty name stands for the whole server.
+func (c *Client) HealthCheck(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	var reply healthpb.HealthCheckResponse
+	if err := c.Call(ctx, HealthCheckMethod, &healthpb.HealthCheckRequest{Service: service}, &reply); err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, err
+	}
+	return reply.Status, nil
+}
@@ -0,0 +1,518 @@
+package tarpc
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/lesismal/arpc"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultPoolSize    = 1
+	defaultDialTimeout = 3 * time.Second
+	defaultBackoffBase = 100 * time.Millisecond
+	defaultBackoffMax  = 10 * time.Second
+)
+
+var (
+	// NodeGoneError is returned for a node picked right before it left the discovery.
+	NodeGoneError = errors.ServiceUnavailable("NODE_GONE", "node left the discovery")
+	// NodeBusyError is returned when every connection of a node has reached the max in-flight calls.
+	NodeBusyError = errors.ServiceUnavailable("NODE_BUSY", "max in-flight calls reached")
+)
+
+// unixPrefix prefixes the address of a node on a unix socket.
+const unixPrefix = "unix:"
+
+// pooledConns holds the pooled connections by arpc client, so that a disconnected one leaves its pool.
+var pooledConns sync.Map
+
+// poolOptions is the config of the connections of every node.
+type poolOptions struct {
+	// size is the max number of connections per node
+	size        int
+	dialTimeout time.Duration
+	// keepalive is the tcp keepalive period and the heartbeat interval of the idle connections
+	keepalive time.Duration
+	// idleTimeout is how long a connection without calls is kept
+	idleTimeout time.Duration
+	// maxInFlight is the max number of concurrent calls and streams per connection
+	maxInFlight int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	// tlsConf secures the connections unless nil
+	tlsConf *tls.Config
+}
+
+// PoolStats is a snapshot of the connections of a client.
+type PoolStats struct {
+	Nodes []NodeStats
+	// Dials is the number of dials, DialFailures the failed ones.
+	Dials        uint64
+	DialFailures uint64
+	// Disconnects is the number of connections closed by the peer or dropped on a missed heartbeat.
+	Disconnects uint64
+	// Reaped is the number of connections closed for being idle.
+	Reaped uint64
+	// Rejected is the number of calls failed with NodeBusyError.
+	Rejected uint64
+}
+
+// NodeStats is the state of the connections of a node.
+type NodeStats struct {
+	Address  string
+	Conns    int
+	Dialing  int
+	InFlight int
+	// Failures is the number of consecutive failed dials, the node is not dialed again before RetryAt.
+	Failures int
+	RetryAt  time.Time
+}
+
+// connPool holds the arpc connections of the nodes by address, they are dialed on demand.
+type connPool struct {
+	mu   sync.Mutex
+	opts poolOptions
+	dial func(addr string) (net.Conn, error)
+	// handler serves the connections of the pool, arpc.DefaultHandler is left alone
+	handler arpc.Handler
+	nodes   map[string]*nodeConns
+	closed  bool
+	stop    chan struct{}
+
+	dials        uint64
+	dialFailures uint64
+	disconnects  uint64
+	reaped       uint64
+	rejected     uint64
+}
+
+// nodeConns is the connections of a node.
+type nodeConns struct {
+	mu       sync.Mutex
+	addr     string
+	conns    []*poolConn
+	dialing  int
+	failures int
+	retryAt  time.Time
+	lastErr  error
+	gone     bool
+	// first is the dial of the first connection in flight
+	first *nodeDial
+}
+
+// nodeDial is the result of a dial, done is closed once it is known.
+type nodeDial struct {
+	done chan struct{}
+	err  error
+}
+
+// poolConn is a connection of a node, it must be released once the call or the stream is done.
+type poolConn struct {
+	client   *arpc.Client
+	pool     *connPool
+	node     *nodeConns
+	inflight int32
+	// lastUsed is the unix nano time the connection was last released
+	lastUsed int64
+	pinging  int32
+}
+
+func newConnPool(opts poolOptions) *connPool {
+	if opts.size <= 0 {
+		opts.size = defaultPoolSize
+	}
+	if opts.backoffBase <= 0 {
+		opts.backoffBase = defaultBackoffBase
+	}
+	if opts.backoffMax < opts.backoffBase {
+		opts.backoffMax = max(defaultBackoffMax, opts.backoffBase)
+	}
+	dialer := &net.Dialer{Timeout: opts.dialTimeout, KeepAlive: opts.keepalive}
+	p := &connPool{
+		opts: opts,
+		dial: func(addr string) (net.Conn, error) {
+			network := "tcp"
+			if path, ok := strings.CutPrefix(addr, unixPrefix); ok {
+				network, addr = "unix", path
+			}
+			if opts.tlsConf != nil {
+				return tls.DialWithDialer(dialer, network, addr, opts.tlsConf)
+			}
+			return dialer.Dial(network, addr)
+		},
+		handler: newClientHandler(),
+		nodes:   make(map[string]*nodeConns),
+		stop:    make(chan struct{}),
+	}
+	if interval := sweepInterval(opts); interval > 0 {
+		go p.sweeper(interval)
+	}
+	return p
+}
+
+// sweepInterval is the shorter one of the keepalive and idle timeout, zero without both.
+func sweepInterval(opts poolOptions) time.Duration {
+	switch {
+	case opts.keepalive <= 0:
+		return opts.idleTimeout
+	case opts.idleTimeout <= 0:
+		return opts.keepalive
+	default:
+		return min(opts.keepalive, opts.idleTimeout)
+	}
+}
+
+// get returns the connection of the node at addr to make a call on, the node must have been applied.
+// The idlest connection is used, another one is dialed in the background while they all have calls
+// in flight and the pool of the node is not full. Only the first connection of a node is awaited,
+// the calls wait for the result of its dial without holding the lock of the node.
+func (p *connPool) get(addr string) (*poolConn, error) {
+	p.mu.Lock()
+	nc, ok := p.nodes[addr]
+	p.mu.Unlock()
+	if !ok {
+		return nil, NodeGoneError
+	}
+	for {
+		nc.mu.Lock()
+		pc, first, err := p.pick(nc)
+		nc.mu.Unlock()
+		if first == nil {
+			return pc, err
+		}
+		<-first.done
+		if first.err != nil {
+			return nil, first.err
+		}
+	}
+}
+
+// pick picks the connection of nc, nc.mu must be held. The dial of the first connection
+// is returned while the node has none, the connection is to be picked again once it is done.
+func (p *connPool) pick(nc *nodeConns) (*poolConn, *nodeDial, error) {
+	if nc.first != nil {
+		return nil, nc.first, nil
+	}
+	pc := nc.idlest(p.opts.maxInFlight)
+	if pc != nil && (atomic.LoadInt32(&pc.inflight) == 0 || len(nc.conns)+nc.dialing >= p.opts.size) {
+		return pc.acquire(), nil, nil
+	}
+	if len(nc.conns)+nc.dialing < p.opts.size && !time.Now().Before(nc.retryAt) {
+		nc.dialing++
+		go p.grow(nc)
+		if len(nc.conns) == 0 {
+			nc.first = &nodeDial{done: make(chan struct{})}
+			return nil, nc.first, nil
+		}
+	}
+	if pc != nil {
+		return pc.acquire(), nil, nil
+	}
+	if len(nc.conns) == 0 {
+		return nil, nil, unreachableError(nc.lastErr)
+	}
+	atomic.AddUint64(&p.rejected, 1)
+	return nil, nil, NodeBusyError
+}
+
+// grow dials another connection of nc, the calls waiting for the first connection are told the result.
+func (p *connPool) grow(nc *nodeConns) {
+	client, err := p.connect(nc.addr)
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	nc.dialing--
+	_, err = p.connected(nc, client, err)
+	if first := nc.first; first != nil {
+		nc.first = nil
+		first.err = err
+		close(first.done)
+		return
+	}
+	if err != nil {
+		log.Warnf("[ARPC] dial %s err: %v", nc.addr, err)
+	}
+}
+
+// connect dials the node at addr.
+func (p *connPool) connect(addr string) (*arpc.Client, error) {
+	atomic.AddUint64(&p.dials, 1)
+	return arpc.NewClient(func() (net.Conn, error) {
+		return p.dial(addr)
+	}, p.handler)
+}
+
+// connected adds the dialed client to nc or records the failure of the dial, nc.mu must be held.
+func (p *connPool) connected(nc *nodeConns, client *arpc.Client, err error) (*poolConn, error) {
+	if err != nil {
+		atomic.AddUint64(&p.dialFailures, 1)
+		nc.failures++
+		nc.lastErr = err
+		nc.retryAt = time.Now().Add(p.backoff(nc.failures))
+		return nil, unreachableError(err)
+	}
+	// the node may have left meanwhile
+	if nc.gone {
+		client.Stop()
+		return nil, NodeGoneError
+	}
+	nc.failures = 0
+	nc.lastErr = nil
+	nc.retryAt = time.Time{}
+	pc := &poolConn{client: client, pool: p, node: nc, lastUsed: time.Now().UnixNano()}
+	nc.conns = append(nc.conns, pc)
+	pooledConns.Store(client, pc)
+	return pc, nil
+}
+
+// backoff returns the delay before the next dial of a node, it doubles with every failure
+// up to the max and is jittered so that the clients do not redial at once.
+func (p *connPool) backoff(failures int) time.Duration {
+	d := p.opts.backoffBase
+	for i := 1; i < failures && d < p.opts.backoffMax; i++ {
+		d *= 2
+	}
+	d = min(d, p.opts.backoffMax)
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func unreachableError(err error) error {
+	if err == nil {
+		return errors.ServiceUnavailable("NODE_UNREACHABLE", "node is unreachable")
+	}
+	return errors.ServiceUnavailable("NODE_UNREACHABLE", err.Error())
+}
+
+// add adds the nodes at addrs that are not in the pool yet.
+func (p *connPool) add(addrs []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	for _, addr := range addrs {
+		if _, ok := p.nodes[addr]; !ok {
+			p.nodes[addr] = &nodeConns{addr: addr}
+		}
+	}
+}
+
+// retain keeps the nodes at addrs and closes the connections of the other ones.
+func (p *connPool) retain(addrs []string) {
+	keep := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		keep[addr] = struct{}{}
+	}
+	p.mu.Lock()
+	gone := make([]*nodeConns, 0)
+	for addr, nc := range p.nodes {
+		if _, ok := keep[addr]; !ok {
+			delete(p.nodes, addr)
+			gone = append(gone, nc)
+		}
+	}
+	p.mu.Unlock()
+	for _, nc := range gone {
+		nc.close()
+	}
+}
+
+// close closes the connections of every node.
+func (p *connPool) close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.stop)
+	}
+	p.mu.Unlock()
+	p.retain(nil)
+}
+
+// stats returns a snapshot of the pool.
+func (p *connPool) stats() PoolStats {
+	p.mu.Lock()
+	nodes := make([]*nodeConns, 0, len(p.nodes))
+	for _, nc := range p.nodes {
+		nodes = append(nodes, nc)
+	}
+	p.mu.Unlock()
+	stats := PoolStats{
+		Nodes:        make([]NodeStats, 0, len(nodes)),
+		Dials:        atomic.LoadUint64(&p.dials),
+		DialFailures: atomic.LoadUint64(&p.dialFailures),
+		Disconnects:  atomic.LoadUint64(&p.disconnects),
+		Reaped:       atomic.LoadUint64(&p.reaped),
+		Rejected:     atomic.LoadUint64(&p.rejected),
+	}
+	for _, nc := range nodes {
+		nc.mu.Lock()
+		ns := NodeStats{
+			Address:  nc.addr,
+			Conns:    len(nc.conns),
+			Dialing:  nc.dialing,
+			Failures: nc.failures,
+			RetryAt:  nc.retryAt,
+		}
+		for _, pc := range nc.conns {
+			ns.InFlight += int(atomic.LoadInt32(&pc.inflight))
+		}
+		nc.mu.Unlock()
+		stats.Nodes = append(stats.Nodes, ns)
+	}
+	return stats
+}
+
+// sweeper closes the idle connections and sends heartbeats on the other ones until the pool is closed.
+func (p *connPool) sweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.sweep(now)
+		}
+	}
+}
+
+func (p *connPool) sweep(now time.Time) {
+	p.mu.Lock()
+	nodes := make([]*nodeConns, 0, len(p.nodes))
+	for _, nc := range p.nodes {
+		nodes = append(nodes, nc)
+	}
+	p.mu.Unlock()
+	for _, nc := range nodes {
+		var idle, pings []*poolConn
+		nc.mu.Lock()
+		conns := nc.conns[:0]
+		for _, pc := range nc.conns {
+			unused := now.Sub(time.Unix(0, atomic.LoadInt64(&pc.lastUsed)))
+			if atomic.LoadInt32(&pc.inflight) > 0 {
+				conns = append(conns, pc)
+				continue
+			}
+			if p.opts.idleTimeout > 0 && unused >= p.opts.idleTimeout {
+				idle = append(idle, pc)
+				continue
+			}
+			conns = append(conns, pc)
+			if p.opts.keepalive > 0 && unused >= p.opts.keepalive && atomic.CompareAndSwapInt32(&pc.pinging, 0, 1) {
+				pings = append(pings, pc)
+			}
+		}
+		nc.conns = conns
+		nc.mu.Unlock()
+		for _, pc := range idle {
+			atomic.AddUint64(&p.reaped, 1)
+			pc.stop()
+		}
+		for _, pc := range pings {
+			go pc.ping()
+		}
+	}
+}
+
+// idlest returns the connection with the fewest calls in flight under limit, zero meaning no limit.
+func (nc *nodeConns) idlest(limit int) *poolConn {
+	var idlest *poolConn
+	least := int32(-1)
+	for _, pc := range nc.conns {
+		n := atomic.LoadInt32(&pc.inflight)
+		if limit > 0 && int(n) >= limit {
+			continue
+		}
+		if least < 0 || n < least {
+			idlest, least = pc, n
+		}
+	}
+	return idlest
+}
+
+// remove removes pc from the connections of the node.
+func (nc *nodeConns) remove(pc *poolConn) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	for i, c := range nc.conns {
+		if c == pc {
+			nc.conns = append(nc.conns[:i], nc.conns[i+1:]...)
+			return
+		}
+	}
+}
+
+func (nc *nodeConns) close() {
+	nc.mu.Lock()
+	conns := nc.conns
+	nc.conns = nil
+	nc.gone = true
+	nc.mu.Unlock()
+	for _, pc := range conns {
+		pc.stop()
+	}
+}
+
+// acquire counts a call in flight, the node lock must be held.
+func (pc *poolConn) acquire() *poolConn {
+	atomic.AddInt32(&pc.inflight, 1)
+	return pc
+}
+
+// release counts the call done.
+func (pc *poolConn) release() {
+	atomic.StoreInt64(&pc.lastUsed, time.Now().UnixNano())
+	atomic.AddInt32(&pc.inflight, -1)
+}
+
+func (pc *poolConn) stop() {
+	pooledConns.Delete(pc.client)
+	pc.client.Stop()
+}
+
+// ping sends a heartbeat, the connection is dropped unless the peer answers within the dial timeout.
+// Any reply will do, a server without health service answers with an error.
+func (pc *poolConn) ping() {
+	defer atomic.StoreInt32(&pc.pinging, 0)
+	timeout := pc.pool.opts.dialTimeout
+	if timeout <= 0 {
+		timeout = defaultDialTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	data, err := marshalEnvelope(envelopeV1, &MessageWrapper{})
+	if err != nil {
+		return
+	}
+	var rsp []byte
+	if err = pc.client.CallWith(ctx, HealthCheckMethod, data, &rsp); err != nil && ctx.Err() != nil {
+		log.Warnf("[ARPC] heartbeat of %s timed out, dropping the connection", pc.node.addr)
+		pc.drop()
+	}
+}
+
+// drop removes the connection from its node once it is broken, the next call dials a new one.
+func (pc *poolConn) drop() {
+	if _, ok := pooledConns.LoadAndDelete(pc.client); !ok {
+		return
+	}
+	atomic.AddUint64(&pc.pool.disconnects, 1)
+	pc.node.remove(pc)
+	// arpc would redial it on its own, the pool does with backoff
+	go pc.client.Stop()
+}
+
+// handleDisconnected aborts the streams of the disconnected client and drops it from its pool.
+func handleDisconnected(client *arpc.Client) {
+	abortStreams(client)
+	if v, ok := pooledConns.Load(client); ok {
+		v.(*poolConn).drop()
+	}
+}
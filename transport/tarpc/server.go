@@ -2,19 +2,21 @@ package tarpc
 
 import (
 	"context"
+	"crypto/tls"
 	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
 	"github.com/LiangQinghai/kratos-ext/pkg/host"
 	"github.com/LiangQinghai/kratos-ext/pkg/matcher"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
+	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/lesismal/arpc"
-	"github.com/lesismal/arpc/codec"
-	"github.com/lesismal/arpc/util"
 	"google.golang.org/grpc/metadata"
 	"net"
 	"net/url"
+	"sync"
 	"time"
 )
 
@@ -41,6 +43,16 @@ func Address(address string) ServerOption {
 	}
 }
 
+// Listener with a listener opened beforehand, such as a unix socket or a socket activated by systemd,
+// in place of the network and address.
+func Listener(lis net.Listener) ServerOption {
+	return func(s *Server) {
+		s.lis = lis
+		s.network = lis.Addr().Network()
+		s.address = lis.Addr().String()
+	}
+}
+
 // Endpoint with endpoint
 func Endpoint(endpoint *url.URL) ServerOption {
 	return func(s *Server) {
@@ -48,6 +60,16 @@ func Endpoint(endpoint *url.URL) ServerOption {
 	}
 }
 
+// TLSConfig with the tls config of the listener, the endpoint is advertised as arpcs.
+// A listener given with Listener is wrapped as well.
+// Set ClientAuth to tls.RequireAndVerifyClientCert for mutual tls, the identity of the client
+// is then available from the PeerCertificates of the Transport.
+func TLSConfig(c *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tlsConf = c
+	}
+}
+
 // ErrorEncode with error encode
 func ErrorEncode(ene EncodeErrorFunc) ServerOption {
 	return func(s *Server) {
@@ -70,36 +92,73 @@ func Middleware(m ...middleware.Middleware) ServerOption {
 	}
 }
 
+// Codec with the codec of the request and reply messages, json by default.
+// The clients must use the same codec.
+func Codec(c encoding.Codec) ServerOption {
+	return func(s *Server) {
+		s.codec = c
+	}
+}
+
+// StreamWindow with the number of frames a stream receives before the client waits for acknowledgement
+func StreamWindow(n uint32) ServerOption {
+	return func(s *Server) {
+		s.streamWindow = n
+	}
+}
+
 func NewServer(opts ...ServerOption) *Server {
 	srv := &Server{
-		network:    "tcp",
-		address:    ":9090",
-		middleware: matcher.New(),
-		timeout:    3 * time.Second,
-		ene:        defaultErrorEncoder,
-		rec:        RecoveryHandler(),
+		network:      "tcp",
+		address:      ":9090",
+		middleware:   matcher.New(),
+		timeout:      3 * time.Second,
+		ene:          defaultErrorEncoder,
+		rec:          RecoveryHandler(),
+		streams:      make(map[streamKey]*ServerStream),
+		streamWindow: defaultStreamWindow,
+		codec:        encoding.GetCodec(defaultCodecName),
+		health:       newHealth(),
 	}
 	for _, opt := range opts {
 		opt(srv)
 	}
+	// the listener given with Listener serves tls as the one opened on Start
+	if srv.lis != nil && srv.tlsConf != nil {
+		srv.lis = tls.NewListener(srv.lis, srv.tlsConf)
+	}
 	arpcServer := arpc.NewServer()
 	//recovery
 	arpcServer.Handler.Use(srv.rec)
+	// stream
+	arpcServer.Handler.Handle(serverFrameMethod, srv.handleStreamFrame)
+	arpcServer.Handler.Handle(serverWindowMethod, srv.handleStreamWindow)
+	arpcServer.Handler.Handle(serverCancelMethod, srv.handleStreamCancel)
+	arpcServer.Handler.HandleDisconnected(srv.cancelStreams)
+	// health
+	arpcServer.Handler.Handle(HealthCheckMethod, srv.handleHealthCheck)
 	srv.arpcServer = arpcServer
 	return srv
 }
 
 type Server struct {
-	arpcServer *arpc.Server
-	lis        net.Listener
-	err        error
-	network    string
-	address    string
-	endpoint   *url.URL
-	timeout    time.Duration
-	middleware matcher.Matcher
-	ene        EncodeErrorFunc
-	rec        HandlerFunc
+	arpcServer   *arpc.Server
+	lis          net.Listener
+	err          error
+	network      string
+	address      string
+	endpoint     *url.URL
+	tlsConf      *tls.Config
+	timeout      time.Duration
+	middleware   matcher.Matcher
+	ene          EncodeErrorFunc
+	rec          HandlerFunc
+	streams      map[streamKey]*ServerStream
+	streamsMu    sync.Mutex
+	streamWindow uint32
+	codec        encoding.Codec
+	health       *health
+	drain        drain
 }
 
 func (s *Server) Endpoint() (*url.URL, error) {
@@ -123,39 +182,70 @@ func (s *Server) Start(_ context.Context) error {
 	return nil
 }
 
-func (s *Server) Stop(_ context.Context) error {
+// Stop stops serving, it rejects the new calls and waits for the in-flight calls and streams
+// until ctx is done, then closes the connections. The health service reports every service
+// as not serving meanwhile.
+func (s *Server) Stop(ctx context.Context) error {
 	log.Info("[ARPC] server stopping")
+	s.health.stop()
+	idle := s.drain.start()
+	if s.lis != nil {
+		// stop accepting connections, the open ones carry the in-flight calls
+		_ = s.lis.Close()
+	}
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		log.Warnf("[ARPC] server stop: %v, closing the in-flight calls", ctx.Err())
+	}
+	s.cancelStreams(nil)
 	return s.arpcServer.Stop()
 }
 
+// Handle registers the handler of method m, the service of m is reported as serving.
 func (s *Server) Handle(m string, handler HandlerFunc) *Server {
-	s.arpcServer.Handler.Handle(m, handler)
+	s.health.add(m)
+	s.arpcServer.Handler.Handle(m, s.track(handler))
 	return s
 }
 
+// Middleware returns the handler of the operation wrapped with its middleware,
+// a call whose deadline has passed by then is rejected.
 func (s *Server) Middleware(ctx context.Context, m middleware.Handler) middleware.Handler {
 	if tr, ok := transport.FromServerContext(ctx); ok {
-		return middleware.Chain(s.middleware.Match(tr.Operation())...)(m)
+		h := middleware.Chain(s.middleware.Match(tr.Operation())...)(m)
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, contextError(ctx, err)
+			}
+			return h(ctx, req)
+		}
 	}
 	return func(ctx context.Context, req interface{}) (interface{}, error) {
 		return nil, NoHandlerError
 	}
 }
 
+// Timeout bounds ctx by the server timeout and by the deadline of the caller, whichever comes first.
 func (s *Server) Timeout(ctx context.Context) (context.Context, context.CancelFunc) {
-	var (
-		cancel context.CancelFunc
-	)
+	deadline, ok := callerDeadline(ctx)
 	if s.timeout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, s.timeout)
-	} else {
-		ctx, cancel = context.WithCancel(ctx)
+		if d := time.Now().Add(s.timeout); !ok || d.Before(deadline) {
+			deadline, ok = d, true
+		}
+	}
+	if ok {
+		return context.WithDeadline(ctx, deadline)
 	}
-	return ctx, cancel
+	return context.WithCancel(ctx)
 }
 
 func (s *Server) DecodeData(data []byte, target any) error {
-	return util.BytesToValue(codec.DefaultCodec, data, target)
+	if len(data) == 0 {
+		// an empty message
+		return nil
+	}
+	return s.codec.Unmarshal(data, target)
 }
 
 func (s *Server) DecodeRequest(c *Ctx) (context.Context, []byte, error) {
@@ -165,52 +255,67 @@ func (s *Server) DecodeRequest(c *Ctx) (context.Context, []byte, error) {
 	}
 	var mw MessageWrapper
 	// decode
-	err := util.BytesToValue(codec.DefaultCodec, body, &mw)
+	_, err := unmarshalEnvelope(body, &mw)
 	if err != nil {
 		return c, nil, err
 	}
 	// init transport
-	ctx := s.initTransport(c, mw.Headers)
+	ctx := withCallerDeadline(s.initTransport(c, c.Client.Conn, mw.Headers), mw.Timeout)
 	if mw.Err != nil {
 		return ctx, nil, mw.Err
 	}
+	if err = checkCodec(s.codec, &mw); err != nil {
+		return ctx, nil, err
+	}
 	return ctx, mw.Data, nil
 }
 
 func (s *Server) EncodeResponse(ctx context.Context, resp any, err error) *MessageWrapper {
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = timeout.DeadlineExceededError
+		}
 		mw := s.ene(ctx, err)
 		return mw
 	}
 	if resp == nil {
 		return &MessageWrapper{}
 	}
-	// encode resp
-	bytes := util.ValueToBytes(codec.DefaultCodec, resp)
 	var md metadata.MD
 	if tr, ok := FromArpcTransport(ctx); ok {
 		md = metadata.MD(tr.replyHeader)
 	}
 	// wrap data
-	return &MessageWrapper{
-		Data:    bytes,
-		Headers: md,
+	mw := &MessageWrapper{Headers: md}
+	if err = encodeData(s.codec, mw, resp); err != nil {
+		return s.ene(ctx, err)
 	}
+	return mw
 }
 
+// Write writes the reply, a MessageWrapper is written in the envelope of the request.
 func (s *Server) Write(c *Ctx, data any) {
-	err := c.Write(data)
+	var err error
+	if mw, ok := data.(*MessageWrapper); ok {
+		err = writeMessage(c, mw)
+	} else {
+		err = c.Write(data)
+	}
 	if err != nil {
 		panic(err)
 	}
 }
 
-func (s *Server) initTransport(ctx context.Context, reqHeader map[string][]string) context.Context {
+func (s *Server) initTransport(ctx context.Context, conn net.Conn, reqHeader map[string][]string) context.Context {
 	tr := Transport{
 		endpoint:    s.endpoint.String(),
 		reqHeader:   mapToHeaderCarrier(reqHeader),
 		replyHeader: mapToHeaderCarrier(map[string][]string{}),
 	}
+	if tc, ok := conn.(*tls.Conn); ok {
+		state := tc.ConnectionState()
+		tr.tlsState = &state
+	}
 	ctx = transport.NewServerContext(ctx, &tr)
 	return ctx
 }
@@ -223,14 +328,22 @@ func (s *Server) listenAndEndpoint() error {
 			return err
 		}
 		s.lis = lis
+		if s.tlsConf != nil {
+			s.lis = tls.NewListener(lis, s.tlsConf)
+		}
 	}
 	if s.endpoint == nil {
+		scheme := endpoint.Scheme("arpc", s.tlsConf != nil)
+		if path, ok := host.Unix(s.lis); ok {
+			s.endpoint = endpoint.NewUnixEndpoint(scheme, path)
+			return s.err
+		}
 		addr, err := host.ExtractFromLis(s.address, s.lis)
 		if err != nil {
 			s.err = err
 			return err
 		}
-		s.endpoint = endpoint.NewEndpoint(endpoint.Scheme("arpc", false), addr)
+		s.endpoint = endpoint.NewEndpoint(scheme, addr)
 	}
 	return s.err
 }
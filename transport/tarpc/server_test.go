@@ -3,13 +3,23 @@ package tarpc
 import (
 	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"fmt"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/proto"
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/middleware/metadata"
 	"github.com/go-kratos/kratos/v2/transport"
 	grpc2 "github.com/go-kratos/kratos/v2/transport/grpc"
 	"github.com/lesismal/arpc/log"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"io"
+	"net"
+	"net/url"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync/atomic"
@@ -329,3 +339,622 @@ func bArpc(t *testing.T) {
 		t.Errorf("expected nil got %v", srv.Stop(ctx))
 	}
 }
+
+func TestStreamWindow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	st := newStream(ctx, cancel, nil, 1, 2)
+	for i := 0; i < 2; i++ {
+		if err := st.push(&MessageWrapper{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the peer ignores the window
+	if err := st.push(&MessageWrapper{}); err != StreamWindowError {
+		t.Errorf("expected %v got %v", StreamWindowError, err)
+	}
+	if err := st.push(&MessageWrapper{End: true}); err != nil {
+		t.Errorf("the end frame should not be limited by the window, got %v", err)
+	}
+	if err := st.push(&MessageWrapper{}); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+}
+
+func TestServerCodec(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:19093"), Codec(encoding.GetCodec("proto")))
+	srv.Handle("/echo", helloWorldEcho(srv))
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	client, err := Dail(ctx, WithEndpoint(srv.endpoint.Host), WithCodec(encoding.GetCodec("proto")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rsp TestReply
+	if err = client.Call(ctx, "/echo", &TestReq{Message: "hello"}, &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Message != "Hello server.——From server" {
+		t.Errorf("unexpected reply %s", rsp.Message)
+	}
+
+	client, err = Dail(ctx, WithEndpoint(srv.endpoint.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Call(ctx, "/echo", &TestReq{Message: "hello"}, &rsp)
+	if errors.Reason(err) != "CODEC_MISMATCH" {
+		t.Errorf("expected %s got %v", "CODEC_MISMATCH", err)
+	}
+}
+
+func TestCheckCodec(t *testing.T) {
+	json, proto := encoding.GetCodec("json"), encoding.GetCodec("proto")
+	mw := &MessageWrapper{}
+	if err := encodeData(proto, mw, &TestReq{Message: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if mw.Codec != "proto" {
+		t.Errorf("expected %s got %s", "proto", mw.Codec)
+	}
+	var in TestReq
+	if err := decodeData(proto, mw, &in); err != nil || in.Message != "hello" {
+		t.Errorf("unexpected decode %v %v", in.Message, err)
+	}
+	if err := decodeData(json, mw, &in); errors.Reason(err) != "CODEC_MISMATCH" {
+		t.Errorf("expected %s got %v", "CODEC_MISMATCH", err)
+	}
+	// messages without a codec name are json
+	if err := checkCodec(json, &MessageWrapper{Data: []byte(`{}`)}); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+	if err := checkCodec(proto, &MessageWrapper{}); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	mw := &MessageWrapper{
+		Headers: map[string][]string{"X-Md-Global-A": {"a", "b"}},
+		Data:    []byte("hello"),
+		Err:     errors.Conflict("CONFLICT", "conflict").WithMetadata(map[string]string{"k": "v"}),
+		Stream:  1,
+		Window:  2,
+		Codec:   "proto",
+		Timeout: time.Second,
+	}
+	for _, version := range []byte{envelopeJSON, envelopeV1} {
+		data, err := marshalEnvelope(version, mw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out MessageWrapper
+		v, err := unmarshalEnvelope(data, &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != version {
+			t.Errorf("expected version %d got %d", version, v)
+		}
+		if string(out.Data) != "hello" || out.Stream != 1 || out.Window != 2 || out.Codec != "proto" || out.Timeout != time.Second ||
+			strings.Join(out.Headers["X-Md-Global-A"], ",") != "a,b" {
+			t.Errorf("unexpected message %+v", out)
+		}
+		if !errors.IsConflict(out.Err) || out.Err.Reason != "CONFLICT" || out.Err.Metadata["k"] != "v" {
+			t.Errorf("unexpected status %v", out.Err)
+		}
+	}
+	if _, err := unmarshalEnvelope([]byte{2}, &MessageWrapper{}); errors.Reason(err) != "ENVELOPE_VERSION" {
+		t.Errorf("expected %s got %v", "ENVELOPE_VERSION", err)
+	}
+}
+
+func TestServerEnvelope(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:19094"))
+	srv.Handle("/echo", helloWorldEcho(srv))
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	for _, version := range []byte{envelopeJSON, envelopeV1} {
+		client, err := Dail(ctx, WithEndpoint(srv.endpoint.Host))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.envelope = uint32(version)
+		var rsp TestReply
+		if err = client.Call(ctx, "/echo", &TestReq{Message: "hello"}, &rsp); err != nil {
+			t.Fatal(err)
+		}
+		if rsp.Message != "Hello server.——From server" {
+			t.Errorf("unexpected reply %s", rsp.Message)
+		}
+		if client.envelope != uint32(version) {
+			t.Errorf("expected envelope %d got %d", version, client.envelope)
+		}
+	}
+}
+
+func benchmarkEnvelope(b *testing.B, version byte) {
+	data := make([]byte, 1024)
+	_, _ = rand.Read(data)
+	mw := &MessageWrapper{
+		Headers: map[string][]string{"X-Md-Global-A": {"a"}, "X-Md-Global-B": {"b"}},
+		Data:    data,
+		Codec:   "proto",
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		buf, err := marshalEnvelope(version, mw)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var out MessageWrapper
+		if _, err = unmarshalEnvelope(buf, &out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEnvelopeJSON(b *testing.B) {
+	benchmarkEnvelope(b, envelopeJSON)
+}
+
+func BenchmarkEnvelopeProto(b *testing.B) {
+	benchmarkEnvelope(b, envelopeV1)
+}
+
+func TestServerDeadline(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:19095"))
+	deadlines := make(chan time.Duration, 1)
+	srv.Handle("/slow", func(c *Ctx) {
+		ctx, bytes, err := srv.DecodeRequest(c)
+		if err != nil {
+			panic(err)
+		}
+		ctx, cancel := srv.Timeout(ctx)
+		defer cancel()
+		var in TestReq
+		if err = srv.DecodeData(bytes, &in); err != nil {
+			panic(err)
+		}
+		SetOperation(ctx, "slow")
+		h := srv.Middleware(ctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			deadline, _ := ctx.Deadline()
+			deadlines <- time.Until(deadline)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		reply, err := h(ctx, &in)
+		srv.Write(c, srv.EncodeResponse(ctx, reply, err))
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	client, err := Dail(ctx, WithEndpoint(srv.endpoint.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	err = client.Call(callCtx, "/slow", &TestReq{}, &TestReply{})
+	if errors.Reason(err) != "DEADLINE_EXCEEDED" {
+		t.Errorf("expected %s got %v", "DEADLINE_EXCEEDED", err)
+	}
+	if left := <-deadlines; left <= 0 || left > 200*time.Millisecond {
+		t.Errorf("expected the caller deadline on the server got %v", left)
+	}
+
+	err = client.Call(callCtx, "/slow", &TestReq{}, &TestReply{})
+	if errors.Reason(err) != "DEADLINE_EXCEEDED" {
+		t.Errorf("expected %s got %v", "DEADLINE_EXCEEDED", err)
+	}
+	select {
+	case <-deadlines:
+		t.Error("expected an expired call not to be sent")
+	default:
+	}
+
+	callCtx, cancel = context.WithCancel(ctx)
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	err = client.Call(callCtx, "/slow", &TestReq{}, &TestReply{})
+	if errors.Reason(err) != "CANCELED" || time.Since(start) > time.Second {
+		t.Errorf("expected %s got %v after %v", "CANCELED", err, time.Since(start))
+	}
+	<-deadlines
+}
+
+func TestServerTimeout(t *testing.T) {
+	srv := &Server{timeout: 3 * time.Second}
+	ctx, cancel := srv.Timeout(withCallerDeadline(context.Background(), time.Second))
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Second {
+		t.Errorf("expected the caller deadline got %v", deadline)
+	}
+	ctx, cancel = srv.Timeout(withCallerDeadline(context.Background(), time.Minute))
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > srv.timeout {
+		t.Errorf("expected the server deadline got %v", deadline)
+	}
+	srv.timeout = 0
+	ctx, cancel = srv.Timeout(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no deadline")
+	}
+}
+
+func TestServerDrain(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:19096"))
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	srv.Handle("/helloworld.Greeter/Slow", func(c *Ctx) {
+		ctx, _, err := srv.DecodeRequest(c)
+		if err != nil {
+			panic(err)
+		}
+		started <- struct{}{}
+		<-release
+		srv.Write(c, srv.EncodeResponse(ctx, &TestReply{Message: "done"}, nil))
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+
+	client, err := Dail(ctx, WithEndpoint(srv.endpoint.Host), WithTimeout(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for service, want := range map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":                   healthpb.HealthCheckResponse_SERVING,
+		"helloworld.Greeter": healthpb.HealthCheckResponse_SERVING,
+	} {
+		if status, err := client.HealthCheck(ctx, service); err != nil || status != want {
+			t.Errorf("%q: expected %v got %v %v", service, want, status, err)
+		}
+	}
+	if _, err = client.HealthCheck(ctx, "unknown"); errors.Reason(err) != "SERVICE_UNKNOWN" {
+		t.Errorf("expected %s got %v", "SERVICE_UNKNOWN", err)
+	}
+
+	replied := make(chan error, 1)
+	go func() {
+		var reply TestReply
+		err := client.Call(ctx, "/helloworld.Greeter/Slow", &TestReq{}, &reply)
+		if err == nil && reply.Message != "done" {
+			err = fmt.Errorf("unexpected reply %s", reply.Message)
+		}
+		replied <- err
+	}()
+	<-started
+	stopped := make(chan error, 1)
+	stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	go func() {
+		stopped <- srv.Stop(stopCtx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	if status, err := client.HealthCheck(ctx, "helloworld.Greeter"); err != nil || status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected %v got %v %v", healthpb.HealthCheckResponse_NOT_SERVING, status, err)
+	}
+	if err = client.Call(ctx, "/helloworld.Greeter/Slow", &TestReq{}, &TestReply{}); errors.Reason(err) != "SERVER_DRAINING" {
+		t.Errorf("expected %s got %v", "SERVER_DRAINING", err)
+	}
+	select {
+	case <-stopped:
+		t.Fatal("expected stop to wait for the in-flight call")
+	default:
+	}
+	close(release)
+	if err = <-replied; err != nil {
+		t.Errorf("expected the in-flight call to complete got %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("expected stop to return once the in-flight call completed")
+	}
+}
+
+func TestDrain(t *testing.T) {
+	var d drain
+	if !d.acquire() {
+		t.Fatal("expected a call before draining")
+	}
+	idle := d.start()
+	if d.acquire() {
+		t.Error("expected the calls to be rejected while draining")
+	}
+	d.hold()
+	d.release()
+	select {
+	case <-idle:
+		t.Fatal("expected the held stream to keep draining")
+	default:
+	}
+	d.release()
+	select {
+	case <-idle:
+	default:
+		t.Error("expected draining to be done")
+	}
+	if d.start() != idle {
+		t.Error("expected draining to start once")
+	}
+}
+
+func TestHealth(t *testing.T) {
+	h := newHealth()
+	h.add("/helloworld.Greeter/SayHello")
+	h.add("nothing")
+	h.set("other", healthpb.HealthCheckResponse_NOT_SERVING)
+	for service, want := range map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":                   healthpb.HealthCheckResponse_SERVING,
+		"helloworld.Greeter": healthpb.HealthCheckResponse_SERVING,
+		"other":              healthpb.HealthCheckResponse_NOT_SERVING,
+	} {
+		if reply, err := h.check(service); err != nil || reply.Status != want {
+			t.Errorf("%q: expected %v got %v %v", service, want, reply, err)
+		}
+	}
+	if _, err := h.check("nothing"); errors.Reason(err) != "SERVICE_UNKNOWN" {
+		t.Errorf("expected %s got %v", "SERVICE_UNKNOWN", err)
+	}
+	h.stop()
+	h.set("", healthpb.HealthCheckResponse_SERVING)
+	h.add("/late.Service/Call")
+	for _, service := range []string{"", "helloworld.Greeter", "late.Service"} {
+		if reply, _ := h.check(service); reply.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("%q: expected %v got %v", service, healthpb.HealthCheckResponse_NOT_SERVING, reply)
+		}
+	}
+}
+
+// testMutualTLS returns the tls configs of a server and of a client authenticating each other.
+func testMutualTLS(t *testing.T) (*tls.Config, *tls.Config) {
+	serverCert, serverPool := testutil.Certificate(t, "127.0.0.1")
+	clientCert, clientPool := testutil.Certificate(t, "client")
+	return &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      serverPool,
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	ctx := context.Background()
+	serverConf, clientConf := testMutualTLS(t)
+	srv := NewServer(Address("127.0.0.1:19097"), TLSConfig(serverConf))
+	srv.Handle("/helloworld.Greeter/Peer", func(c *Ctx) {
+		ctx, _, err := srv.DecodeRequest(c)
+		if err != nil {
+			panic(err)
+		}
+		var reply TestReply
+		if tr, ok := FromArpcTransport(ctx); ok && len(tr.PeerCertificates()) > 0 {
+			reply.Message = tr.PeerCertificates()[0].Subject.CommonName
+		}
+		srv.Write(c, srv.EncodeResponse(ctx, &reply, nil))
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+	if srv.endpoint.Scheme != "arpcs" {
+		t.Errorf("expected %s got %s", "arpcs", srv.endpoint.Scheme)
+	}
+
+	client, err := Dail(ctx, WithEndpoint(srv.endpoint.Host), WithTLSConfig(clientConf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply TestReply
+	if err = client.Call(ctx, "/helloworld.Greeter/Peer", &TestReq{}, &reply); err != nil || reply.Message != "client" {
+		t.Errorf("expected the identity of the client got %s %v", reply.Message, err)
+	}
+
+	plain, err := Dail(ctx, WithEndpoint(srv.endpoint.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	if err = plain.Call(ctx, "/helloworld.Greeter/Peer", &TestReq{}, &reply); err == nil {
+		t.Error("expected a plain client to fail")
+	}
+}
+
+func TestServerListenerTLS(t *testing.T) {
+	ctx := context.Background()
+	serverConf, clientConf := testMutualTLS(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(Listener(lis), TLSConfig(serverConf))
+	srv.Handle("/helloworld.Greeter/SayHello", func(c *Ctx) {
+		ctx, _, err := srv.DecodeRequest(c)
+		if err != nil {
+			panic(err)
+		}
+		srv.Write(c, srv.EncodeResponse(ctx, &TestReply{Message: "hello"}, nil))
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+	if srv.endpoint.Scheme != "arpcs" {
+		t.Errorf("expected %s got %s", "arpcs", srv.endpoint.Scheme)
+	}
+
+	client, err := Dail(ctx, WithEndpoint(srv.endpoint.Host), WithTLSConfig(clientConf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply TestReply
+	if err = client.Call(ctx, "/helloworld.Greeter/SayHello", &TestReq{}, &reply); err != nil || reply.Message != "hello" {
+		t.Errorf("expected a tls call to succeed got %s %v", reply.Message, err)
+	}
+
+	plain, err := Dail(ctx, WithEndpoint(srv.endpoint.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	if err = plain.Call(ctx, "/helloworld.Greeter/SayHello", &TestReq{}, &reply); err == nil {
+		t.Error("expected a plain client to fail")
+	}
+}
+
+func TestTransportPeer(t *testing.T) {
+	serverConf, clientConf := testMutualTLS(t)
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", serverConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err == nil {
+			// the handshake is done along with the client one
+			err = conn.(*tls.Conn).Handshake()
+		}
+		if err != nil {
+			t.Error(err)
+		}
+		accepted <- conn
+	}()
+
+	p := newConnPool(poolOptions{tlsConf: clientConf})
+	defer p.close()
+	conn, err := p.dial(lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	serverConn := <-accepted
+	if serverConn == nil {
+		t.FailNow()
+	}
+	defer serverConn.Close()
+
+	srv := &Server{endpoint: &url.URL{Scheme: "arpcs", Host: lis.Addr().String()}}
+	tr, _ := FromArpcTransport(srv.initTransport(context.Background(), serverConn, nil))
+	if certs := tr.PeerCertificates(); len(certs) != 1 || certs[0].Subject.CommonName != "client" {
+		t.Errorf("expected the certificate of the client got %v", certs)
+	}
+	if tr.ConnectionState() == nil || !tr.ConnectionState().HandshakeComplete {
+		t.Error("expected the tls state of the connection")
+	}
+	tr, _ = FromArpcTransport(srv.initTransport(context.Background(), nil, nil))
+	if tr.ConnectionState() != nil || tr.PeerCertificates() != nil {
+		t.Error("expected no tls state without tls")
+	}
+}
+
+func TestListenerEndpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	srv := &Server{}
+	Listener(lis)(srv)
+	if err = srv.listenAndEndpoint(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "arpc+unix://" + path; srv.endpoint.String() != want {
+		t.Errorf("expected %s got %s", want, srv.endpoint)
+	}
+
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	srv = &Server{address: ":9090"}
+	Listener(tcp)(srv)
+	if err = srv.listenAndEndpoint(); err != nil {
+		t.Fatal(err)
+	}
+	if srv.endpoint.Host != tcp.Addr().String() {
+		t.Errorf("expected %s got %s", tcp.Addr(), srv.endpoint.Host)
+	}
+}
+
+func TestServerUnix(t *testing.T) {
+	ctx := context.Background()
+	lis, err := net.Listen("unix", filepath.Join(t.TempDir(), "app.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(Listener(lis))
+	srv.Handle("/helloworld.Greeter/SayHello", helloWorldEcho(srv))
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+	ept, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := Dail(ctx, WithEndpoint(ept.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var reply TestReply
+	if err = client.Call(ctx, "/helloworld.Greeter/SayHello", &TestReq{Message: "unix"}, &reply); err != nil {
+		t.Fatal(err)
+	}
+}
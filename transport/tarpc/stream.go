@@ -0,0 +1,207 @@
+package tarpc
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/lesismal/arpc"
+	"io"
+	"sync"
+	"time"
+)
+
+// the streams are multiplexed over the arpc connections with notifications,
+// every frame carries the stream id.
+const (
+	// clientFrameMethod is notified by the server to push stream frames to the client.
+	clientFrameMethod = "/_tarpc/client/stream/frame"
+	// clientWindowMethod is notified by the server to grow the send window of the client.
+	clientWindowMethod = "/_tarpc/client/stream/window"
+	// serverFrameMethod is notified by the client to push stream frames to the server.
+	serverFrameMethod = "/_tarpc/server/stream/frame"
+	// serverWindowMethod is notified by the client to grow the send window of the server.
+	serverWindowMethod = "/_tarpc/server/stream/window"
+	// serverCancelMethod is notified by the client to cancel a stream on the server.
+	serverCancelMethod = "/_tarpc/server/stream/cancel"
+
+	// defaultStreamWindow is the default number of frames a stream receives without acknowledging.
+	defaultStreamWindow uint32 = 64
+)
+
+var (
+	StreamIDError     = errors.New(400, "STREAM_ID", "missing stream id")
+	StreamClosedError = errors.New(503, "STREAM_CLOSED", "arpc connection closed")
+	StreamWindowError = errors.New(429, "STREAM_WINDOW", "stream window exceeded")
+)
+
+// stream is the state shared by both sides of a stream, the frames of the peer are
+// queued until received and the frames sent are limited by the window of the peer.
+type stream struct {
+	ctx          context.Context
+	cancel       context.CancelFunc
+	client       *arpc.Client
+	id           uint64
+	timeout      time.Duration
+	frameMethod  string
+	windowMethod string
+	codec        encoding.Codec
+	// envelope is the envelope version of the frames, the one the stream was opened with
+	envelope byte
+	// window is the receive window advertised to the peer
+	window uint32
+
+	mu       sync.Mutex
+	frames   []*MessageWrapper
+	consumed uint32
+	credit   uint32
+	recvDone bool
+	sendDone bool
+	ready    chan struct{}
+	credited chan struct{}
+	sendMu   sync.Mutex
+}
+
+func newStream(ctx context.Context, cancel context.CancelFunc, client *arpc.Client, id uint64, window uint32) *stream {
+	if window == 0 {
+		window = defaultStreamWindow
+	}
+	return &stream{
+		ctx:      ctx,
+		cancel:   cancel,
+		client:   client,
+		id:       id,
+		window:   window,
+		ready:    make(chan struct{}, 1),
+		credited: make(chan struct{}, 1),
+	}
+}
+
+// push queues a frame of the peer, it returns io.EOF if the peer has ended the stream
+// and StreamWindowError if the peer sends more frames than the window allows, which
+// must reset the stream.
+func (s *stream) push(mw *MessageWrapper) error {
+	s.mu.Lock()
+	if s.recvDone {
+		s.mu.Unlock()
+		return io.EOF
+	}
+	// the end frame is not limited by the window
+	if !mw.End && uint32(len(s.frames)) >= s.window {
+		s.mu.Unlock()
+		return StreamWindowError
+	}
+	s.frames = append(s.frames, mw)
+	s.recvDone = mw.End
+	s.mu.Unlock()
+	signal(s.ready)
+	return nil
+}
+
+// grow grows the send window with n frames.
+func (s *stream) grow(n uint32) {
+	s.mu.Lock()
+	s.credit += n
+	s.mu.Unlock()
+	signal(s.credited)
+}
+
+// recv blocks until a frame of the peer is received, the consumed frames are
+// acknowledged to the peer once half of the window is used.
+func (s *stream) recv() (*MessageWrapper, error) {
+	for {
+		s.mu.Lock()
+		if len(s.frames) > 0 {
+			mw := s.frames[0]
+			s.frames[0] = nil
+			s.frames = s.frames[1:]
+			var ack uint32
+			if !mw.End {
+				s.consumed++
+				if !s.recvDone && s.consumed >= (s.window+1)/2 {
+					ack, s.consumed = s.consumed, 0
+				}
+			}
+			s.mu.Unlock()
+			if ack > 0 {
+				if err := s.notify(s.windowMethod, &MessageWrapper{Stream: s.id, Window: ack}); err != nil {
+					log.Errorf("[ARPC] stream %d send window err: %v", s.id, err)
+				}
+			}
+			return mw, nil
+		}
+		done := s.recvDone
+		s.mu.Unlock()
+		if done {
+			return nil, io.EOF
+		}
+		select {
+		case <-s.ready:
+		case <-s.ctx.Done():
+			return nil, s.ctx.Err()
+		}
+	}
+}
+
+// send sends a frame once the window of the peer allows.
+func (s *stream) send(mw *MessageWrapper) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	for {
+		s.mu.Lock()
+		if s.sendDone {
+			s.mu.Unlock()
+			return io.EOF
+		}
+		if s.credit > 0 {
+			s.credit--
+			s.mu.Unlock()
+			break
+		}
+		s.mu.Unlock()
+		select {
+		case <-s.credited:
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+	}
+	mw.Stream = s.id
+	data, err := marshalEnvelope(s.envelope, mw)
+	if err != nil {
+		return err
+	}
+	return s.client.NotifyWith(s.ctx, s.frameMethod, data)
+}
+
+// closeSend sends the end frame, which is not limited by the window.
+func (s *stream) closeSend(mw *MessageWrapper) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	if s.sendDone {
+		s.mu.Unlock()
+		return nil
+	}
+	s.sendDone = true
+	s.mu.Unlock()
+	mw.Stream = s.id
+	mw.End = true
+	return s.notify(s.frameMethod, mw)
+}
+
+// notify sends mw to method of the peer in the envelope of the stream.
+func (s *stream) notify(method string, mw *MessageWrapper) error {
+	data, err := marshalEnvelope(s.envelope, mw)
+	if err != nil {
+		return err
+	}
+	return s.client.Notify(method, data, s.timeout)
+}
+
+// signal wakes up the waiter of ch without blocking.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
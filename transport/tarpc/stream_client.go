@@ -0,0 +1,280 @@
+package tarpc
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/lesismal/arpc"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// clientStreams holds the opened client streams by id.
+var (
+	clientStreams  sync.Map
+	clientStreamID uint64
+)
+
+// newClientHandler returns the handler of the pooled connections, it serves the stream
+// frames of the servers and drops the disconnected connections.
+func newClientHandler() arpc.Handler {
+	h := arpc.NewHandler()
+	h.Handle(clientFrameMethod, handleStreamFrame)
+	h.Handle(clientWindowMethod, handleStreamWindow)
+	h.HandleDisconnected(handleDisconnected)
+	return h
+}
+
+// ClientStream is the client side of a stream.
+type ClientStream struct {
+	*stream
+	tr       *Transport
+	err      error
+	done     chan struct{}
+	doneOnce sync.Once
+	// conn is released and report tells the selector how the stream went once it is finished
+	conn   *poolConn
+	report selector.DoneFunc
+}
+
+// NewStream opens a stream of method on an arpc connection,
+// req is sent as the first message unless it is nil.
+func (c *Client) NewStream(ctx context.Context, method string, req any) (*ClientStream, error) {
+	tr := &Transport{
+		operation:   method,
+		reqHeader:   headerCarrier{},
+		replyHeader: headerCarrier{},
+		nodeFilters: c.opts.filters,
+	}
+	ctx = transport.NewClientContext(ctx, tr)
+
+	var h middleware.Handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+		pc, report, err := c.pick(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		st := newStream(ctx, cancel, pc.client, atomic.AddUint64(&clientStreamID, 1), c.opts.streamWindow)
+		st.timeout = c.opts.timeout
+		st.frameMethod = serverFrameMethod
+		st.windowMethod = serverWindowMethod
+		st.codec = c.opts.codec
+		stream := &ClientStream{
+			stream: st,
+			tr:     tr,
+			done:   make(chan struct{}),
+			conn:   pc,
+			report: report,
+		}
+		// frames may arrive before the ack, register the stream first
+		clientStreams.Store(stream.id, stream)
+		reqMsg, err := c.newMessage(ctx, req)
+		if err != nil {
+			stream.abort(err, false)
+			return nil, err
+		}
+		reqMsg.Stream = stream.id
+		reqMsg.Window = stream.window
+		// the stream is bounded by the caller deadline, the ack by the client timeout as well
+		var timeout time.Duration
+		if reqMsg.Timeout, err = callTimeout(ctx, 0); err == nil {
+			timeout, err = callTimeout(ctx, c.opts.timeout)
+		}
+		if err != nil {
+			stream.abort(err, false)
+			return nil, err
+		}
+		var ack MessageWrapper
+		stream.envelope, err = c.invoke(ctx, pc.client, method, timeout, reqMsg, &ack)
+		if err == nil && ack.Err != nil {
+			err = ack.Err
+		}
+		if err != nil {
+			stream.abort(err, false)
+			return nil, err
+		}
+		for k, v := range ack.Headers {
+			tr.replyHeader[strings.ToLower(k)] = v
+		}
+		stream.grow(ack.Window)
+		go stream.watch()
+		return stream, nil
+	}
+	if len(c.opts.middleware) > 0 {
+		h = middleware.Chain(c.opts.middleware...)(h)
+	}
+	var p selector.Peer
+	ctx = selector.NewPeerContext(ctx, &p)
+	stream, err := h(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return stream.(*ClientStream), nil
+}
+
+// Context returns the stream context, the reply header is available
+// from its client transport.
+func (s *ClientStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg sends a message to the server, it blocks while the window of the server is full.
+// It returns io.EOF once the server has finished the stream, the status is returned by RecvMsg.
+func (s *ClientStream) SendMsg(m any) error {
+	if err := s.Err(); err != nil && err != io.EOF {
+		return err
+	}
+	s.mu.Lock()
+	done := s.recvDone
+	s.mu.Unlock()
+	if done {
+		return io.EOF
+	}
+	mw := &MessageWrapper{}
+	if err := encodeData(s.codec, mw, m); err != nil {
+		return err
+	}
+	err := s.send(mw)
+	if err != nil && s.ctx.Err() != nil {
+		return s.Err()
+	}
+	return err
+}
+
+// CloseSend closes the send side of the stream, the server receives io.EOF.
+func (s *ClientStream) CloseSend() error {
+	return s.closeSend(&MessageWrapper{})
+}
+
+// RecvMsg blocks until it receives a message into m or the stream is done,
+// it returns io.EOF when the server finishes the stream successfully.
+func (s *ClientStream) RecvMsg(m any) error {
+	if err := s.Err(); err != nil {
+		return err
+	}
+	mw, err := s.recv()
+	if err != nil {
+		s.abort(err, true)
+		return s.Err()
+	}
+	for k, v := range mw.Headers {
+		s.tr.replyHeader[strings.ToLower(k)] = v
+	}
+	if mw.End {
+		err = io.EOF
+		if mw.Err != nil {
+			err = mw.Err
+		}
+		s.abort(err, false)
+		return err
+	}
+	return decodeData(s.codec, mw, m)
+}
+
+// Err returns the error the stream finished with.
+func (s *ClientStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// watch propagates the context cancellation to the server.
+func (s *ClientStream) watch() {
+	select {
+	case <-s.ctx.Done():
+		s.abort(s.ctx.Err(), true)
+	case <-s.done:
+	}
+}
+
+// abort finishes the stream with err, the server is told to cancel
+// the stream if notify is true and it is still running.
+func (s *ClientStream) abort(err error, notify bool) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	notify = notify && !s.recvDone
+	s.recvDone = true
+	s.sendDone = true
+	s.mu.Unlock()
+	s.finish(err)
+	s.cancel()
+	if notify {
+		if e := s.notify(serverCancelMethod, &MessageWrapper{Stream: s.id}); e != nil {
+			log.Errorf("[ARPC] stream %d send cancel err: %v", s.id, e)
+		}
+	}
+}
+
+// finish releases the stream once the server has finished it with err.
+func (s *ClientStream) finish(err error) {
+	clientStreams.Delete(s.id)
+	s.doneOnce.Do(func() {
+		close(s.done)
+		if s.conn != nil {
+			s.conn.release()
+		}
+		if s.report != nil {
+			s.report(s.ctx, selector.DoneInfo{Err: err})
+		}
+	})
+}
+
+// clientStreamOf decodes a stream frame of the server and finds its stream.
+func clientStreamOf(c *Ctx) (*ClientStream, *MessageWrapper, bool) {
+	var mw MessageWrapper
+	if _, err := unmarshalEnvelope(c.Body(), &mw); err != nil {
+		log.Errorf("[ARPC] decode stream frame err: %v", err)
+		return nil, nil, false
+	}
+	if v, ok := clientStreams.Load(mw.Stream); ok {
+		if stream := v.(*ClientStream); stream.client == c.Client {
+			return stream, &mw, true
+		}
+	}
+	return nil, nil, false
+}
+
+// handleStreamFrame queues the frames pushed by the server, the stream is reset
+// when the server overflows its window.
+func handleStreamFrame(c *Ctx) {
+	stream, mw, ok := clientStreamOf(c)
+	if !ok {
+		return
+	}
+	switch err := stream.push(mw); err {
+	case nil:
+		if mw.End {
+			if mw.Err != nil {
+				err = mw.Err
+			}
+			stream.finish(err)
+		}
+	case StreamWindowError:
+		log.Errorf("[ARPC] stream %d reset: %v", stream.id, err)
+		stream.abort(err, true)
+	}
+}
+
+// handleStreamWindow grows the send window on the server's acknowledgement.
+func handleStreamWindow(c *Ctx) {
+	if stream, mw, ok := clientStreamOf(c); ok {
+		stream.grow(mw.Window)
+	}
+}
+
+// abortStreams aborts the client streams of the closed connection.
+func abortStreams(client *arpc.Client) {
+	clientStreams.Range(func(_, v any) bool {
+		if stream := v.(*ClientStream); stream.client == client {
+			stream.abort(StreamClosedError, false)
+		}
+		return true
+	})
+}
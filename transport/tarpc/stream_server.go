@@ -0,0 +1,222 @@
+package tarpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/lesismal/arpc"
+	"google.golang.org/grpc/metadata"
+	"io"
+)
+
+type streamKey struct {
+	client *arpc.Client
+	id     uint64
+}
+
+// ServerStream is the server side of a stream.
+type ServerStream struct {
+	*stream
+	srv        *Server
+	headerSent bool
+}
+
+// Context returns the stream context, it is canceled when the client
+// cancels the stream, the connection is closed or the server stops.
+func (s *ServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg sends a message to the client, it blocks while the window of the client is full.
+func (s *ServerStream) SendMsg(m any) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	mw := &MessageWrapper{}
+	if err := encodeData(s.codec, mw, m); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if !s.headerSent {
+		s.headerSent = true
+		mw.Headers = s.replyHeader()
+	}
+	s.mu.Unlock()
+	return s.send(mw)
+}
+
+// RecvMsg blocks until it receives a message into m,
+// it returns io.EOF when the client has closed its send side.
+func (s *ServerStream) RecvMsg(m any) error {
+	mw, err := s.recv()
+	if err != nil {
+		return err
+	}
+	if mw.End {
+		return io.EOF
+	}
+	return decodeData(s.codec, mw, m)
+}
+
+// finish sends the end frame with the status of the handler.
+func (s *ServerStream) finish(err error) {
+	defer s.cancel()
+	if s.ctx.Err() != nil {
+		// canceled by the client or the connection is gone
+		return
+	}
+	var mw *MessageWrapper
+	if err != nil {
+		mw = s.srv.ene(s.ctx, err)
+	} else {
+		mw = &MessageWrapper{Headers: s.replyHeader()}
+	}
+	if e := s.closeSend(mw); e != nil {
+		log.Errorf("[ARPC] stream %d send end frame err: %v", s.id, e)
+	}
+}
+
+// reset cancels the handler and ends the stream with err, as the client has broken the stream.
+func (s *ServerStream) reset(err error) {
+	// the handler may be waiting for the window in SendMsg, which holds the send lock
+	s.cancel()
+	if e := s.closeSend(s.srv.ene(s.ctx, err)); e != nil {
+		log.Errorf("[ARPC] stream %d send end frame err: %v", s.id, e)
+	}
+}
+
+func (s *ServerStream) replyHeader() metadata.MD {
+	if tr, ok := FromArpcTransport(s.ctx); ok {
+		return metadata.MD(tr.replyHeader)
+	}
+	return nil
+}
+
+// DecodeStream decodes the stream open request, returns the server stream
+// and the data of the first message.
+func (s *Server) DecodeStream(c *Ctx) (*ServerStream, []byte, error) {
+	var mw MessageWrapper
+	version, err := unmarshalEnvelope(c.Body(), &mw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mw.Stream == 0 {
+		return nil, nil, StreamIDError
+	}
+	if mw.Err != nil {
+		return nil, nil, mw.Err
+	}
+	if err := checkCodec(s.codec, &mw); err != nil {
+		return nil, nil, err
+	}
+	// the arpc context is released when the handler returns,
+	// so the stream lives in a context of its own, bounded by the caller deadline.
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if mw.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), mw.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	st := newStream(s.initTransport(ctx, c.Client.Conn, mw.Headers), cancel, c.Client, mw.Stream, s.streamWindow)
+	st.timeout = s.timeout
+	st.frameMethod = clientFrameMethod
+	st.windowMethod = clientWindowMethod
+	st.codec = s.codec
+	st.envelope = version
+	st.credit = mw.Window
+	if st.credit == 0 {
+		st.credit = defaultStreamWindow
+	}
+	return &ServerStream{stream: st, srv: s}, mw.Data, nil
+}
+
+// ServeStream acknowledges the stream open request and runs handler in a new goroutine,
+// the end frame carries the returned error to the client.
+func (s *Server) ServeStream(c *Ctx, stream *ServerStream, handler func(ctx context.Context) error) {
+	s.addStream(stream)
+	if err := writeMessage(c, &MessageWrapper{Stream: stream.id, Window: stream.window}); err != nil {
+		s.removeStream(stream)
+		stream.cancel()
+		panic(err)
+	}
+	// Stop waits for the stream as for the call that opened it
+	s.drain.hold()
+	go func() {
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("[ARPC] stream %d panic recovery from %v", stream.id, r)
+				err = errors.InternalServer("PANIC", fmt.Sprintf("%v", r))
+			}
+			s.removeStream(stream)
+			stream.finish(err)
+			s.drain.release()
+		}()
+		err = handler(stream.ctx)
+	}()
+}
+
+func (s *Server) addStream(stream *ServerStream) {
+	s.streamsMu.Lock()
+	s.streams[streamKey{client: stream.client, id: stream.id}] = stream
+	s.streamsMu.Unlock()
+}
+
+func (s *Server) removeStream(stream *ServerStream) {
+	s.streamsMu.Lock()
+	delete(s.streams, streamKey{client: stream.client, id: stream.id})
+	s.streamsMu.Unlock()
+}
+
+// streamOf decodes a stream frame of the client and finds its stream.
+func (s *Server) streamOf(c *Ctx) (*ServerStream, *MessageWrapper, bool) {
+	var mw MessageWrapper
+	if _, err := unmarshalEnvelope(c.Body(), &mw); err != nil {
+		log.Errorf("[ARPC] decode stream frame err: %v", err)
+		return nil, nil, false
+	}
+	s.streamsMu.Lock()
+	stream, ok := s.streams[streamKey{client: c.Client, id: mw.Stream}]
+	s.streamsMu.Unlock()
+	return stream, &mw, ok
+}
+
+// handleStreamFrame queues the frames pushed by the client, the stream is reset
+// when the client overflows its window.
+func (s *Server) handleStreamFrame(c *Ctx) {
+	if stream, mw, ok := s.streamOf(c); ok {
+		if err := stream.push(mw); err == StreamWindowError {
+			log.Errorf("[ARPC] stream %d reset: %v", stream.id, err)
+			stream.reset(err)
+		}
+	}
+}
+
+// handleStreamWindow grows the send window on the client's acknowledgement.
+func (s *Server) handleStreamWindow(c *Ctx) {
+	if stream, mw, ok := s.streamOf(c); ok {
+		stream.grow(mw.Window)
+	}
+}
+
+// handleStreamCancel cancels the stream on the client's request.
+func (s *Server) handleStreamCancel(c *Ctx) {
+	if stream, _, ok := s.streamOf(c); ok {
+		stream.cancel()
+	}
+}
+
+// cancelStreams cancels the streams of the client, all of them if client is nil.
+func (s *Server) cancelStreams(client *arpc.Client) {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+	for k, stream := range s.streams {
+		if client == nil || k.client == client {
+			stream.cancel()
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package tarpc_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/transport/tarpc"
+	"github.com/LiangQinghai/kratos-ext/transport/tarpc/internal/helloworld"
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// greeter serves the generated Greeter, canceled is closed once a stream of "forever" is canceled.
+type greeter struct {
+	canceled chan struct{}
+}
+
+func (g *greeter) SayHello(_ context.Context, in *helloworld.HelloRequest) (*helloworld.HelloReply, error) {
+	return &helloworld.HelloReply{Message: "hello " + in.Name}, nil
+}
+
+func (g *greeter) ListHello(in *helloworld.HelloRequest, stream helloworld.Greeter_ListHelloArpcServer) error {
+	if in.Name == "forever" {
+		<-stream.Context().Done()
+		close(g.canceled)
+		return stream.Context().Err()
+	}
+	for i := 0; i < 3; i++ {
+		if err := stream.Send(&helloworld.HelloReply{Message: fmt.Sprintf("%s-%d", in.Name, i)}); err != nil {
+			return err
+		}
+	}
+	if in.Name == "error" {
+		return errors.BadRequest("STREAM", "stream error")
+	}
+	return nil
+}
+
+func (g *greeter) RecordHello(stream helloworld.Greeter_RecordHelloArpcServer) error {
+	var names []string
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&helloworld.HelloReply{Message: "hello " + strings.Join(names, ",")})
+		}
+		if err != nil {
+			return err
+		}
+		names = append(names, in.Name)
+	}
+}
+
+func (g *greeter) ChatHello(stream helloworld.Greeter_ChatHelloArpcServer) error {
+	var count int
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return stream.Send(&helloworld.HelloReply{Message: fmt.Sprintf("total-%d", count)})
+		}
+		if err != nil {
+			return err
+		}
+		count++
+		if err := stream.Send(&helloworld.HelloReply{Message: in.Name}); err != nil {
+			return err
+		}
+	}
+}
+
+// startServer starts srv and returns a client dialing it.
+func startServer(t *testing.T, srv *tarpc.Server, opts ...tarpc.ClientOption) *tarpc.Client {
+	ept, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	t.Cleanup(func() {
+		_ = srv.Stop(context.Background())
+	})
+	client, err := tarpc.Dail(context.Background(), append(opts, tarpc.WithEndpoint(ept.Host))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestServerStream(t *testing.T) {
+	ctx := context.Background()
+	g := &greeter{canceled: make(chan struct{})}
+	srv := tarpc.NewServer(tarpc.Address("127.0.0.1:19091"))
+	helloworld.RegisterGreeterArpcServer(srv, g)
+	cc := startServer(t, srv)
+	client := helloworld.NewGreeterArpcClient(cc)
+
+	reply, err := client.SayHello(ctx, &helloworld.HelloRequest{Name: "kratos"})
+	if err != nil || reply.Message != "hello kratos" {
+		t.Fatalf("expected hello kratos got %v %v", reply, err)
+	}
+
+	stream, err := client.ListHello(ctx, &helloworld.HelloRequest{Name: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reply.Message != fmt.Sprintf("hello-%d", i) {
+			t.Errorf("expected %s got %s", fmt.Sprintf("hello-%d", i), reply.Message)
+		}
+	}
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+
+	stream, err = client.ListHello(ctx, &helloworld.HelloRequest{Name: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	if errors.Reason(err) != "STREAM" {
+		t.Errorf("expected %s got %v", "STREAM", err)
+	}
+
+	// a request the server can't decode ends the stream with the error
+	raw, err := cc.NewStream(ctx, helloworld.ArpcOperationGreeterListHello, wrapperspb.Bytes([]byte{0xff}))
+	if err == nil {
+		err = raw.RecvMsg(new(helloworld.HelloReply))
+	}
+	if err == nil || err == io.EOF {
+		t.Errorf("expected a decode error got %v", err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	stream, err = client.ListHello(cctx, &helloworld.HelloRequest{Name: "forever"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err = stream.Recv(); err != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+	select {
+	case <-g.canceled:
+	case <-time.After(time.Second):
+		t.Error("expected the server stream to be canceled")
+	}
+}
+
+func TestServerClientStream(t *testing.T) {
+	ctx := context.Background()
+	srv := tarpc.NewServer(tarpc.Address("127.0.0.1:19098"))
+	helloworld.RegisterGreeterArpcServer(srv, &greeter{})
+	client := helloworld.NewGreeterArpcClient(startServer(t, srv))
+
+	stream, err := client.RecordHello(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if err = stream.Send(&helloworld.HelloRequest{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reply, err := stream.CloseAndRecv()
+	if err != nil || reply.Message != "hello a,b,c" {
+		t.Errorf("expected hello a,b,c got %v %v", reply, err)
+	}
+}
+
+func TestServerBidiStream(t *testing.T) {
+	ctx := context.Background()
+	srv := tarpc.NewServer(tarpc.Address("127.0.0.1:19092"), tarpc.StreamWindow(2))
+	helloworld.RegisterGreeterArpcServer(srv, &greeter{})
+	client := helloworld.NewGreeterArpcClient(startServer(t, srv, tarpc.WithStreamWindow(2)))
+
+	stream, err := client.ChatHello(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// more frames than both windows, the sender waits for acknowledgement
+	const n = 10
+	go func() {
+		for i := 0; i < n; i++ {
+			if err := stream.Send(&helloworld.HelloRequest{Name: fmt.Sprintf("msg-%d", i)}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		if err := stream.CloseSend(); err != nil {
+			t.Error(err)
+		}
+	}()
+	for i := 0; i < n; i++ {
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reply.Message != fmt.Sprintf("msg-%d", i) {
+			t.Errorf("expected %s got %s", fmt.Sprintf("msg-%d", i), reply.Message)
+		}
+	}
+	total, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.Message != fmt.Sprintf("total-%d", n) {
+		t.Errorf("expected %s got %s", fmt.Sprintf("total-%d", n), total.Message)
+	}
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+	if err = stream.Send(&helloworld.HelloRequest{}); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+}
@@ -2,6 +2,8 @@ package tarpc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/go-kratos/kratos/v2/transport"
 	"google.golang.org/grpc/metadata"
@@ -20,6 +22,7 @@ type Transport struct {
 	reqHeader   headerCarrier
 	replyHeader headerCarrier
 	nodeFilters []selector.NodeFilter
+	tlsState    *tls.ConnectionState
 }
 
 // Kind returns the transport kind.
@@ -52,6 +55,20 @@ func (tr *Transport) NodeFilters() []selector.NodeFilter {
 	return tr.nodeFilters
 }
 
+// ConnectionState returns the tls state of the connection of the call on the server, nil without tls.
+func (tr *Transport) ConnectionState() *tls.ConnectionState {
+	return tr.tlsState
+}
+
+// PeerCertificates returns the certificate chain the client presented, the leaf first,
+// it is empty unless the server asks for client certificates.
+func (tr *Transport) PeerCertificates() []*x509.Certificate {
+	if tr.tlsState == nil {
+		return nil
+	}
+	return tr.tlsState.PeerCertificates
+}
+
 type headerCarrier metadata.MD
 
 // Get returns the value associated with the passed key.
@@ -0,0 +1,58 @@
+package techo
+
+import (
+	"bytes"
+	"fmt"
+	pkgbinding "github.com/LiangQinghai/kratos-ext/pkg/binding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http/binding"
+	"io"
+	"net/url"
+)
+
+// BindBody decodes the request body into v with the codec of the Content-Type header,
+// an empty body leaves v untouched.
+func BindBody(ctx Ctx, v any) error {
+	codec, ok := CodecForRequest(ctx, "Content-Type")
+	if !ok {
+		return errors.BadRequest("CODEC", fmt.Sprintf("unregister Content-Type: %s", ctx.Request().Header.Get("Content-Type")))
+	}
+	req := ctx.Request()
+	data, err := io.ReadAll(req.Body)
+	// reset body.
+	req.Body = io.NopCloser(bytes.NewBuffer(data))
+	if err != nil {
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err = codec.Unmarshal(data, v); err != nil {
+		return errors.BadRequest("CODEC", fmt.Sprintf("body unmarshal %s", err.Error()))
+	}
+	return nil
+}
+
+// BindQuery decodes the query params into v, nested fields are named by their json path, e.g. message.id.
+func BindQuery(ctx Ctx, v any) error {
+	return binding.BindQuery(ctx.QueryParams(), v)
+}
+
+// BindPath decodes the path vars into v, nested fields are named by their json path, e.g. message.id.
+func BindPath(ctx Ctx, v any) error {
+	vars := make(url.Values, len(ctx.ParamNames()))
+	for i, name := range ctx.ParamNames() {
+		vars.Set(name, ctx.ParamValues()[i])
+	}
+	return binding.BindQuery(vars, v)
+}
+
+// BindPattern decodes the path vars into v by matching the request path with the
+// google.api.http path pattern, a {var=pattern} var keeps every segment it matches.
+func BindPattern(ctx Ctx, pattern string, v any) error {
+	vars, err := pkgbinding.PathVars(pattern, ctx.Request().URL.EscapedPath())
+	if err != nil {
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	return binding.BindQuery(vars, v)
+}
@@ -0,0 +1,60 @@
+package techo
+
+import (
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/pkg/httputil"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// EncodeResponseFunc is encode response func.
+type EncodeResponseFunc = func(ctx Ctx, v any) error
+
+// EncodeErrorFunc is encode error func.
+type EncodeErrorFunc func(ctx Ctx, err error)
+
+// DefaultErrorEncoder encodes the error to the HTTP response, the errors of echo such as
+// a missing route keep their status code.
+func DefaultErrorEncoder(ctx Ctx, err error) {
+	if ctx.Response().Committed {
+		return
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		err = errors.New(he.Code, http.StatusText(he.Code), fmt.Sprint(he.Message))
+	}
+	se := errors.FromError(err)
+	codec, _ := CodecForRequest(ctx, "Accept")
+	body, err := codec.Marshal(se)
+	if err != nil {
+		ctx.Response().WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_ = ctx.Blob(int(se.Code), httputil.ContentType(codec.Name()), body)
+}
+
+// CodecForRequest get encoding.Codec via http.Request
+func CodecForRequest(ctx Ctx, name string) (encoding.Codec, bool) {
+	for _, accept := range ctx.Request().Header[name] {
+		codec := encoding.GetCodec(httputil.ContentSubtype(accept))
+		if codec != nil {
+			return codec, true
+		}
+	}
+	return encoding.GetCodec("json"), false
+}
+
+// DefaultResponseEncoder encodes the object to the HTTP response.
+func DefaultResponseEncoder(ctx Ctx, v any) error {
+	if v == nil {
+		return nil
+	}
+	codec, _ := CodecForRequest(ctx, "Accept")
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ctx.Blob(http.StatusOK, httputil.ContentType(codec.Name()), data)
+}
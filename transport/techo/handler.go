@@ -0,0 +1,5 @@
+package techo
+
+import "github.com/labstack/echo/v4"
+
+type Handler = echo.HandlerFunc
@@ -0,0 +1,271 @@
+package techo
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
+	"github.com/LiangQinghai/kratos-ext/pkg/host"
+	"github.com/LiangQinghai/kratos-ext/pkg/matcher"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/labstack/echo/v4"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var (
+	_ transport.Server     = (*Server)(nil)
+	_ transport.Endpointer = (*Server)(nil)
+)
+
+// ServerOption is an echo framework option
+type ServerOption func(*Server)
+
+// Network set network
+func Network(network string) ServerOption {
+	return func(s *Server) {
+		s.network = network
+	}
+}
+
+// Address with address
+func Address(address string) ServerOption {
+	return func(s *Server) {
+		s.address = address
+	}
+}
+
+// Listener with a listener opened beforehand, served in place of the network and address.
+func Listener(lis net.Listener) ServerOption {
+	return func(s *Server) {
+		s.lis = lis
+		s.network = lis.Addr().Network()
+		s.address = lis.Addr().String()
+	}
+}
+
+// TLSConfig with the tls config of the echo server.
+func TLSConfig(c *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tlsConf = c
+	}
+}
+
+// Endpoint with endpoint
+func Endpoint(endpoint *url.URL) ServerOption {
+	return func(s *Server) {
+		s.endpoint = endpoint
+	}
+}
+
+// Middleware with service middleware option.
+func Middleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.middleware.Use(m...)
+	}
+}
+
+// ResponseEncoder with response encoder.
+func ResponseEncoder(en EncodeResponseFunc) ServerOption {
+	return func(s *Server) {
+		s.enc = en
+	}
+}
+
+// ErrorEncoder with error encoder.
+func ErrorEncoder(en EncodeErrorFunc) ServerOption {
+	return func(s *Server) {
+		s.ene = en
+	}
+}
+
+// RawMiddleware echo mid
+func RawMiddleware(m ...echo.MiddlewareFunc) ServerOption {
+	return func(s *Server) {
+		s.rawMid = append(s.rawMid, m...)
+	}
+}
+
+// Timeout with the timeout of the requests, zero disables it. A client may shorten
+// it with a Grpc-Timeout or X-Request-Timeout header.
+func Timeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = d
+	}
+}
+
+// OperationTimeout with the timeout of the operations matching selector in place of the server
+// timeout, zero disables it. Selectors are matched as middleware selectors, e.g. /helloworld.Greeter/*.
+func OperationTimeout(selector string, d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeouts.Add(selector, d)
+	}
+}
+
+// StreamHeartbeat with the heartbeat interval of server-sent event streams, zero disables it.
+func StreamHeartbeat(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.heartbeat = d
+	}
+}
+
+func NewServer(opts ...ServerOption) *Server {
+	srv := &Server{
+		network:    "tcp",
+		address:    ":0",
+		middleware: matcher.New(),
+		enc:        DefaultResponseEncoder,
+		ene:        DefaultErrorEncoder,
+		timeout:    3 * time.Second,
+		timeouts:   timeout.New(),
+		heartbeat:  15 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	srv.app = echo.New()
+	srv.app.HideBanner = true
+	srv.app.HidePort = true
+	srv.app.HTTPErrorHandler = func(err error, ctx echo.Context) {
+		srv.ene(ctx, err)
+	}
+	srv.app.Use(srv.transportMid())
+	srv.app.Use(srv.rawMid...)
+	srv.server = &http.Server{Handler: srv.app, TLSConfig: srv.tlsConf}
+	return srv
+}
+
+type Server struct {
+	app        *echo.Echo
+	server     *http.Server
+	lis        net.Listener
+	tlsConf    *tls.Config
+	endpoint   *url.URL
+	err        error
+	network    string
+	address    string
+	timeout    time.Duration
+	timeouts   timeout.Matcher
+	heartbeat  time.Duration
+	middleware matcher.Matcher
+	rawMid     []echo.MiddlewareFunc
+	enc        EncodeResponseFunc
+	ene        EncodeErrorFunc
+}
+
+func (s *Server) Start(_ context.Context) error {
+	err := s.initEndpoint()
+	if err != nil {
+		return err
+	}
+	log.Infof("[echo] server listening on %s", s.endpoint.String())
+	lis := s.lis
+	if s.tlsConf != nil {
+		lis = tls.NewListener(lis, s.tlsConf)
+	}
+	if err = s.server.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) Endpoint() (*url.URL, error) {
+	err := s.initEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	return s.endpoint, nil
+}
+
+// Middleware mid handler
+// m: middleware.Handler kratos middleware
+// ctx: context.Context
+// path: router path
+// returns: middleware.Handler
+func (s *Server) Middleware(m middleware.Handler, ctx context.Context, path string) middleware.Handler {
+	operation := path
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		operation = tr.Operation()
+	}
+	return timeout.Handler(s.timeouts, operation, middleware.Chain(s.middleware.Match(operation)...)(m))
+}
+
+// Group router group, the routes are served with the kratos transport
+// returns: *echo.Group
+func (s *Server) Group(prefix string, m ...echo.MiddlewareFunc) *echo.Group {
+	return s.app.Group(prefix, m...)
+}
+
+// Router the echo router, the routes are served with the kratos transport
+// returns: *echo.Echo
+func (s *Server) Router() *echo.Echo {
+	return s.app
+}
+
+// Write response data encode
+// returns error
+func (s *Server) Write(ctx Ctx, v any) error {
+	return s.enc(ctx, v)
+}
+
+func (s *Server) initEndpoint() error {
+	if s.lis == nil {
+		lis, err := net.Listen(s.network, s.address)
+		if err != nil {
+			s.err = err
+			return err
+		}
+		s.lis = lis
+	}
+	if s.endpoint == nil {
+		scheme := endpoint.Scheme("http", s.tlsConf != nil)
+		if path, ok := host.Unix(s.lis); ok {
+			s.endpoint = endpoint.NewUnixEndpoint(scheme, path)
+			return s.err
+		}
+		addr, err := host.ExtractFromLis(s.address, s.lis)
+		if err != nil {
+			s.err = err
+			return err
+		}
+		s.endpoint = endpoint.NewEndpoint(scheme, addr)
+	}
+	return s.err
+}
+
+func (s *Server) transportMid() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var (
+				ctx    context.Context
+				cancel context.CancelFunc
+			)
+			req := c.Request()
+			tr := Transport{
+				endpoint:     s.endpoint.String(),
+				operation:    c.Path(),
+				pathTemplate: c.Path(),
+				reqHeader:    headerCarrier(req.Header),
+				replyHeader:  headerCarrier(c.Response().Header()),
+				reqCtx:       c,
+			}
+			if d := timeout.Budget(s.timeout, req.Header); d > 0 {
+				ctx, cancel = context.WithTimeout(req.Context(), d)
+			} else {
+				ctx, cancel = context.WithCancel(req.Context())
+			}
+			defer cancel()
+			c.SetRequest(req.WithContext(transport.NewServerContext(ctx, &tr)))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package techo
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/pkg/sse"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// Stream is the server-sent event stream of a server-streaming rpc.
+type Stream = sse.Stream
+
+// NewStream returns the event stream of the request, messages are encoded with the codec
+// of the Accept header. The stream is not bound by the server timeout, it lasts until the
+// handler returns or the client disconnects.
+func (s *Server) NewStream(ctx Ctx) *Stream {
+	req := ctx.Request()
+	codec := sse.CodecForAccept(req.Header.Values("Accept")...)
+	lastEventID := req.Header.Get(sse.LastEventIDHeader)
+	return sse.NewStream(context.WithoutCancel(req.Context()), codec, lastEventID, s.heartbeat)
+}
+
+// ServeStream runs handler and writes the stream as the response. The handler error is
+// returned if it fails before sending any event, later errors are sent as an error event.
+func (s *Server) ServeStream(ctx Ctx, stream *Stream, handler func() error) error {
+	if err := stream.Run(handler); err != nil {
+		return err
+	}
+	w := ctx.Response()
+	w.Header().Set("Content-Type", sse.ContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := stream.WriteTo(&flushWriter{w}); err != nil {
+		log.Debugf("[echo] event stream closed: %v", err)
+	}
+	return nil
+}
+
+// flushWriter flushes the events of a stream to the client.
+type flushWriter struct {
+	*echo.Response
+}
+
+func (w *flushWriter) Flush() error {
+	w.Response.Flush()
+	return nil
+}
@@ -0,0 +1,393 @@
+package tfiber
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
+	"github.com/LiangQinghai/kratos-ext/pkg/host"
+	"github.com/LiangQinghai/kratos-ext/pkg/httputil"
+	"github.com/LiangQinghai/kratos-ext/pkg/resolver"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/wrr"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/valyala/fasthttp"
+	"time"
+)
+
+func init() {
+	if selector.GlobalSelector() == nil {
+		selector.SetGlobalSelector(wrr.NewBuilder())
+	}
+}
+
+// DecodeErrorFunc is decode error func.
+type DecodeErrorFunc func(ctx context.Context, res *fasthttp.Response) error
+
+// EncodeRequestFunc is request encode func.
+type EncodeRequestFunc func(ctx context.Context, contentType string, in interface{}) (body []byte, err error)
+
+// DecodeResponseFunc is response decode func.
+type DecodeResponseFunc func(ctx context.Context, res *fasthttp.Response, out interface{}) error
+
+// ClientOption is fiber client option.
+type ClientOption func(*clientOptions)
+
+// clientOptions is fiber client config
+type clientOptions struct {
+	timeout      time.Duration
+	endpoint     string
+	userAgent    string
+	encoder      EncodeRequestFunc
+	decoder      DecodeResponseFunc
+	errorDecoder DecodeErrorFunc
+	middleware   []middleware.Middleware
+	tlsConf      *tls.Config
+	discovery    registry.Discovery
+	nodeFilters  []selector.NodeFilter
+	selector     selector.Builder
+	block        bool
+	client       *fasthttp.Client
+}
+
+// WithTimeout with client request timeout.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithUserAgent with client user agent.
+func WithUserAgent(ua string) ClientOption {
+	return func(o *clientOptions) {
+		o.userAgent = ua
+	}
+}
+
+// WithMiddleware with client middleware.
+func WithMiddleware(m ...middleware.Middleware) ClientOption {
+	return func(o *clientOptions) {
+		o.middleware = m
+	}
+}
+
+// WithEndpoint with client addr, such as "127.0.0.1:8000", "direct:///127.0.0.1:8000" or "discovery:///svc".
+func WithEndpoint(endpoint string) ClientOption {
+	return func(o *clientOptions) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithRequestEncoder with client request encoder.
+func WithRequestEncoder(encoder EncodeRequestFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.encoder = encoder
+	}
+}
+
+// WithResponseDecoder with client response decoder.
+func WithResponseDecoder(decoder DecodeResponseFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.decoder = decoder
+	}
+}
+
+// WithErrorDecoder with client error decoder.
+func WithErrorDecoder(errorDecoder DecodeErrorFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.errorDecoder = errorDecoder
+	}
+}
+
+// WithDiscovery with client discovery.
+func WithDiscovery(d registry.Discovery) ClientOption {
+	return func(o *clientOptions) {
+		o.discovery = d
+	}
+}
+
+// WithNodeFilter with select filters
+func WithNodeFilter(filters ...selector.NodeFilter) ClientOption {
+	return func(o *clientOptions) {
+		o.nodeFilters = filters
+	}
+}
+
+// WithSelector with node selector builder, such as wrr, p2c or random,
+// selector.GlobalSelector is used by default.
+func WithSelector(b selector.Builder) ClientOption {
+	return func(o *clientOptions) {
+		o.selector = b
+	}
+}
+
+// WithBlock with client block, NewClient waits until the discovery returns available nodes.
+func WithBlock() ClientOption {
+	return func(o *clientOptions) {
+		o.block = true
+	}
+}
+
+// WithTLSConfig with tls config.
+func WithTLSConfig(c *tls.Config) ClientOption {
+	return func(o *clientOptions) {
+		o.tlsConf = c
+	}
+}
+
+// WithFastHTTPClient with raw fasthttp client
+func WithFastHTTPClient(c *fasthttp.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.client = c
+	}
+}
+
+// Client is a fiber transport client.
+type Client struct {
+	opts     clientOptions
+	target   *resolver.Target
+	r        *resolver.Resolver
+	cc       *fasthttp.Client
+	insecure bool
+	selector selector.Selector
+}
+
+// NewClient returns a fiber client.
+func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
+	options := clientOptions{
+		timeout:      2000 * time.Millisecond,
+		encoder:      DefaultRequestEncoder,
+		decoder:      DefaultResponseDecoder,
+		errorDecoder: DefaultErrorDecoder,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	insecure := options.tlsConf == nil
+	target, err := resolver.ParseTarget(options.endpoint, endpoint.Scheme("http", !insecure))
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme == "direct" {
+		target = &resolver.Target{Scheme: endpoint.Scheme("http", !insecure), Authority: target.Endpoint}
+	}
+	cc := options.client
+	if cc == nil {
+		cc = &fasthttp.Client{}
+	}
+	if !insecure {
+		cc.TLSConfig = options.tlsConf
+	}
+	builder := options.selector
+	if builder == nil {
+		builder = selector.GlobalSelector()
+	}
+	c := &Client{
+		opts:     options,
+		target:   target,
+		cc:       cc,
+		insecure: insecure,
+		selector: builder.Build(),
+	}
+	if options.discovery != nil {
+		if target.Scheme == "discovery" {
+			c.r, err = resolver.New(ctx, options.discovery, target, c.selector, options.block, endpoint.Scheme("http", !insecure))
+			if err != nil {
+				return nil, fmt.Errorf("[fiber client] new resolver failed!err: %v", err)
+			}
+		} else if _, _, err := host.ExtractHostPort(options.endpoint); err != nil {
+			return nil, fmt.Errorf("[fiber client] invalid endpoint format: %v", options.endpoint)
+		}
+	}
+	return c, nil
+}
+
+// Invoke makes a rpc call procedure for remote service.
+func (c *Client) Invoke(ctx context.Context, method, path string, args interface{}, reply interface{}, opts ...CallOption) error {
+	info := defaultCallInfo(path)
+	for _, o := range opts {
+		if err := o.before(&info); err != nil {
+			return err
+		}
+	}
+	req := fasthttp.AcquireRequest()
+	res := fasthttp.AcquireResponse()
+	defer func() {
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(res)
+	}()
+	if args != nil {
+		data, err := c.opts.encoder(ctx, info.contentType, args)
+		if err != nil {
+			return err
+		}
+		req.SetBody(data)
+		req.Header.SetContentType(info.contentType)
+	}
+	// negotiate the reply codec with the request content type
+	req.Header.Set("Accept", info.contentType)
+	req.Header.SetMethod(method)
+	req.SetRequestURI(fmt.Sprintf("%s://%s%s", c.target.Scheme, c.target.Authority, path))
+	if c.opts.userAgent != "" {
+		req.Header.SetUserAgent(c.opts.userAgent)
+	}
+	tr := &Transport{
+		endpoint:     c.opts.endpoint,
+		operation:    info.operation,
+		pathTemplate: info.pathTemplate,
+		reqHeader:    &requestHeaderCarrier{RequestHeader: &req.Header},
+		replyHeader:  &responseHeaderCarrier{ResponseHeader: &res.Header},
+	}
+	ctx = transport.NewClientContext(ctx, tr)
+	h := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		err := c.Do(ctx, req, res)
+		cs := csAttempt{res: res}
+		for _, o := range opts {
+			o.after(&info, &cs)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := c.opts.decoder(ctx, res, reply); err != nil {
+			return nil, err
+		}
+		return reply, nil
+	}
+	var p selector.Peer
+	ctx = selector.NewPeerContext(ctx, &p)
+	if len(c.opts.middleware) > 0 {
+		h = middleware.Chain(c.opts.middleware...)(h)
+	}
+	_, err := h(ctx, args)
+	return err
+}
+
+// Do send a fasthttp request and decode error of response,
+// returns an error (of type *errors.Error) if the response status code is not 2xx.
+func (c *Client) Do(ctx context.Context, req *fasthttp.Request, res *fasthttp.Response) error {
+	var done func(context.Context, selector.DoneInfo)
+	if c.r != nil {
+		var (
+			err  error
+			node selector.Node
+		)
+		if node, done, err = c.selector.Select(ctx, selector.WithNodeFilter(c.opts.nodeFilters...)); err != nil {
+			return errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
+		}
+		req.URI().SetScheme(endpoint.Scheme("http", !c.insecure))
+		req.SetHost(node.Address())
+	}
+	err := c.do(ctx, req, res)
+	if err == nil {
+		err = c.opts.errorDecoder(ctx, res)
+	}
+	if done != nil {
+		done(ctx, selector.DoneInfo{Err: err})
+	}
+	return err
+}
+
+// do sends req and returns once the response is read or ctx is done, as fasthttp ignores ctx.
+func (c *Client) do(ctx context.Context, req *fasthttp.Request, res *fasthttp.Response) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		return c.doDeadline(ctx, req, res)
+	}
+	// the request in flight works on copies, req and res are released by the caller once canceled
+	inReq, inRes := fasthttp.AcquireRequest(), fasthttp.AcquireResponse()
+	req.CopyTo(inReq)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.doDeadline(ctx, inReq, inRes)
+	}()
+	release := func() {
+		fasthttp.ReleaseRequest(inReq)
+		fasthttp.ReleaseResponse(inRes)
+	}
+	select {
+	case err := <-errc:
+		inRes.CopyTo(res)
+		release()
+		return err
+	case <-ctx.Done():
+		go func() {
+			<-errc
+			release()
+		}()
+		return ctx.Err()
+	}
+}
+
+func (c *Client) doDeadline(ctx context.Context, req *fasthttp.Request, res *fasthttp.Response) error {
+	if deadline, ok := c.deadline(ctx); ok {
+		return c.cc.DoDeadline(req, res, deadline)
+	}
+	return c.cc.Do(req, res)
+}
+
+// Close stops the discovery watcher and tears down the idle connections.
+func (c *Client) Close() error {
+	c.cc.CloseIdleConnections()
+	if c.r != nil {
+		return c.r.Close()
+	}
+	return nil
+}
+
+// deadline returns the earlier one of client timeout and context deadline.
+func (c *Client) deadline(ctx context.Context) (time.Time, bool) {
+	deadline, ok := ctx.Deadline()
+	if c.opts.timeout > 0 {
+		if d := time.Now().Add(c.opts.timeout); !ok || d.Before(deadline) {
+			return d, true
+		}
+	}
+	return deadline, ok
+}
+
+// DefaultRequestEncoder is a fiber request encoder.
+func DefaultRequestEncoder(_ context.Context, contentType string, in interface{}) ([]byte, error) {
+	name := httputil.ContentSubtype(contentType)
+	codec := encoding.GetCodec(name)
+	if codec == nil {
+		codec = encoding.GetCodec("json")
+	}
+	return codec.Marshal(in)
+}
+
+// DefaultResponseDecoder is a fiber response decoder.
+func DefaultResponseDecoder(_ context.Context, res *fasthttp.Response, v interface{}) error {
+	if v == nil || len(res.Body()) == 0 {
+		return nil
+	}
+	return CodecForResponse(res).Unmarshal(res.Body(), v)
+}
+
+// DefaultErrorDecoder is a fiber error decoder.
+func DefaultErrorDecoder(_ context.Context, res *fasthttp.Response) error {
+	if res.StatusCode() >= 200 && res.StatusCode() <= 299 {
+		return nil
+	}
+	e := new(errors.Error)
+	err := CodecForResponse(res).Unmarshal(res.Body(), e)
+	if err == nil {
+		e.Code = int32(res.StatusCode())
+		return e
+	}
+	return errors.Newf(res.StatusCode(), errors.UnknownReason, "").WithCause(err)
+}
+
+// CodecForResponse get encoding.Codec via fasthttp.Response
+func CodecForResponse(res *fasthttp.Response) encoding.Codec {
+	codec := encoding.GetCodec(httputil.ContentSubtype(string(res.Header.ContentType())))
+	if codec != nil {
+		return codec
+	}
+	return encoding.GetCodec("json")
+}
@@ -0,0 +1,170 @@
+package tfiber
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:18091"))
+	srv.Group("").Get("/client/:path", func(ctx *fiber.Ctx) error {
+		ctx.Set("X-Reply", ctx.Get("X-Request"))
+		if tr, ok := transport.FromServerContext(ctx.UserContext()); ok {
+			ctx.Set("X-Template", tr.(*Transport).PathTemplate())
+		}
+		return ctx.JSON(testData{Path: ctx.Params("path") + ctx.Query("query")})
+	})
+	srv.Group("").Post("/client/body", newBindHandler())
+	srv.Group("").Get("/errors/client", func(ctx *fiber.Ctx) error {
+		return kratoserrors.BadRequest("BAD", "bad request")
+	})
+	srv.Group("").Get("/slow/client", func(ctx *fiber.Ctx) error {
+		time.Sleep(time.Second)
+		return ctx.JSON(testData{})
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	var (
+		operation string
+		reply     string
+		accept    string
+		userAgent string
+	)
+	client, err := NewClient(ctx,
+		WithEndpoint("direct:///127.0.0.1:18091"),
+		WithUserAgent("tfiber-test"),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				tr, ok := transport.FromClientContext(ctx)
+				if ok {
+					operation = tr.Operation()
+					accept = tr.RequestHeader().Get("Accept")
+					userAgent = tr.RequestHeader().Get("User-Agent")
+					tr.RequestHeader().Set("X-Request", "request")
+				}
+				res, err := handler(ctx, req)
+				if ok {
+					reply = tr.ReplyHeader().Get("X-Reply")
+				}
+				return res, err
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var res testData
+	header := &fasthttp.ResponseHeader{}
+	err = client.Invoke(ctx, http.MethodGet, "/client/path?query=query", nil, &res,
+		Operation("/test/Get"), Header(header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Path != "pathquery" {
+		t.Errorf("expected %s got %s", "pathquery", res.Path)
+	}
+	if operation != "/test/Get" {
+		t.Errorf("expected %s got %s", "/test/Get", operation)
+	}
+	if reply != "request" || string(header.Peek("X-Reply")) != "request" {
+		t.Errorf("expected %s got %s", "request", reply)
+	}
+	if accept != "application/json" || userAgent != "tfiber-test" {
+		t.Errorf("expected the request headers to be seen by the middleware got %s %s", accept, userAgent)
+	}
+	if got := string(header.Peek("X-Template")); got != "/client/:path" {
+		t.Errorf("expected %s got %s", "/client/:path", got)
+	}
+
+	var bind bindData
+	err = client.Invoke(ctx, http.MethodPost, "/client/body", &bindData{Body: "body"}, &bind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bind.Body != "body" {
+		t.Errorf("expected %s got %s", "body", bind.Body)
+	}
+
+	err = client.Invoke(ctx, http.MethodGet, "/errors/client", nil, &res)
+	if kratoserrors.Code(err) != http.StatusBadRequest || kratoserrors.Reason(err) != "BAD" {
+		t.Errorf("expected 400 BAD got %v", err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	err = client.Invoke(cctx, http.MethodGet, "/slow/client", nil, &res)
+	if err != context.Canceled || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected %v at once got %v after %v", context.Canceled, err, time.Since(start))
+	}
+}
+
+func TestClientDiscovery(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:18092"))
+	srv.Group("").Get("/discovery", newHandleFuncWrapper())
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	d := testutil.NewDiscovery(
+		&registry.ServiceInstance{ID: "1", Name: "tfiber", Endpoints: []string{"http://127.0.0.1:18092"}},
+	)
+	var peer string
+	client, err := NewClient(ctx,
+		WithEndpoint("discovery:///tfiber"),
+		WithDiscovery(d),
+		WithBlock(),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				reply, err := handler(ctx, req)
+				if p, ok := selector.FromPeerContext(ctx); ok && p.Node != nil {
+					peer = p.Node.Address()
+				}
+				return reply, err
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var res testData
+	err = client.Invoke(ctx, http.MethodGet, "/discovery", nil, &res, ContentType("application/proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Path != "/discovery" {
+		t.Errorf("expected %s got %s", "/discovery", res.Path)
+	}
+	if peer != "127.0.0.1:18092" {
+		t.Errorf("expected %s got %s", "127.0.0.1:18092", peer)
+	}
+}
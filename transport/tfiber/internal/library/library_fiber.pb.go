@@ -0,0 +1,153 @@
+// Code generated by protoc-gen-go-fiber. DO NOT EDIT.
+// version:
+// - protoc-gen-go-fiber v0.0.1
+// - protoc             v4.25.3
+// source: library.proto
+
+package library
+
+import (
+	context "context"
+	testutil "github.com/LiangQinghai/kratos-ext/internal/testutil"
+	tfiber "github.com/LiangQinghai/kratos-ext/transport/tfiber"
+)
+
+var _ = new(context.Context)
+
+const _ = tfiber.SupportPackageIsVersion1
+
+const FiberOperationLibraryGetBook = "/library.Library/GetBook"
+const FiberOperationLibraryGetFile = "/library.Library/GetFile"
+const FiberOperationLibraryListBooks = "/library.Library/ListBooks"
+
+type LibraryFiberServer interface {
+	GetBook(context.Context, *testutil.GetBookRequest) (*testutil.Book, error)
+	GetFile(context.Context, *testutil.GetFileRequest) (*testutil.File, error)
+	ListBooks(context.Context, *testutil.ListBooksRequest) (*testutil.ListBooksReply, error)
+}
+
+func RegisterLibraryFiberServer(s *tfiber.Server, srv LibraryFiberServer) {
+	r := s.Router()
+	r.Get("/v1/shelves/:shelves/books/:books", _Library_GetBook0_Fiber_Handler(s, srv))
+	r.Get("/v1/shelves/:shelves/books", _Library_ListBooks0_Fiber_Handler(s, srv))
+	r.Get("/v1/files/*", _Library_GetFile0_Fiber_Handler(s, srv))
+}
+
+func _Library_GetBook0_Fiber_Handler(s *tfiber.Server, srv LibraryFiberServer) tfiber.Handler {
+	return func(ctx *tfiber.Ctx) error {
+		var in testutil.GetBookRequest
+		if err := ctx.QueryParser(&in); err != nil {
+			return err
+		}
+		if err := tfiber.BindPattern(ctx, "/v1/{name=shelves/*/books/*}", &in); err != nil {
+			return err
+		}
+		tfiber.SetOperation(ctx.UserContext(), FiberOperationLibraryGetBook)
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetBook(ctx, req.(*testutil.GetBookRequest))
+		}, ctx.UserContext(), ctx.Path())
+		out, err := h(ctx.UserContext(), &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*testutil.Book)
+		return s.Write(ctx, reply)
+	}
+}
+
+func _Library_ListBooks0_Fiber_Handler(s *tfiber.Server, srv LibraryFiberServer) tfiber.Handler {
+	return func(ctx *tfiber.Ctx) error {
+		var in testutil.ListBooksRequest
+		if err := ctx.QueryParser(&in); err != nil {
+			return err
+		}
+		if err := tfiber.BindPattern(ctx, "/v1/{parent=shelves/*}/books", &in); err != nil {
+			return err
+		}
+		tfiber.SetOperation(ctx.UserContext(), FiberOperationLibraryListBooks)
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.ListBooks(ctx, req.(*testutil.ListBooksRequest))
+		}, ctx.UserContext(), ctx.Path())
+		out, err := h(ctx.UserContext(), &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*testutil.ListBooksReply)
+		return s.Write(ctx, reply)
+	}
+}
+
+func _Library_GetFile0_Fiber_Handler(s *tfiber.Server, srv LibraryFiberServer) tfiber.Handler {
+	return func(ctx *tfiber.Ctx) error {
+		var in testutil.GetFileRequest
+		if err := ctx.QueryParser(&in); err != nil {
+			return err
+		}
+		if err := tfiber.BindPattern(ctx, "/v1/{name=files/**}", &in); err != nil {
+			return err
+		}
+		tfiber.SetOperation(ctx.UserContext(), FiberOperationLibraryGetFile)
+		h := s.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetFile(ctx, req.(*testutil.GetFileRequest))
+		}, ctx.UserContext(), ctx.Path())
+		out, err := h(ctx.UserContext(), &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*testutil.File)
+		return s.Write(ctx, reply)
+	}
+}
+
+type LibraryFiberClient interface {
+	GetBook(ctx context.Context, req *testutil.GetBookRequest, opts ...tfiber.CallOption) (rsp *testutil.Book, err error)
+	GetFile(ctx context.Context, req *testutil.GetFileRequest, opts ...tfiber.CallOption) (rsp *testutil.File, err error)
+	ListBooks(ctx context.Context, req *testutil.ListBooksRequest, opts ...tfiber.CallOption) (rsp *testutil.ListBooksReply, err error)
+}
+
+type _LibraryFiberClientImpl struct {
+	cc *tfiber.Client
+}
+
+func NewLibraryFiberClient(cc *tfiber.Client) LibraryFiberClient {
+	return &_LibraryFiberClientImpl{cc}
+}
+
+func (c *_LibraryFiberClientImpl) GetBook(ctx context.Context, in *testutil.GetBookRequest, opts ...tfiber.CallOption) (*testutil.Book, error) {
+	var out testutil.Book
+	pattern := "/v1/{name=shelves/*/books/*}"
+	path := tfiber.EncodeURL(pattern, in, true)
+	opts = append(opts, tfiber.Operation(FiberOperationLibraryGetBook))
+	opts = append(opts, tfiber.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *_LibraryFiberClientImpl) GetFile(ctx context.Context, in *testutil.GetFileRequest, opts ...tfiber.CallOption) (*testutil.File, error) {
+	var out testutil.File
+	pattern := "/v1/{name=files/**}"
+	path := tfiber.EncodeURL(pattern, in, true)
+	opts = append(opts, tfiber.Operation(FiberOperationLibraryGetFile))
+	opts = append(opts, tfiber.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *_LibraryFiberClientImpl) ListBooks(ctx context.Context, in *testutil.ListBooksRequest, opts ...tfiber.CallOption) (*testutil.ListBooksReply, error) {
+	var out testutil.ListBooksReply
+	pattern := "/v1/{parent=shelves/*}/books"
+	path := tfiber.EncodeURL(pattern, in, true)
+	opts = append(opts, tfiber.Operation(FiberOperationLibraryListBooks))
+	opts = append(opts, tfiber.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,32 @@
+package tfiber_test
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	"github.com/LiangQinghai/kratos-ext/transport/tfiber"
+	"github.com/LiangQinghai/kratos-ext/transport/tfiber/internal/library"
+	"testing"
+	"time"
+)
+
+func TestPatternVars(t *testing.T) {
+	ctx := context.Background()
+	srv := tfiber.NewServer(tfiber.Address("127.0.0.1:18109"))
+	library.RegisterLibraryFiberServer(srv, testutil.LibraryServer{})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	cc, err := tfiber.NewClient(ctx, tfiber.WithEndpoint("direct:///127.0.0.1:18109"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	testutil.CheckLibrary(t, testutil.LibraryClient(ctx, library.NewLibraryFiberClient(cc)))
+}
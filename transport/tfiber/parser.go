@@ -1,6 +1,9 @@
 package tfiber
 
 import (
+	"github.com/LiangQinghai/kratos-ext/pkg/binding"
+	"github.com/go-kratos/kratos/v2/errors"
+	httpbinding "github.com/go-kratos/kratos/v2/transport/http/binding"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -11,3 +14,19 @@ func init() {
 		ZeroEmpty:         true,
 	})
 }
+
+// EncodeURL encode proto message to url path, the fields which are neither
+// bound to path vars nor listed in excludes are encoded as query params when needQuery is true.
+func EncodeURL(pathTemplate string, msg interface{}, needQuery bool, excludes ...string) string {
+	return binding.EncodeURL(pathTemplate, msg, needQuery, excludes...)
+}
+
+// BindPattern decodes the path vars into v by matching the request path with the
+// google.api.http path pattern, a {var=pattern} var keeps every segment it matches.
+func BindPattern(ctx *Ctx, pattern string, v any) error {
+	vars, err := binding.PathVars(pattern, string(ctx.Request().URI().PathOriginal()))
+	if err != nil {
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	return httpbinding.BindQuery(vars, v)
+}
@@ -6,11 +6,14 @@ import (
 	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
 	"github.com/LiangQinghai/kratos-ext/pkg/host"
 	"github.com/LiangQinghai/kratos-ext/pkg/matcher"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/gofiber/fiber/v2"
+	"net"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -36,6 +39,26 @@ func Address(address string) ServerOption {
 	}
 }
 
+// Listener with a listener opened beforehand, such as a unix socket or a socket activated by systemd,
+// in place of the network and address. Prefork is not supported with it.
+func Listener(lis net.Listener) ServerOption {
+	return func(s *Server) {
+		s.lis = lis
+		s.network = lis.Addr().Network()
+		s.address = lis.Addr().String()
+	}
+}
+
+// TLSConfig with the tls config of the server, it is honored in full: certificates picked
+// by GetCertificate, client certificates verified against ClientCAs and the NextProtos of ALPN.
+// Use a tlsutil.CertReloader as GetCertificate to reload the certificate from disk.
+// Prefork is not supported with it.
+func TLSConfig(c *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tlsConf = c
+	}
+}
+
 // Endpoint with endpoint
 func Endpoint(endpoint *url.URL) ServerOption {
 	return func(s *Server) {
@@ -92,6 +115,29 @@ func FiberConfig(c *fiber.Config) ServerOption {
 	}
 }
 
+// Timeout with the timeout of the requests, zero disables it. A client may shorten
+// it with a Grpc-Timeout or X-Request-Timeout header.
+func Timeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = d
+	}
+}
+
+// OperationTimeout with the timeout of the operations matching selector in place of the server
+// timeout, zero disables it. Selectors are matched as middleware selectors, e.g. /helloworld.Greeter/*.
+func OperationTimeout(selector string, d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeouts.Add(selector, d)
+	}
+}
+
+// StreamHeartbeat with the heartbeat interval of server-sent event streams, zero disables it.
+func StreamHeartbeat(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.heartbeat = d
+	}
+}
+
 func NewServer(opts ...ServerOption) *Server {
 	srv := &Server{
 		network:    "tcp",
@@ -99,6 +145,8 @@ func NewServer(opts ...ServerOption) *Server {
 		middleware: matcher.New(),
 		enc:        DefaultResponseEncoder,
 		timeout:    3 * time.Second,
+		timeouts:   timeout.New(),
+		heartbeat:  15 * time.Second,
 		fiberConfig: &fiber.Config{
 			ErrorHandler:          DefaultErrorEncoder,
 			DisableStartupMessage: true,
@@ -107,18 +155,24 @@ func NewServer(opts ...ServerOption) *Server {
 	for _, opt := range opts {
 		opt(srv)
 	}
+	if strings.HasPrefix(srv.network, "tcp") {
+		srv.fiberConfig.Network = srv.network
+	}
 	srv.app = fiber.New(*srv.fiberConfig)
 	return srv
 }
 
 type Server struct {
 	app         *fiber.App
+	lis         net.Listener
 	tlsConf     *tls.Config
 	endpoint    *url.URL
 	err         error
 	network     string
 	address     string
 	timeout     time.Duration
+	timeouts    timeout.Matcher
+	heartbeat   time.Duration
 	middleware  matcher.Matcher
 	rawMid      []fiber.Handler
 	enc         EncodeResponseFunc
@@ -130,19 +184,15 @@ func (s *Server) Start(_ context.Context) error {
 	if err != nil {
 		return err
 	}
-	if s.tlsConf != nil {
-		err := s.app.ListenTLSWithCertificate(s.address, s.tlsConf.Certificates[0])
-		if err != nil {
-			return err
-		}
-	} else {
-		err := s.app.Listen(s.address)
-		if err != nil {
-			return err
+	log.Infof("[fiber] server listening on %s", s.endpoint.String())
+	if s.lis != nil {
+		lis := s.lis
+		if s.tlsConf != nil {
+			lis = tls.NewListener(lis, s.tlsConf)
 		}
+		return s.app.Listener(lis)
 	}
-	log.Infof("[fiber] server listening on %s", s.endpoint.String())
-	return nil
+	return s.app.Listen(s.address)
 }
 
 func (s *Server) Stop(ctx context.Context) error {
@@ -163,10 +213,11 @@ func (s *Server) Endpoint() (*url.URL, error) {
 // path: router path
 // returns: middleware.Handler
 func (s *Server) Middleware(m middleware.Handler, ctx context.Context, path string) middleware.Handler {
+	operation := path
 	if tr, ok := transport.FromServerContext(ctx); ok {
-		return middleware.Chain(s.middleware.Match(tr.Operation())...)(m)
+		operation = tr.Operation()
 	}
-	return middleware.Chain(s.middleware.Match(path)...)(m)
+	return timeout.Handler(s.timeouts, operation, middleware.Chain(s.middleware.Match(operation)...)(m))
 }
 
 // Group router group, it will use Router function
@@ -199,13 +250,38 @@ func (s *Server) Static(prefix, root string, config ...fiber.Static) fiber.Route
 }
 
 func (s *Server) initEndpoint() error {
+	// fiber listens on tcp without tls only, the tls config is honored in full by a tls listener
+	// and the other networks are listened here
+	if s.lis == nil && (s.tlsConf != nil || !strings.HasPrefix(s.network, "tcp")) {
+		lis, err := net.Listen(s.network, s.address)
+		if err != nil {
+			s.err = err
+			return err
+		}
+		s.lis = lis
+	}
 	if s.endpoint == nil {
-		addr, err := host.Extract(s.address)
+		scheme := endpoint.Scheme("http", s.tlsConf != nil)
+		if s.lis != nil {
+			if path, ok := host.Unix(s.lis); ok {
+				s.endpoint = endpoint.NewUnixEndpoint(scheme, path)
+				return s.err
+			}
+		}
+		var (
+			addr string
+			err  error
+		)
+		if s.lis != nil {
+			addr, err = host.ExtractFromLis(s.address, s.lis)
+		} else {
+			addr, err = host.Extract(s.address)
+		}
 		if err != nil {
 			s.err = err
 			return err
 		}
-		s.endpoint = endpoint.NewEndpoint(endpoint.Scheme("http", s.tlsConf != nil), addr)
+		s.endpoint = endpoint.NewEndpoint(scheme, addr)
 	}
 	return s.err
 }
@@ -216,18 +292,18 @@ func (s *Server) transportMid() fiber.Handler {
 			ctx    context.Context
 			cancel context.CancelFunc
 		)
-		if s.timeout > 0 {
-			ctx, cancel = context.WithTimeout(c.UserContext(), s.timeout)
-		} else {
-			ctx, cancel = context.WithCancel(c.UserContext())
-		}
-		defer cancel()
 		tr := Transport{
 			endpoint:    s.endpoint.String(),
-			reqHeader:   c.GetReqHeaders(),
-			replyHeader: c.GetRespHeaders(),
+			reqHeader:   headerCarrier(c.GetReqHeaders()),
+			replyHeader: headerCarrier(c.GetRespHeaders()),
 			reqCtx:      c,
 		}
+		if d := timeout.Budget(s.timeout, tr.reqHeader); d > 0 {
+			ctx, cancel = context.WithTimeout(c.UserContext(), d)
+		} else {
+			ctx, cancel = context.WithCancel(c.UserContext())
+		}
+		defer cancel()
 		c.SetUserContext(transport.NewServerContext(ctx, &tr))
 		return c.Next()
 	}
@@ -1,16 +1,27 @@
 package tfiber
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	"github.com/LiangQinghai/kratos-ext/pkg/tlsutil"
 	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/go-kratos/kratos/v2/transport/http"
 	"github.com/gofiber/fiber/v2"
 	"io"
+	"net"
 	http2 "net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -273,3 +284,355 @@ func testClient(t *testing.T, srv *Server) {
 		}
 	}
 }
+
+func TestServerStream(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:18093"), StreamHeartbeat(50*time.Millisecond))
+	closed := make(chan error, 1)
+	srv.Router().Get("/stream/:n", func(ctx *fiber.Ctx) error {
+		n := ctx.Params("n")
+		stream := srv.NewStream(ctx)
+		return srv.ServeStream(ctx, stream, func() error {
+			if n == "forever" {
+				for {
+					if err := stream.Send(&testData{Path: n}); err != nil {
+						closed <- err
+						return err
+					}
+				}
+			}
+			if n == "0" {
+				return kratoserrors.BadRequest("BAD", "bad")
+			}
+			for i := 0; i < 2; i++ {
+				if err := stream.Send(&testData{Path: n}); err != nil {
+					return err
+				}
+			}
+			return kratoserrors.Conflict("CONFLICT", "conflict")
+		})
+	})
+	reused := make(chan struct{})
+	srv.Router().Get("/header", func(ctx *fiber.Ctx) error {
+		stream := srv.NewStream(ctx)
+		return srv.ServeStream(ctx, stream, func() error {
+			if err := stream.Send(&testData{Path: "first"}); err != nil {
+				return err
+			}
+			// the fiber ctx has served other requests by now
+			<-reused
+			tr, _ := transport.FromServerContext(stream.Context())
+			if _, ok := RequestFromServerContext(stream.Context()); ok {
+				return stream.Send(&testData{Path: "fiber ctx"})
+			}
+			return stream.Send(&testData{Path: tr.RequestHeader().Get("X-Foo")})
+		})
+	})
+	srv.Router().Get("/ping", func(ctx *fiber.Ctx) error {
+		return ctx.SendString("pong")
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	req, _ := http2.NewRequest(http2.MethodGet, "http://127.0.0.1:18093/stream/1", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Last-Event-ID", "5")
+	resp, err := http2.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected %s got %s", "text/event-stream", ct)
+	}
+	want := "id: 6\ndata: {\"path\":\"1\"}\n\nid: 7\ndata: {\"path\":\"1\"}\n\nevent: error\ndata: "
+	if !strings.HasPrefix(string(content), want) || !strings.Contains(string(content), `"reason":"CONFLICT"`) {
+		t.Errorf("unexpected stream %q", content)
+	}
+
+	resp, err = http2.Get("http://127.0.0.1:18093/stream/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http2.StatusBadRequest {
+		t.Errorf("expected %d got %d", http2.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp, err = http2.Get("http://127.0.0.1:18093/stream/forever")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = resp.Body.Read(make([]byte, 64))
+	_ = resp.Body.Close()
+	select {
+	case err = <-closed:
+		if err != context.Canceled {
+			t.Errorf("expected %v got %v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the stream to stop once the client disconnects")
+	}
+
+	req, _ = http2.NewRequest(http2.MethodGet, "http://127.0.0.1:18093/header", nil)
+	req.Header.Set("X-Foo", "foo")
+	resp, err = http2.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewReader(resp.Body)
+	for line := ""; !strings.HasPrefix(line, "data: "); {
+		if line, err = events.ReadString('\n'); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		req, _ = http2.NewRequest(http2.MethodGet, "http://127.0.0.1:18093/ping", nil)
+		req.Header.Set("X-Foo", strings.Repeat("bar", i+1))
+		if resp, err := http2.DefaultClient.Do(req); err == nil {
+			_ = resp.Body.Close()
+		}
+	}
+	close(reused)
+	content, err = io.ReadAll(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `data: {"path":"foo"}`) {
+		t.Errorf("expected the header of the stream request got %q", content)
+	}
+}
+
+func TestServerTimeout(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(
+		Address("127.0.0.1:18094"),
+		Timeout(100*time.Millisecond),
+		OperationTimeout("/slow/*", time.Second),
+	)
+	srv.Router().Get("/wait/:op", func(ctx *fiber.Ctx) error {
+		c := ctx.UserContext()
+		SetOperation(c, "/"+ctx.Params("op")+"/wait")
+		h := srv.Middleware(func(ctx context.Context, _ interface{}) (interface{}, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(300 * time.Millisecond):
+				return &testData{Path: "done"}, nil
+			}
+		}, c, ctx.Path())
+		out, err := h(c, nil)
+		if err != nil {
+			return err
+		}
+		return srv.Write(ctx, out)
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	tests := []struct {
+		name   string
+		op     string
+		header map[string]string
+		code   int
+	}{
+		{"server", "fast", nil, http2.StatusGatewayTimeout},
+		{"operation", "slow", nil, http2.StatusOK},
+		{"grpc", "slow", map[string]string{"Grpc-Timeout": "50m"}, http2.StatusGatewayTimeout},
+		{"request", "slow", map[string]string{"X-Request-Timeout": "0.05"}, http2.StatusGatewayTimeout},
+		{"longer", "fast", map[string]string{"X-Request-Timeout": "10s"}, http2.StatusGatewayTimeout},
+	}
+	for _, test := range tests {
+		req, _ := http2.NewRequest(http2.MethodGet, "http://127.0.0.1:18094/wait/"+test.op, nil)
+		for k, v := range test.header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http2.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != test.code {
+			t.Errorf("%s: expected %d got %d %s", test.name, test.code, resp.StatusCode, body)
+		}
+		if test.code == http2.StatusGatewayTimeout && !strings.Contains(string(body), "DEADLINE_EXCEEDED") {
+			t.Errorf("%s: unexpected body %s", test.name, body)
+		}
+	}
+}
+
+func TestServerListener(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "app.sock")
+	srv := NewServer(Network("unix"), Address(path))
+	srv.Router().Get("/ping", func(ctx *fiber.Ctx) error {
+		return srv.Write(ctx, &testData{Path: "pong"})
+	})
+	ept, err := srv.Endpoint()
+	if err != nil || ept.String() != "http+unix://"+path {
+		t.Errorf("expected %s got %v %v", "http+unix://"+path, ept, err)
+	}
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	client := &http2.Client{Transport: &http2.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var data testData
+	if err = json.NewDecoder(resp.Body).Decode(&data); err != nil || data.Path != "pong" {
+		t.Errorf("expected %s got %v %v", "pong", data, err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	if ept, err = NewServer(Listener(lis)).Endpoint(); err != nil || ept.Host != lis.Addr().String() {
+		t.Errorf("expected %s got %v %v", lis.Addr(), ept, err)
+	}
+}
+
+// writeCertificate writes the certificate and its key as pem files.
+func writeCertificate(t *testing.T, cert tls.Certificate, certFile, keyFile string) {
+	key, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	fooCert, fooPool := testutil.Certificate(t, "foo.test")
+	writeCertificate(t, fooCert, certFile, keyFile)
+	reloader, err := tlsutil.NewCertReloader(certFile, keyFile, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	barCert, barPool := testutil.Certificate(t, "bar.test")
+	clientCert, clientPool := testutil.Certificate(t, "client")
+	serverConf := &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName == "bar.test" {
+				return &barCert, nil
+			}
+			return reloader.GetCertificate(hello)
+		},
+		ClientCAs:  clientPool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		NextProtos: []string{"http/1.1"},
+	}
+	srv := NewServer(Address("127.0.0.1:18095"), TLSConfig(serverConf))
+	srv.Router().Get("/ping", func(ctx *fiber.Ctx) error {
+		return srv.Write(ctx, &testData{Path: ctx.Protocol()})
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+	ept, err := srv.Endpoint()
+	if err != nil || ept.String() != "https://127.0.0.1:18095" {
+		t.Errorf("expected %s got %v %v", "https://127.0.0.1:18095", ept, err)
+	}
+
+	get := func(name string, pool *x509.CertPool) error {
+		client := &http2.Client{Transport: &http2.Transport{TLSClientConfig: &tls.Config{
+			ServerName:   name,
+			RootCAs:      pool,
+			Certificates: []tls.Certificate{clientCert},
+			NextProtos:   []string{"http/1.1"},
+		}}}
+		defer client.CloseIdleConnections()
+		resp, err := client.Get("https://127.0.0.1:18095/ping")
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		var data testData
+		if err = json.NewDecoder(resp.Body).Decode(&data); err != nil || data.Path != "https" {
+			t.Errorf("expected %s got %v %v", "https", data, err)
+		}
+		if resp.TLS.NegotiatedProtocol != "http/1.1" {
+			t.Errorf("expected %s got %s", "http/1.1", resp.TLS.NegotiatedProtocol)
+		}
+		return nil
+	}
+	if err = get("foo.test", fooPool); err != nil {
+		t.Fatal(err)
+	}
+	if err = get("bar.test", barPool); err != nil {
+		t.Fatal(err)
+	}
+
+	// the renewed certificate is served without a restart
+	bazCert, bazPool := testutil.Certificate(t, "foo.test")
+	writeCertificate(t, bazCert, certFile, keyFile)
+	later := time.Now().Add(time.Second)
+	for _, file := range []string{certFile, keyFile} {
+		if err = os.Chtimes(file, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = get("foo.test", fooPool); err == nil {
+		t.Error("expected an error for the replaced certificate")
+	}
+	if err = get("foo.test", bazPool); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http2.Client{Transport: &http2.Transport{TLSClientConfig: &tls.Config{
+		ServerName: "foo.test",
+		RootCAs:    bazPool,
+	}}}
+	if resp, err := client.Get("https://127.0.0.1:18095/ping"); err == nil {
+		resp.Body.Close()
+		t.Error("expected an error without a client certificate")
+	}
+}
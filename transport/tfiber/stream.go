@@ -0,0 +1,52 @@
+package tfiber
+
+import (
+	"bufio"
+	"context"
+	"github.com/LiangQinghai/kratos-ext/pkg/sse"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"strings"
+)
+
+// Stream is the server-sent event stream of a server-streaming rpc.
+type Stream = sse.Stream
+
+// NewStream returns the event stream of the request, messages are encoded with the codec
+// of the Accept header. The stream is not bound by the server timeout, it lasts until the
+// handler returns or the client disconnects. Its context outlives ctx, so it carries a copy
+// of the Transport without the fiber ctx.
+func (s *Server) NewStream(ctx *Ctx) *Stream {
+	codec := sse.CodecForAccept(ctx.GetReqHeaders()["Accept"]...)
+	lastEventID := strings.Clone(ctx.Get(sse.LastEventIDHeader))
+	return sse.NewStream(detachContext(ctx.UserContext()), codec, lastEventID, s.heartbeat)
+}
+
+// detachContext returns a context which outlives the request, fiber reuses the ctx and
+// the memory of its strings once the handler returns.
+func detachContext(ctx context.Context) context.Context {
+	ctx = context.WithoutCancel(ctx)
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if tr, ok := tr.(*Transport); ok {
+			ctx = transport.NewServerContext(ctx, tr.detach())
+		}
+	}
+	return ctx
+}
+
+// ServeStream runs handler and writes the stream as the response. The handler error is
+// returned if it fails before sending any event, later errors are sent as an error event.
+// The events are written after the fiber handler returns, so handler must not use ctx.
+func (s *Server) ServeStream(ctx *Ctx, stream *Stream, handler func() error) error {
+	if err := stream.Run(handler); err != nil {
+		return err
+	}
+	ctx.Set("Content-Type", sse.ContentType)
+	ctx.Set("Cache-Control", "no-cache")
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := stream.WriteTo(w); err != nil {
+			log.Debugf("[fiber] event stream closed: %v", err)
+		}
+	})
+	return nil
+}
@@ -3,6 +3,9 @@ package tfiber
 import (
 	"context"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/valyala/fasthttp"
+	"slices"
+	"strings"
 )
 
 const (
@@ -12,11 +15,12 @@ const (
 )
 
 type Transport struct {
-	endpoint    string
-	operation   string
-	reqHeader   headerCarrier
-	replyHeader headerCarrier
-	reqCtx      *Ctx
+	endpoint     string
+	operation    string
+	pathTemplate string
+	reqHeader    transport.Header
+	replyHeader  transport.Header
+	reqCtx       *Ctx
 }
 
 func (t *Transport) Kind() transport.Kind {
@@ -39,9 +43,44 @@ func (t *Transport) ReplyHeader() transport.Header {
 	return t.replyHeader
 }
 
+// PathTemplate returns the http path template.
+func (t *Transport) PathTemplate() string {
+	// the route of the handler is only matched once the transport middleware has run
+	if t.pathTemplate == "" && t.reqCtx != nil {
+		return t.reqCtx.Route().Path
+	}
+	return t.pathTemplate
+}
+
+// detach copies the transport without the fiber ctx, the headers are copied as well.
+func (t *Transport) detach() *Transport {
+	return &Transport{
+		endpoint:     t.endpoint,
+		operation:    t.operation,
+		pathTemplate: t.PathTemplate(),
+		reqHeader:    cloneHeader(t.reqHeader),
+		replyHeader:  cloneHeader(t.replyHeader),
+	}
+}
+
 // header
 type headerCarrier map[string][]string
 
+// cloneHeader copies the keys and the values, fiber returns them without copy unless Immutable is set.
+func cloneHeader(h transport.Header) headerCarrier {
+	keys := h.Keys()
+	c := make(headerCarrier, len(keys))
+	for _, k := range keys {
+		v := h.Values(k)
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = strings.Clone(v[i])
+		}
+		c[strings.Clone(k)] = values
+	}
+	return c
+}
+
 func (h headerCarrier) Get(key string) string {
 	if v, ok := h[key]; ok {
 		return v[0]
@@ -62,8 +101,8 @@ func (h headerCarrier) Add(key string, value string) {
 }
 
 func (h headerCarrier) Keys() []string {
-	keys := make([]string, len(h))
-	for k, _ := range h {
+	keys := make([]string, 0, len(h))
+	for k := range h {
 		keys = append(keys, k)
 	}
 	return keys
@@ -73,6 +112,57 @@ func (h headerCarrier) Values(key string) []string {
 	return h[key]
 }
 
+type requestHeaderCarrier struct {
+	*fasthttp.RequestHeader
+}
+
+func (h *requestHeaderCarrier) Get(key string) string {
+	return string(h.Peek(key))
+}
+
+func (h *requestHeaderCarrier) Keys() []string {
+	return visitKeys(h.VisitAll)
+}
+
+func (h *requestHeaderCarrier) Values(key string) []string {
+	return stringValues(h.PeekAll(key))
+}
+
+type responseHeaderCarrier struct {
+	*fasthttp.ResponseHeader
+}
+
+func (h *responseHeaderCarrier) Get(key string) string {
+	return string(h.Peek(key))
+}
+
+func (h *responseHeaderCarrier) Keys() []string {
+	return visitKeys(h.VisitAll)
+}
+
+func (h *responseHeaderCarrier) Values(key string) []string {
+	return stringValues(h.PeekAll(key))
+}
+
+func visitKeys(visitAll func(func(key, value []byte))) []string {
+	keys := make([]string, 0)
+	visitAll(func(key, _ []byte) {
+		// repeated headers are visited once per value
+		if k := string(key); !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	})
+	return keys
+}
+
+func stringValues(values [][]byte) []string {
+	res := make([]string, len(values))
+	for i, v := range values {
+		res[i] = string(v)
+	}
+	return res
+}
+
 // SetOperation sets the transport operation.
 func SetOperation(ctx context.Context, op string) {
 	if tr, ok := transport.FromServerContext(ctx); ok {
@@ -86,7 +176,7 @@ func SetOperation(ctx context.Context, op string) {
 func RequestFromServerContext(ctx context.Context) (*Ctx, bool) {
 	if tr, ok := transport.FromServerContext(ctx); ok {
 		if tr, ok := tr.(*Transport); ok {
-			return tr.reqCtx, true
+			return tr.reqCtx, tr.reqCtx != nil
 		}
 	}
 	return nil, false
@@ -0,0 +1,47 @@
+package tgin
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type nestedData struct {
+	Message struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"message"`
+	Tags []string `json:"tags"`
+	Body string   `json:"body"`
+}
+
+func TestBind(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("POST", "/messages/7?message.name=foo&tags=a&tags=b", strings.NewReader(`{"body":"body"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{{Key: "message.id", Value: "7"}}
+	var in nestedData
+	if err := BindBody(ctx, &in); err != nil {
+		t.Fatal(err)
+	}
+	if err := BindQuery(ctx, &in); err != nil {
+		t.Fatal(err)
+	}
+	if err := BindPath(ctx, &in); err != nil {
+		t.Fatal(err)
+	}
+	if in.Message.ID != 7 || in.Message.Name != "foo" || !slices.Equal(in.Tags, []string{"a", "b"}) || in.Body != "body" {
+		t.Errorf("unexpected binding %+v", in)
+	}
+
+	ctx.Params = gin.Params{{Key: "message.id", Value: "seven"}}
+	if err := BindPath(ctx, &in); err == nil {
+		t.Error("expected an error for a malformed path var")
+	}
+	ctx.Request.Header.Set("Content-Type", "application/unknown")
+	if err := BindBody(ctx, &in); err == nil {
+		t.Error("expected an error for an unknown content type")
+	}
+}
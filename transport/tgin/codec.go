@@ -0,0 +1,54 @@
+package tgin
+
+import (
+	"github.com/LiangQinghai/kratos-ext/pkg/httputil"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"net/http"
+)
+
+// EncodeResponseFunc is encode response func.
+type EncodeResponseFunc = func(ctx *Ctx, v any) error
+
+// EncodeErrorFunc is encode error func.
+type EncodeErrorFunc func(ctx *Ctx, err error)
+
+// DefaultErrorEncoder encodes the error to the HTTP response.
+func DefaultErrorEncoder(ctx *Ctx, err error) {
+	if ctx.Writer.Written() {
+		return
+	}
+	se := errors.FromError(err)
+	codec, _ := CodecForRequest(ctx, "Accept")
+	body, err := codec.Marshal(se)
+	if err != nil {
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	ctx.Data(int(se.Code), httputil.ContentType(codec.Name()), body)
+}
+
+// CodecForRequest get encoding.Codec via http.Request
+func CodecForRequest(ctx *Ctx, name string) (encoding.Codec, bool) {
+	for _, accept := range ctx.Request.Header[name] {
+		codec := encoding.GetCodec(httputil.ContentSubtype(accept))
+		if codec != nil {
+			return codec, true
+		}
+	}
+	return encoding.GetCodec("json"), false
+}
+
+// DefaultResponseEncoder encodes the object to the HTTP response.
+func DefaultResponseEncoder(ctx *Ctx, v any) error {
+	if v == nil {
+		return nil
+	}
+	codec, _ := CodecForRequest(ctx, "Accept")
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	ctx.Data(http.StatusOK, httputil.ContentType(codec.Name()), data)
+	return nil
+}
@@ -0,0 +1,5 @@
+package tgin
+
+import "github.com/gin-gonic/gin"
+
+type Ctx = gin.Context
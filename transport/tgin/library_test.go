@@ -0,0 +1,28 @@
+package tgin_test
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	"github.com/LiangQinghai/kratos-ext/transport/tgin"
+	"github.com/LiangQinghai/kratos-ext/transport/tgin/internal/library"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPatternVars(t *testing.T) {
+	ctx := context.Background()
+	srv := tgin.NewServer(tgin.Address("127.0.0.1:18111"))
+	library.RegisterLibraryGinServer(srv, testutil.LibraryServer{})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	testutil.CheckLibrary(t, testutil.LibraryGet(http.DefaultClient, "http://127.0.0.1:18111"))
+}
@@ -0,0 +1,289 @@
+package tgin
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
+	"github.com/LiangQinghai/kratos-ext/pkg/host"
+	"github.com/LiangQinghai/kratos-ext/pkg/matcher"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
+	"github.com/gin-gonic/gin"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var (
+	_ transport.Server     = (*Server)(nil)
+	_ transport.Endpointer = (*Server)(nil)
+)
+
+// ServerOption is a gin framework option
+type ServerOption func(*Server)
+
+// Network set network
+func Network(network string) ServerOption {
+	return func(s *Server) {
+		s.network = network
+	}
+}
+
+// Address with address
+func Address(address string) ServerOption {
+	return func(s *Server) {
+		s.address = address
+	}
+}
+
+// Listener serves on lis in place of the network and address.
+func Listener(lis net.Listener) ServerOption {
+	return func(s *Server) {
+		s.lis = lis
+		s.network = lis.Addr().Network()
+		s.address = lis.Addr().String()
+	}
+}
+
+// TLSConfig with the tls config of the gin server.
+func TLSConfig(c *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tlsConf = c
+	}
+}
+
+// Endpoint with endpoint
+func Endpoint(endpoint *url.URL) ServerOption {
+	return func(s *Server) {
+		s.endpoint = endpoint
+	}
+}
+
+// Middleware with service middleware option.
+func Middleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.middleware.Use(m...)
+	}
+}
+
+// ResponseEncoder with response encoder.
+func ResponseEncoder(en EncodeResponseFunc) ServerOption {
+	return func(s *Server) {
+		s.enc = en
+	}
+}
+
+// ErrorEncoder with error encoder.
+func ErrorEncoder(en EncodeErrorFunc) ServerOption {
+	return func(s *Server) {
+		s.ene = en
+	}
+}
+
+// RawMiddleware gin mid
+func RawMiddleware(m ...gin.HandlerFunc) ServerOption {
+	return func(s *Server) {
+		s.rawMid = append(s.rawMid, m...)
+	}
+}
+
+// Timeout with the timeout of the requests, zero disables it. A client may shorten
+// it with a Grpc-Timeout or X-Request-Timeout header.
+func Timeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = d
+	}
+}
+
+// OperationTimeout with the timeout of the operations matching selector in place of the server
+// timeout, zero disables it. Selectors are matched as middleware selectors, e.g. /helloworld.Greeter/*.
+func OperationTimeout(selector string, d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeouts.Add(selector, d)
+	}
+}
+
+// StreamHeartbeat with the heartbeat interval of server-sent event streams, zero disables it.
+func StreamHeartbeat(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.heartbeat = d
+	}
+}
+
+func NewServer(opts ...ServerOption) *Server {
+	srv := &Server{
+		network:    "tcp",
+		address:    ":0",
+		middleware: matcher.New(),
+		enc:        DefaultResponseEncoder,
+		ene:        DefaultErrorEncoder,
+		timeout:    3 * time.Second,
+		timeouts:   timeout.New(),
+		heartbeat:  15 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	srv.app = gin.New()
+	srv.app.HandleMethodNotAllowed = true
+	srv.app.Use(srv.errorMid(), gin.CustomRecovery(func(ctx *gin.Context, rec any) {
+		err, ok := rec.(error)
+		if !ok {
+			err = fmt.Errorf("%v", rec)
+		}
+		srv.ene(ctx, err)
+	}))
+	srv.app.Use(srv.transportMid())
+	srv.app.Use(srv.rawMid...)
+	srv.app.NoRoute(func(ctx *gin.Context) {
+		_ = ctx.Error(errors.NotFound(http.StatusText(http.StatusNotFound), http.StatusText(http.StatusNotFound)))
+	})
+	srv.app.NoMethod(func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), http.StatusText(http.StatusMethodNotAllowed)))
+	})
+	srv.server = &http.Server{Handler: srv.app, TLSConfig: srv.tlsConf}
+	return srv
+}
+
+type Server struct {
+	app        *gin.Engine
+	server     *http.Server
+	lis        net.Listener
+	tlsConf    *tls.Config
+	endpoint   *url.URL
+	err        error
+	network    string
+	address    string
+	timeout    time.Duration
+	timeouts   timeout.Matcher
+	heartbeat  time.Duration
+	middleware matcher.Matcher
+	rawMid     []gin.HandlerFunc
+	enc        EncodeResponseFunc
+	ene        EncodeErrorFunc
+}
+
+func (s *Server) Start(_ context.Context) error {
+	err := s.initEndpoint()
+	if err != nil {
+		return err
+	}
+	log.Infof("[gin] server listening on %s", s.endpoint.String())
+	lis := s.lis
+	if s.tlsConf != nil {
+		lis = tls.NewListener(lis, s.tlsConf)
+	}
+	if err = s.server.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) Endpoint() (*url.URL, error) {
+	err := s.initEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	return s.endpoint, nil
+}
+
+// Middleware mid handler
+// m: middleware.Handler kratos middleware
+// ctx: context.Context
+// path: router path
+// returns: middleware.Handler
+func (s *Server) Middleware(m middleware.Handler, ctx context.Context, path string) middleware.Handler {
+	operation := path
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		operation = tr.Operation()
+	}
+	return timeout.Handler(s.timeouts, operation, middleware.Chain(s.middleware.Match(operation)...)(m))
+}
+
+// Group router group, the routes are served with the kratos transport
+// returns: *gin.RouterGroup
+func (s *Server) Group(prefix string, h ...Handler) *gin.RouterGroup {
+	return s.app.Group(prefix, h...)
+}
+
+// Router the gin router, the routes are served with the kratos transport
+// returns: *gin.Engine
+func (s *Server) Router() *gin.Engine {
+	return s.app
+}
+
+// Write response data encode, a failure is recorded as the error of ctx
+func (s *Server) Write(ctx *Ctx, v any) {
+	if err := s.enc(ctx, v); err != nil {
+		_ = ctx.Error(err)
+	}
+}
+
+func (s *Server) initEndpoint() error {
+	if s.lis == nil {
+		lis, err := net.Listen(s.network, s.address)
+		if err != nil {
+			s.err = err
+			return err
+		}
+		s.lis = lis
+	}
+	if s.endpoint == nil {
+		scheme := endpoint.Scheme("http", s.tlsConf != nil)
+		if path, ok := host.Unix(s.lis); ok {
+			s.endpoint = endpoint.NewUnixEndpoint(scheme, path)
+			return s.err
+		}
+		addr, err := host.ExtractFromLis(s.address, s.lis)
+		if err != nil {
+			s.err = err
+			return err
+		}
+		s.endpoint = endpoint.NewEndpoint(scheme, addr)
+	}
+	return s.err
+}
+
+// errorMid encodes the last error recorded by the handlers with ctx.Error.
+func (s *Server) errorMid() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+		if err := ctx.Errors.Last(); err != nil {
+			s.ene(ctx, err.Err)
+		}
+	}
+}
+
+func (s *Server) transportMid() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+		tr := Transport{
+			endpoint:     s.endpoint.String(),
+			operation:    c.FullPath(),
+			pathTemplate: c.FullPath(),
+			reqHeader:    headerCarrier(c.Request.Header),
+			replyHeader:  headerCarrier(c.Writer.Header()),
+			reqCtx:       c,
+		}
+		if d := timeout.Budget(s.timeout, c.Request.Header); d > 0 {
+			ctx, cancel = context.WithTimeout(c.Request.Context(), d)
+		} else {
+			ctx, cancel = context.WithCancel(c.Request.Context())
+		}
+		defer cancel()
+		c.Request = c.Request.WithContext(transport.NewServerContext(ctx, &tr))
+		c.Next()
+	}
+}
@@ -0,0 +1,342 @@
+package tgin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"io"
+	"net"
+	http2 "net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testData struct {
+	Path string `json:"path"`
+}
+
+type bindData struct {
+	Path  string `json:"path"`
+	Query string `json:"query"`
+	Body  string `json:"body"`
+}
+
+// newBindHandler binds the request as protoc-gen-go-gin generates it.
+func newBindHandler(srv *Server) Handler {
+	return func(ctx *Ctx) {
+		var in bindData
+		if err := BindBody(ctx, &in); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		if err := BindQuery(ctx, &in); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		if err := BindPath(ctx, &in); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		c := ctx.Request.Context()
+		SetOperation(c, "/helloworld.Greeter/Bind")
+		h := srv.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		}, c, ctx.FullPath())
+		out, err := h(c, &in)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		srv.Write(ctx, out)
+	}
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	var seen []string
+	srv := NewServer(Middleware(func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, errors.New("missing transport")
+			}
+			seen = []string{string(tr.Kind()), tr.Operation(), tr.(*Transport).PathTemplate(), tr.RequestHeader().Get("X-Md-Foo")}
+			tr.ReplyHeader().Set("X-Md-Reply", "bar")
+			return handler(ctx, req)
+		}
+	}))
+	srv.Router().POST("/bind/:path", newBindHandler(srv))
+	srv.Group("/errors").GET("/cause", func(ctx *Ctx) {
+		_ = ctx.Error(kratoserrors.BadRequest(
+			"xxx",
+			"zzz",
+		).WithMetadata(map[string]string{"foo": "bar"}).
+			WithCause(errors.New("error cause")))
+	})
+	srv.Group("/errors").GET("/panic", func(ctx *Ctx) {
+		panic(kratoserrors.Conflict("yyy", "zzz"))
+	})
+	e, err := srv.Endpoint()
+	if err != nil || e == nil || strings.HasSuffix(e.Host, ":0") {
+		t.Fatal(e, err)
+	}
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	client, err := http.NewClient(ctx, http.WithEndpoint(e.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	req, _ := http2.NewRequest(http2.MethodPost, e.String()+"/bind/path?query=query", strings.NewReader(`{"body":"body"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Md-Foo", "foo")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res bindData
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	_ = resp.Body.Close()
+	if err != nil || res.Path != "path" || res.Query != "query" || res.Body != "body" {
+		t.Errorf("expected %s, %s, %s got %v %v", "path", "query", "body", res, err)
+	}
+	if md := resp.Header.Get("X-Md-Reply"); md != "bar" {
+		t.Errorf("expected %s got %s", "bar", md)
+	}
+	want := []string{"gin", "/helloworld.Greeter/Bind", "/bind/:path", "foo"}
+	if strings.Join(seen, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v got %v", want, seen)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		reason string
+	}{
+		{http2.MethodGet, "/errors/cause", http2.StatusBadRequest, "xxx"},
+		{http2.MethodGet, "/errors/panic", http2.StatusConflict, "yyy"},
+		{http2.MethodGet, "/not/found", http2.StatusNotFound, "Not Found"},
+		{http2.MethodGet, "/bind/path", http2.StatusMethodNotAllowed, "Method Not Allowed"},
+		// no body is sent without a Content-Type
+		{http2.MethodPost, "/bind/path", http2.StatusBadRequest, "CODEC"},
+	}
+	for _, test := range tests {
+		err := client.Invoke(ctx, test.method, test.path, nil, &res)
+		if se := kratoserrors.FromError(err); se.Code != int32(test.code) || se.Reason != test.reason {
+			t.Errorf("%s %s: expected %d %s got %v", test.method, test.path, test.code, test.reason, err)
+		}
+	}
+}
+
+func TestServerStream(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:18102"), StreamHeartbeat(50*time.Millisecond))
+	closed := make(chan error, 1)
+	srv.Router().GET("/stream/:n", func(ctx *Ctx) {
+		n := ctx.Param("n")
+		stream := srv.NewStream(ctx)
+		err := srv.ServeStream(ctx, stream, func() error {
+			if n == "forever" {
+				for {
+					if err := stream.Send(&testData{Path: n}); err != nil {
+						closed <- err
+						return err
+					}
+				}
+			}
+			if n == "0" {
+				return kratoserrors.BadRequest("BAD", "bad")
+			}
+			for i := 0; i < 2; i++ {
+				if err := stream.Send(&testData{Path: n}); err != nil {
+					return err
+				}
+			}
+			return kratoserrors.Conflict("CONFLICT", "conflict")
+		})
+		if err != nil {
+			_ = ctx.Error(err)
+		}
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	req, _ := http2.NewRequest(http2.MethodGet, "http://127.0.0.1:18102/stream/1", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Last-Event-ID", "5")
+	resp, err := http2.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected %s got %s", "text/event-stream", ct)
+	}
+	want := "id: 6\ndata: {\"path\":\"1\"}\n\nid: 7\ndata: {\"path\":\"1\"}\n\nevent: error\ndata: "
+	if !strings.HasPrefix(string(content), want) || !strings.Contains(string(content), `"reason":"CONFLICT"`) {
+		t.Errorf("unexpected stream %q", content)
+	}
+
+	resp, err = http2.Get("http://127.0.0.1:18102/stream/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http2.StatusBadRequest {
+		t.Errorf("expected %d got %d", http2.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp, err = http2.Get("http://127.0.0.1:18102/stream/forever")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = resp.Body.Read(make([]byte, 64))
+	_ = resp.Body.Close()
+	select {
+	case err = <-closed:
+		if err != context.Canceled {
+			t.Errorf("expected %v got %v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the stream to stop once the client disconnects")
+	}
+}
+
+func TestServerTimeout(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(
+		Address("127.0.0.1:18103"),
+		Timeout(100*time.Millisecond),
+		OperationTimeout("/slow/*", time.Second),
+	)
+	srv.Router().GET("/wait/:op", func(ctx *Ctx) {
+		c := ctx.Request.Context()
+		SetOperation(c, "/"+ctx.Param("op")+"/wait")
+		h := srv.Middleware(func(ctx context.Context, _ interface{}) (interface{}, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(300 * time.Millisecond):
+				return &testData{Path: "done"}, nil
+			}
+		}, c, ctx.FullPath())
+		out, err := h(c, nil)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		srv.Write(ctx, out)
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	tests := []struct {
+		name   string
+		op     string
+		header map[string]string
+		code   int
+	}{
+		{"server", "fast", nil, http2.StatusGatewayTimeout},
+		{"operation", "slow", nil, http2.StatusOK},
+		{"grpc", "slow", map[string]string{"Grpc-Timeout": "50m"}, http2.StatusGatewayTimeout},
+		{"request", "slow", map[string]string{"X-Request-Timeout": "0.05"}, http2.StatusGatewayTimeout},
+		{"longer", "fast", map[string]string{"X-Request-Timeout": "10s"}, http2.StatusGatewayTimeout},
+	}
+	for _, test := range tests {
+		req, _ := http2.NewRequest(http2.MethodGet, "http://127.0.0.1:18103/wait/"+test.op, nil)
+		for k, v := range test.header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http2.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != test.code {
+			t.Errorf("%s: expected %d got %d %s", test.name, test.code, resp.StatusCode, body)
+		}
+		if test.code == http2.StatusGatewayTimeout && !strings.Contains(string(body), "DEADLINE_EXCEEDED") {
+			t.Errorf("%s: unexpected body %s", test.name, body)
+		}
+	}
+}
+
+func TestServerListener(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "app.sock")
+	srv := NewServer(Network("unix"), Address(path))
+	srv.Router().GET("/ping", func(ctx *Ctx) {
+		srv.Write(ctx, &testData{Path: "pong"})
+	})
+	ept, err := srv.Endpoint()
+	if err != nil || ept.String() != "http+unix://"+path {
+		t.Errorf("expected %s got %v %v", "http+unix://"+path, ept, err)
+	}
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	client := &http2.Client{Transport: &http2.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var data testData
+	if err = json.NewDecoder(resp.Body).Decode(&data); err != nil || data.Path != "pong" {
+		t.Errorf("expected %s got %v %v", "pong", data, err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	if ept, err = NewServer(Listener(lis)).Endpoint(); err != nil || ept.Host != lis.Addr().String() {
+		t.Errorf("expected %s got %v %v", lis.Addr(), ept, err)
+	}
+}
@@ -0,0 +1,46 @@
+package tgin
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/pkg/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/go-kratos/kratos/v2/log"
+	"net/http"
+)
+
+// Stream is the server-sent event stream of a server-streaming rpc.
+type Stream = sse.Stream
+
+// NewStream returns the event stream of the request, messages are encoded with the codec
+// of the Accept header. The stream is not bound by the server timeout, it lasts until the
+// handler returns or the client disconnects.
+func (s *Server) NewStream(ctx *Ctx) *Stream {
+	codec := sse.CodecForAccept(ctx.Request.Header.Values("Accept")...)
+	lastEventID := ctx.GetHeader(sse.LastEventIDHeader)
+	return sse.NewStream(context.WithoutCancel(ctx.Request.Context()), codec, lastEventID, s.heartbeat)
+}
+
+// ServeStream runs handler and writes the stream as the response. The handler error is
+// returned if it fails before sending any event, later errors are sent as an error event.
+func (s *Server) ServeStream(ctx *Ctx, stream *Stream, handler func() error) error {
+	if err := stream.Run(handler); err != nil {
+		return err
+	}
+	ctx.Header("Content-Type", sse.ContentType)
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Status(http.StatusOK)
+	if err := stream.WriteTo(&flushWriter{ctx.Writer}); err != nil {
+		log.Debugf("[gin] event stream closed: %v", err)
+	}
+	return nil
+}
+
+// flushWriter flushes the events of a stream to the client.
+type flushWriter struct {
+	gin.ResponseWriter
+}
+
+func (w *flushWriter) Flush() error {
+	w.ResponseWriter.Flush()
+	return nil
+}
@@ -0,0 +1,93 @@
+package tgin
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/transport"
+	"net/http"
+)
+
+const (
+	KindGin transport.Kind = "gin"
+	// SupportPackageIsVersion1 These constants should not be referenced from any other code.
+	SupportPackageIsVersion1 = true
+)
+
+type Transport struct {
+	endpoint     string
+	operation    string
+	pathTemplate string
+	reqHeader    headerCarrier
+	replyHeader  headerCarrier
+	reqCtx       *Ctx
+}
+
+func (t *Transport) Kind() transport.Kind {
+	return KindGin
+}
+
+func (t *Transport) Endpoint() string {
+	return t.endpoint
+}
+
+func (t *Transport) Operation() string {
+	return t.operation
+}
+
+func (t *Transport) RequestHeader() transport.Header {
+	return t.reqHeader
+}
+
+func (t *Transport) ReplyHeader() transport.Header {
+	return t.replyHeader
+}
+
+// PathTemplate returns the http path template.
+func (t *Transport) PathTemplate() string {
+	return t.pathTemplate
+}
+
+// header
+type headerCarrier http.Header
+
+func (h headerCarrier) Get(key string) string {
+	return http.Header(h).Get(key)
+}
+
+func (h headerCarrier) Set(key string, value string) {
+	http.Header(h).Set(key, value)
+}
+
+func (h headerCarrier) Add(key string, value string) {
+	http.Header(h).Add(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range http.Header(h) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (h headerCarrier) Values(key string) []string {
+	return http.Header(h).Values(key)
+}
+
+// SetOperation sets the transport operation.
+func SetOperation(ctx context.Context, op string) {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if tr, ok := tr.(*Transport); ok {
+			tr.operation = op
+		}
+	}
+}
+
+// RequestFromServerContext returns request from context.
+func RequestFromServerContext(ctx context.Context) (*Ctx, bool) {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if tr, ok := tr.(*Transport); ok {
+			return tr.reqCtx, true
+		}
+	}
+	return nil, false
+}
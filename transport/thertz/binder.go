@@ -2,11 +2,14 @@ package thertz
 
 import (
 	"context"
+	"fmt"
+	pkgbinding "github.com/LiangQinghai/kratos-ext/pkg/binding"
 	"github.com/LiangQinghai/kratos-ext/transport/thertz/internal/schema"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server/binding"
 	"github.com/cloudwego/hertz/pkg/protocol"
 	"github.com/cloudwego/hertz/pkg/route/param"
+	"github.com/go-kratos/kratos/v2/errors"
 )
 
 var thertzBinderInstance = newThertzBinder()
@@ -19,7 +22,40 @@ func binderMid() Handler {
 	}
 }
 
-func newThertzBinder() binding.Binder {
+// EncodeURL encode proto message to url path, the fields which are neither
+// bound to path vars nor listed in excludes are encoded as query params when needQuery is true.
+func EncodeURL(pathTemplate string, msg interface{}, needQuery bool, excludes ...string) string {
+	return pkgbinding.EncodeURL(pathTemplate, msg, needQuery, excludes...)
+}
+
+// BindBody decodes the request body into v with the codec of the Content-Type header,
+// it binds a body field whose message the default binder of hertz can't allocate.
+func BindBody(ctx *ReqCtx, v interface{}) error {
+	codec, ok := CodecForRequest(ctx, "Content-Type")
+	if !ok {
+		return errors.BadRequest("CODEC", fmt.Sprintf("unregister Content-Type: %s", ctx.ContentType()))
+	}
+	data := ctx.Request.Body()
+	if len(data) == 0 {
+		return nil
+	}
+	if err := codec.Unmarshal(data, v); err != nil {
+		return errors.BadRequest("CODEC", fmt.Sprintf("body unmarshal %s", err.Error()))
+	}
+	return nil
+}
+
+// BindPattern decodes the path vars into v by matching the request path with the
+// google.api.http path pattern, a {var=pattern} var keeps every segment it matches.
+func BindPattern(ctx *ReqCtx, pattern string, v interface{}) error {
+	vars, err := pkgbinding.PathVars(pattern, string(ctx.Request.URI().PathOriginal()))
+	if err != nil {
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	return thertzBinderInstance.decoder.Decode(v, vars)
+}
+
+func newThertzBinder() *thertzBinder {
 	decoder := schema.NewDecoder()
 	decoder.SetAliasTag("json")
 	return &thertzBinder{
@@ -0,0 +1,155 @@
+package thertz
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/random"
+	"github.com/go-kratos/kratos/v2/transport"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:18081"))
+	srv.Router().GET("/client/:path", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Response.Header.Set("X-Reply", "reply")
+		ctx.JSON(200, testData{Path: ctx.Param("path") + string(ctx.QueryArgs().Peek("query"))})
+	})
+	srv.Router().POST("/client/body", newBindHandler())
+	srv.Router().GET("/client/error", func(c context.Context, ctx *app.RequestContext) {
+		panic(kratoserrors.BadRequest("BAD", "bad request"))
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	var operation string
+	client, err := NewClient(ctx,
+		WithEndpoint("127.0.0.1:18081"),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tr, ok := transport.FromClientContext(ctx); ok {
+					operation = tr.Operation()
+					tr.RequestHeader().Set("X-Request", "request")
+				}
+				return handler(ctx, req)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var res testData
+	header := &protocol.ResponseHeader{}
+	err = client.Invoke(ctx, http.MethodGet, "/client/path?query=query", nil, &res,
+		Operation("/test/Get"), Header(header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Path != "pathquery" {
+		t.Errorf("expected %s got %s", "pathquery", res.Path)
+	}
+	if operation != "/test/Get" {
+		t.Errorf("expected %s got %s", "/test/Get", operation)
+	}
+	if header.Get("X-Reply") != "reply" {
+		t.Errorf("expected %s got %s", "reply", header.Get("X-Reply"))
+	}
+
+	var bind bindData
+	err = client.Invoke(ctx, http.MethodPost, "/client/body", &bindData{Body: "body"}, &bind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bind.Body != "body" {
+		t.Errorf("expected %s got %s", "body", bind.Body)
+	}
+
+	err = client.Invoke(ctx, http.MethodGet, "/client/error", nil, &res)
+	if kratoserrors.Code(err) != http.StatusBadRequest || kratoserrors.Reason(err) != "BAD" {
+		t.Errorf("expected 400 BAD got %v", err)
+	}
+}
+
+func TestClientDiscovery(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:18082"))
+	srv.Router().GET("/discovery", newHandleFuncWrapper())
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	d := testutil.NewDiscovery(
+		&registry.ServiceInstance{ID: "1", Name: "thertz", Version: "v1", Endpoints: []string{"http://127.0.0.1:18082"}},
+		&registry.ServiceInstance{ID: "2", Name: "thertz", Version: "v2", Endpoints: []string{"grpc://127.0.0.1:19082"}},
+	)
+	var peer string
+	client, err := NewClient(ctx,
+		WithEndpoint("discovery:///thertz"),
+		WithDiscovery(d),
+		WithSelector(random.NewBuilder()),
+		WithBlock(),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				reply, err := handler(ctx, req)
+				if p, ok := selector.FromPeerContext(ctx); ok && p.Node != nil {
+					peer = p.Node.Address()
+				}
+				return reply, err
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var res testData
+	if err = client.Invoke(ctx, http.MethodGet, "/discovery", nil, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Path != "/discovery" {
+		t.Errorf("expected %s got %s", "/discovery", res.Path)
+	}
+	if peer != "127.0.0.1:18082" {
+		t.Errorf("expected %s got %s", "127.0.0.1:18082", peer)
+	}
+
+	filtered, err := NewClient(ctx,
+		WithEndpoint("discovery:///thertz"),
+		WithDiscovery(d),
+		WithBlock(),
+		WithNodeFilter(func(_ context.Context, nodes []selector.Node) []selector.Node {
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer filtered.Close()
+	err = filtered.Invoke(ctx, http.MethodGet, "/discovery", nil, &res)
+	if kratoserrors.Code(err) != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package thertz
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/suite"
+	h2config "github.com/hertz-contrib/http2/config"
+	"golang.org/x/net/http2"
+	"io"
+	"net/http"
+)
+
+// h2Factory serves HTTP/2 with golang.org/x/net/http2. Its requests keep every value of
+// a repeated header, as the HTTP/1.1 server of hertz does.
+type h2Factory struct {
+	conf *h2config.Config
+}
+
+func newH2Factory(opts ...h2config.Option) suite.ServerFactory {
+	return &h2Factory{conf: h2config.NewConfig(opts...)}
+}
+
+// New is called by hertz once the engine runs.
+func (f *h2Factory) New(core suite.Core) (protocol.Server, error) {
+	base := &http.Server{ReadTimeout: f.conf.ReadTimeout}
+	base.SetKeepAlivesEnabled(!f.conf.DisableKeepalive)
+	return &h2Server{
+		core: core,
+		base: base,
+		srv: &http2.Server{
+			MaxConcurrentStreams:         f.conf.MaxConcurrentStreams,
+			MaxReadFrameSize:             f.conf.MaxReadFrameSize,
+			PermitProhibitedCipherSuites: f.conf.PermitProhibitedCipherSuites,
+			IdleTimeout:                  f.conf.IdleTimeout,
+			MaxUploadBufferPerConnection: f.conf.MaxUploadBufferPerConnection,
+			MaxUploadBufferPerStream:     f.conf.MaxUploadBufferPerStream,
+		},
+	}, nil
+}
+
+type h2Server struct {
+	core suite.Core
+	base *http.Server
+	srv  *http2.Server
+}
+
+// Serve serves the HTTP/2 connection, its preface has only been peeked by hertz.
+func (s *h2Server) Serve(c context.Context, conn network.Conn) error {
+	s.srv.ServeConn(conn, &http2.ServeConnOpts{
+		Context:    c,
+		BaseConfig: s.base,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.serveHTTP(conn, w, r)
+		}),
+	})
+	return nil
+}
+
+// serveHTTP runs the hertz handlers of the request on a request context of the engine.
+func (s *h2Server) serveHTTP(conn network.Conn, w http.ResponseWriter, r *http.Request) {
+	pool := s.core.GetCtxPool()
+	ctx := pool.Get().(*app.RequestContext)
+	defer func() {
+		ctx.Reset()
+		pool.Put(ctx)
+	}()
+	ctx.SetConn(&h2Conn{Conn: conn, w: w})
+	req := &ctx.Request
+	req.Header.SetProtocol(consts.HTTP20)
+	req.SetMethod(r.Method)
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	req.SetRequestURI(scheme + "://" + r.Host + r.RequestURI)
+	req.Header.SetHost(r.Host)
+	for key, values := range r.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.SetBodyStream(r.Body, int(r.ContentLength))
+
+	s.core.ServeHTTP(r.Context(), ctx)
+
+	if hw := ctx.Response.GetHijackWriter(); hw != nil {
+		_ = hw.Finalize()
+		return
+	}
+	writeH2Header(w, &ctx.Response)
+	if ctx.Response.IsBodyStream() {
+		_, _ = io.Copy(w, ctx.Response.BodyStream())
+		return
+	}
+	_, _ = w.Write(ctx.Response.Body())
+}
+
+// writeH2Header writes the header of resp, the connection specific headers are left to HTTP/2.
+func writeH2Header(w http.ResponseWriter, resp *protocol.Response) {
+	resp.Header.VisitAll(func(key, value []byte) {
+		switch k := string(key); k {
+		case consts.HeaderContentLength, consts.HeaderConnection, consts.HeaderTransferEncoding, consts.HeaderTrailer:
+		default:
+			w.Header().Add(k, string(value))
+		}
+	})
+	w.WriteHeader(resp.StatusCode())
+}
+
+// h2Conn is the conn of an HTTP/2 request, its response is written to w.
+type h2Conn struct {
+	network.Conn
+	w http.ResponseWriter
+}
+
+// h2Writer streams the response in data frames, it is the hijack writer of server-sent events.
+type h2Writer struct {
+	resp  *protocol.Response
+	w     http.ResponseWriter
+	wrote bool
+}
+
+func (w *h2Writer) Write(p []byte) (int, error) {
+	w.writeHeader()
+	return w.w.Write(p)
+}
+
+func (w *h2Writer) Flush() error {
+	w.writeHeader()
+	w.w.(http.Flusher).Flush()
+	return nil
+}
+
+// Finalize is called once the handlers return, it is safe to call several times.
+func (w *h2Writer) Finalize() error {
+	return w.Flush()
+}
+
+func (w *h2Writer) writeHeader() {
+	if !w.wrote {
+		w.wrote = true
+		writeH2Header(w.w, w.resp)
+	}
+}
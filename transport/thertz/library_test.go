@@ -0,0 +1,176 @@
+package thertz_test
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	"github.com/LiangQinghai/kratos-ext/transport/thertz"
+	"github.com/LiangQinghai/kratos-ext/transport/thertz/internal/library"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"golang.org/x/net/http2"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// libraryServer adds the methods of the thertz Library to the shared one.
+type libraryServer struct {
+	testutil.LibraryServer
+}
+
+func (libraryServer) CreateBook(_ context.Context, in *testutil.CreateBookRequest) (*testutil.Book, error) {
+	return &testutil.Book{Name: in.Parent + "/books/" + in.GetBook().GetName()}, nil
+}
+
+func (libraryServer) WatchBooks(in *testutil.ListBooksRequest, stream library.Library_WatchBooksHertzServer) error {
+	for i := 0; i < 2; i++ {
+		if err := stream.Send(&testutil.Book{Name: in.Parent}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestPatternVars(t *testing.T) {
+	ctx := context.Background()
+	srv := thertz.NewServer(thertz.Address("127.0.0.1:18110"))
+	library.RegisterLibraryHertzServer(srv, libraryServer{})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	cc, err := thertz.NewClient(ctx, thertz.WithEndpoint("127.0.0.1:18110"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	client := library.NewLibraryHertzClient(cc)
+	testutil.CheckLibrary(t, testutil.LibraryClient(ctx, client))
+	book, err := client.CreateBook(ctx, &testutil.CreateBookRequest{Parent: "shelves/1", Book: &testutil.Book{Name: "a"}})
+	if err != nil || book.Name != "shelves/1/books/a" {
+		t.Errorf("expected %s got %v %v", "shelves/1/books/a", book, err)
+	}
+}
+
+// headerMid echoes the request headers seen through the transport.
+func headerMid(handler middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		tr, _ := transport.FromServerContext(ctx)
+		tr.ReplyHeader().Set("X-Host", tr.RequestHeader().Get("Host"))
+		tr.ReplyHeader().Set("X-Keys", strings.Join(tr.RequestHeader().Keys(), ","))
+		tr.ReplyHeader().Set("X-Values", strings.Join(tr.RequestHeader().Values("X-Md-Foo"), ","))
+		tr.ReplyHeader().Set("X-Operation", tr.Operation())
+		return handler(ctx, req)
+	}
+}
+
+func TestServerHTTP2(t *testing.T) {
+	ctx := context.Background()
+	serverCert, serverPool := testutil.Certificate(t, "foo.test")
+	if _, err := thertz.NewServer(thertz.Address("127.0.0.1:18087"), thertz.HTTP2()).Endpoint(); err == nil {
+		t.Error("expected HTTP2 to require TLSConfig")
+	}
+	h2cSrv := thertz.NewServer(thertz.Address("127.0.0.1:18087"), thertz.H2C(), thertz.Middleware(headerMid))
+	tlsSrv := thertz.NewServer(thertz.Address("127.0.0.1:18088"), thertz.HTTP2(), thertz.Middleware(headerMid),
+		thertz.TLSConfig(&tls.Config{Certificates: []tls.Certificate{serverCert}}))
+	for _, srv := range []*thertz.Server{h2cSrv, tlsSrv} {
+		library.RegisterLibraryHertzServer(srv, libraryServer{})
+		go func(srv *thertz.Server) {
+			if err := srv.Start(ctx); err != nil {
+				panic(err)
+			}
+		}(srv)
+	}
+	time.Sleep(time.Second)
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_ = h2cSrv.Stop(stopCtx)
+		_ = tlsSrv.Stop(stopCtx)
+	}()
+
+	h2c := &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}
+	tlsConf := &tls.Config{RootCAs: serverPool, ServerName: "foo.test"}
+	tests := []struct {
+		name   string
+		url    string
+		client *http.Client
+		proto  string
+	}{
+		{"http1", "http://127.0.0.1:18087", &http.Client{}, "HTTP/1.1"},
+		{"h2c", "http://127.0.0.1:18087", &http.Client{Transport: h2c}, "HTTP/2.0"},
+		{"https1", "https://127.0.0.1:18088", &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}, "HTTP/1.1"},
+		{"h2", "https://127.0.0.1:18088", &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf, ForceAttemptHTTP2: true}}, "HTTP/2.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testutil.CheckLibrary(t, testutil.LibraryGet(test.client, test.url))
+
+			req, _ := http.NewRequest(http.MethodPost, test.url+"/v1/shelves/1/books", strings.NewReader(`{"name":"a"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Md-Foo", "bar")
+			req.Header.Add("X-Md-Foo", "baz")
+			resp, err := test.client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var book testutil.Book
+			err = json.NewDecoder(resp.Body).Decode(&book)
+			_ = resp.Body.Close()
+			if resp.Proto != test.proto {
+				t.Errorf("expected %s got %s", test.proto, resp.Proto)
+			}
+			if err != nil || book.Name != "shelves/1/books/a" {
+				t.Errorf("expected %s got %v %v", "shelves/1/books/a", book.Name, err)
+			}
+			if host := resp.Header.Get("X-Host"); host != req.URL.Host {
+				t.Errorf("expected %s got %s", req.URL.Host, host)
+			}
+			if keys := strings.Split(resp.Header.Get("X-Keys"), ","); !slices.Contains(keys, "Host") || !slices.Contains(keys, "X-Md-Foo") {
+				t.Errorf("expected the Host and X-Md-Foo keys got %v", keys)
+			}
+			if values := resp.Header.Get("X-Values"); values != "bar,baz" {
+				t.Errorf("expected %s got %s", "bar,baz", values)
+			}
+			if op := resp.Header.Get("X-Operation"); op != library.HertzOperationLibraryCreateBook {
+				t.Errorf("expected %s got %s", library.HertzOperationLibraryCreateBook, op)
+			}
+
+			req, _ = http.NewRequest(http.MethodGet, test.url+"/v1/shelves/1/watch", nil)
+			req.Header.Set("Accept", "text/event-stream")
+			resp, err = test.client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Proto != test.proto {
+				t.Errorf("expected %s got %s", test.proto, resp.Proto)
+			}
+			data := "data: {\"name\":\"shelves/1\"}\n\n"
+			if want := "id: 1\n" + data + "id: 2\n" + data; string(content) != want {
+				t.Errorf("expected %q got %q", want, content)
+			}
+		})
+	}
+}
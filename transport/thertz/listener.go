@@ -0,0 +1,67 @@
+//go:build !windows
+
+package thertz
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/network"
+	hnetpoll "github.com/cloudwego/hertz/pkg/network/netpoll"
+	"github.com/cloudwego/netpoll"
+	"net"
+	"sync"
+)
+
+// listenerTransporter serves on a listener opened beforehand, the transporters of hertz
+// listen on their own. It is a tcp or unix listener served with netpoll, which has no tls.
+type listenerTransporter struct {
+	mu        sync.Mutex
+	lis       net.Listener
+	opts      *config.Options
+	eventLoop netpoll.EventLoop
+}
+
+func newListenerTransporter(lis net.Listener) func(*config.Options) network.Transporter {
+	return func(opts *config.Options) network.Transporter {
+		return &listenerTransporter{lis: lis, opts: opts}
+	}
+}
+
+func (t *listenerTransporter) ListenAndServe(onData network.OnData) error {
+	eventLoop, err := netpoll.NewEventLoop(func(ctx context.Context, conn netpoll.Connection) error {
+		return onData(ctx, &hnetpoll.Conn{Conn: conn.(network.Conn)})
+	},
+		netpoll.WithIdleTimeout(t.opts.KeepAliveTimeout),
+		netpoll.WithOnPrepare(func(conn netpoll.Connection) context.Context {
+			_ = conn.SetReadTimeout(t.opts.ReadTimeout)
+			if t.opts.WriteTimeout > 0 {
+				_ = conn.SetWriteTimeout(t.opts.WriteTimeout)
+			}
+			return context.Background()
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.eventLoop = eventLoop
+	t.mu.Unlock()
+	return eventLoop.Serve(t.lis)
+}
+
+func (t *listenerTransporter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	return t.Shutdown(ctx)
+}
+
+// Shutdown stops accepting connections and waits for the open ones until ctx is done.
+func (t *listenerTransporter) Shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	eventLoop := t.eventLoop
+	t.mu.Unlock()
+	if eventLoop == nil {
+		return t.lis.Close()
+	}
+	return eventLoop.Shutdown(ctx)
+}
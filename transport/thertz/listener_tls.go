@@ -0,0 +1,174 @@
+package thertz
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/network"
+	"net"
+	"syscall"
+	"time"
+)
+
+// tlsListenerTransporter serves tls on a listener opened beforehand, it accepts from
+// tls.NewListener as the standard transporter of hertz does from the listener it opens.
+type tlsListenerTransporter struct {
+	lis  net.Listener
+	size int
+}
+
+func newTLSListenerTransporter(lis net.Listener) func(*config.Options) network.Transporter {
+	return func(opts *config.Options) network.Transporter {
+		// the config of the options has the protocols appended by hertz
+		return &tlsListenerTransporter{lis: tls.NewListener(lis, opts.TLS), size: opts.ReadBufferSize}
+	}
+}
+
+func (t *tlsListenerTransporter) ListenAndServe(onData network.OnData) error {
+	for {
+		conn, err := t.lis.Accept()
+		if err != nil {
+			return err
+		}
+		go onData(context.Background(), newTLSConn(conn.(*tls.Conn), t.size))
+	}
+}
+
+func (t *tlsListenerTransporter) Close() error {
+	return t.lis.Close()
+}
+
+// Shutdown stops accepting connections, hertz closes the open ones after their next response.
+func (t *tlsListenerTransporter) Shutdown(context.Context) error {
+	return t.lis.Close()
+}
+
+// tlsConn is the buffered network.Conn of a tls connection, the handshake and the
+// connection state of the tls connection select the protocol negotiated with ALPN.
+type tlsConn struct {
+	*tls.Conn
+	buf  []byte
+	r, w int // the unread bytes are buf[r:w]
+	out  net.Buffers
+}
+
+func newTLSConn(c *tls.Conn, size int) *tlsConn {
+	if size <= 0 {
+		size = 4096
+	}
+	return &tlsConn{Conn: c, buf: make([]byte, size)}
+}
+
+// Peek returns the next n bytes, it reads until they are buffered.
+func (c *tlsConn) Peek(n int) ([]byte, error) {
+	for c.w-c.r < n {
+		if len(c.buf)-c.r < n {
+			// the bytes peeked before are valid until Release, they are left in the old buffer
+			buf := make([]byte, max(n, 2*len(c.buf)))
+			c.w = copy(buf, c.buf[c.r:c.w])
+			c.r = 0
+			c.buf = buf
+		}
+		m, err := c.Conn.Read(c.buf[c.w:])
+		c.w += m
+		if err != nil && c.w-c.r < n {
+			return c.buf[c.r:c.w], err
+		}
+	}
+	return c.buf[c.r : c.r+n], nil
+}
+
+func (c *tlsConn) Skip(n int) error {
+	if _, err := c.Peek(n); err != nil {
+		return err
+	}
+	c.r += n
+	return nil
+}
+
+// Release moves the unread bytes to the head of the buffer.
+func (c *tlsConn) Release() error {
+	c.w = copy(c.buf, c.buf[c.r:c.w])
+	c.r = 0
+	return nil
+}
+
+func (c *tlsConn) Len() int {
+	return c.w - c.r
+}
+
+func (c *tlsConn) ReadByte() (byte, error) {
+	if _, err := c.Peek(1); err != nil {
+		return 0, err
+	}
+	c.r++
+	return c.buf[c.r-1], nil
+}
+
+func (c *tlsConn) ReadBinary(n int) ([]byte, error) {
+	b, err := c.Peek(n)
+	if err != nil {
+		return nil, err
+	}
+	c.r += n
+	return append([]byte(nil), b...), nil
+}
+
+// Read returns the buffered bytes first.
+func (c *tlsConn) Read(b []byte) (int, error) {
+	if c.r < c.w {
+		n := copy(b, c.buf[c.r:c.w])
+		c.r += n
+		return n, nil
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *tlsConn) Malloc(n int) ([]byte, error) {
+	b := make([]byte, n)
+	c.out = append(c.out, b)
+	return b, nil
+}
+
+// WriteBinary buffers b until Flush, so b must not change until then.
+func (c *tlsConn) WriteBinary(b []byte) (int, error) {
+	c.out = append(c.out, b)
+	return len(b), nil
+}
+
+func (c *tlsConn) Flush() error {
+	if len(c.out) == 0 {
+		return nil
+	}
+	_, err := c.out.WriteTo(c.Conn)
+	c.out = nil
+	return err
+}
+
+// Write flushes the buffered bytes before writing b.
+func (c *tlsConn) Write(b []byte) (int, error) {
+	if err := c.Flush(); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(b)
+}
+
+func (c *tlsConn) SetReadTimeout(d time.Duration) error {
+	if d <= 0 {
+		return c.Conn.SetReadDeadline(time.Time{})
+	}
+	return c.Conn.SetReadDeadline(time.Now().Add(d))
+}
+
+func (c *tlsConn) SetWriteTimeout(d time.Duration) error {
+	if d <= 0 {
+		return c.Conn.SetWriteDeadline(time.Time{})
+	}
+	return c.Conn.SetWriteDeadline(time.Now().Add(d))
+}
+
+// HandleSpecificError ignores the connections reset by the client, as the standard transporter does.
+func (c *tlsConn) HandleSpecificError(err error, _ string) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
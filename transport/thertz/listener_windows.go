@@ -0,0 +1,32 @@
+package thertz
+
+import (
+	"context"
+	"errors"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/network"
+	"net"
+)
+
+// listenerTransporter would serve on a listener opened beforehand, netpoll is not available on windows.
+type listenerTransporter struct {
+	lis net.Listener
+}
+
+func newListenerTransporter(lis net.Listener) func(*config.Options) network.Transporter {
+	return func(*config.Options) network.Transporter {
+		return &listenerTransporter{lis: lis}
+	}
+}
+
+func (t *listenerTransporter) ListenAndServe(network.OnData) error {
+	return errors.New("thertz: serving on a listener is not supported on windows")
+}
+
+func (t *listenerTransporter) Close() error {
+	return t.lis.Close()
+}
+
+func (t *listenerTransporter) Shutdown(context.Context) error {
+	return t.lis.Close()
+}
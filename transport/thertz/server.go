@@ -3,18 +3,24 @@ package thertz
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
 	"github.com/LiangQinghai/kratos-ext/pkg/host"
 	"github.com/LiangQinghai/kratos-ext/pkg/matcher"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/middlewares/server/recovery"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/protocol/suite"
 	"github.com/cloudwego/hertz/pkg/route"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
+	h2config "github.com/hertz-contrib/http2/config"
+	"github.com/hertz-contrib/websocket"
 	"net"
 	"net/url"
+	"slices"
 	"time"
 )
 
@@ -40,6 +46,43 @@ func Address(address string) ServerOption {
 	}
 }
 
+// Listener with a listener opened beforehand, such as a unix socket or a socket activated by systemd,
+// in place of the network and address. It must be a tcp or unix listener, served with tls along with TLSConfig.
+func Listener(lis net.Listener) ServerOption {
+	return func(s *Server) {
+		s.lis = lis
+		s.network = lis.Addr().Network()
+		s.address = lis.Addr().String()
+	}
+}
+
+// TLSConfig with the tls config of the server, it is honored in full: certificates picked
+// by GetCertificate, client certificates verified against ClientCAs and the NextProtos of ALPN.
+// Use a tlsutil.CertReloader as GetCertificate to reload the certificate from disk.
+func TLSConfig(c *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tlsConf = c
+	}
+}
+
+// HTTP2 serves HTTP/2 over tls next to HTTP/1.1, the protocol is negotiated by ALPN.
+// It requires TLSConfig, client and bidi streaming rpcs need HTTP/1.1 for their websockets.
+func HTTP2(opts ...h2config.Option) ServerOption {
+	return func(s *Server) {
+		s.http2 = true
+		s.http2Opts = append(s.http2Opts, opts...)
+	}
+}
+
+// H2C serves HTTP/2 cleartext with prior knowledge next to HTTP/1.1, the protocol is told
+// by the connection preface. Client and bidi streaming rpcs need HTTP/1.1 for their websockets.
+func H2C(opts ...h2config.Option) ServerOption {
+	return func(s *Server) {
+		s.h2c = true
+		s.http2Opts = append(s.http2Opts, opts...)
+	}
+}
+
 // Endpoint with endpoint
 func Endpoint(endpoint *url.URL) ServerOption {
 	return func(s *Server) {
@@ -89,6 +132,38 @@ func NoRouteHandler(h Handler) ServerOption {
 	}
 }
 
+// Timeout with the timeout of the requests, zero disables it. A client may shorten
+// it with a Grpc-Timeout or X-Request-Timeout header.
+func Timeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = d
+	}
+}
+
+// OperationTimeout with the timeout of the operations matching selector in place of the server
+// timeout, zero disables it. Selectors are matched as middleware selectors, e.g. /helloworld.Greeter/*.
+func OperationTimeout(selector string, d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeouts.Add(selector, d)
+	}
+}
+
+// StreamHeartbeat with the heartbeat interval of server-sent event streams and
+// the ping interval of websockets, zero disables it.
+func StreamHeartbeat(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.heartbeat = d
+	}
+}
+
+// WebSocketUpgrader with the upgrader of client and bidi streaming rpcs,
+// its Error func is replaced by the error encoder.
+func WebSocketUpgrader(u *websocket.HertzUpgrader) ServerOption {
+	return func(s *Server) {
+		s.upgrader = u
+	}
+}
+
 func NewServer(opts ...ServerOption) *Server {
 	srv := &Server{
 		network:         "tcp",
@@ -98,17 +173,42 @@ func NewServer(opts ...ServerOption) *Server {
 		ene:             DefaultErrorEncoder,
 		notFoundHandler: Default404Handler,
 		timeout:         3 * time.Second,
+		timeouts:        timeout.New(),
+		heartbeat:       15 * time.Second,
+		upgrader:        &websocket.HertzUpgrader{},
 	}
 	for _, opt := range opts {
 		opt(srv)
 	}
+	if srv.http2 && srv.tlsConf == nil {
+		srv.err = errors.New("thertz: HTTP2 requires TLSConfig, use H2C to serve HTTP/2 cleartext")
+	}
 	hOpts := make([]config.Option, 0)
 	hOpts = append(hOpts, server.WithNetwork(srv.network))
 	hOpts = append(hOpts, server.WithHostPorts(srv.address))
+	if srv.lis != nil && srv.tlsConf != nil {
+		hOpts = append(hOpts, server.WithTransport(newTLSListenerTransporter(srv.lis)))
+	} else if srv.lis != nil {
+		hOpts = append(hOpts, server.WithTransport(newListenerTransporter(srv.lis)))
+	}
 	if srv.tlsConf != nil {
-		hOpts = append(hOpts, server.WithTLS(srv.tlsConf))
+		// hertz appends http/1.1 to the NextProtos of the config
+		tlsConf := srv.tlsConf.Clone()
+		if srv.http2 {
+			if !slices.Contains(tlsConf.NextProtos, suite.HTTP2) {
+				tlsConf.NextProtos = append([]string{suite.HTTP2}, tlsConf.NextProtos...)
+			}
+			hOpts = append(hOpts, server.WithALPN(true))
+		}
+		hOpts = append(hOpts, server.WithTLS(tlsConf))
+	}
+	if srv.h2c {
+		hOpts = append(hOpts, server.WithH2C(true))
 	}
 	hertz := server.New(hOpts...)
+	if srv.http2 || srv.h2c {
+		hertz.AddProtocol(suite.HTTP2, newH2Factory(srv.http2Opts...))
+	}
 	srv.app = hertz
 	// error handler
 	srv.app.Use(recovery.Recovery(recovery.WithRecoveryHandler(srv.ene)))
@@ -122,11 +222,17 @@ type Server struct {
 	app             *server.Hertz
 	lis             net.Listener
 	tlsConf         *tls.Config
+	http2           bool
+	h2c             bool
+	http2Opts       []h2config.Option
 	endpoint        *url.URL
 	err             error
 	network         string
 	address         string
 	timeout         time.Duration
+	timeouts        timeout.Matcher
+	heartbeat       time.Duration
+	upgrader        *websocket.HertzUpgrader
 	middleware      matcher.Matcher
 	rawMid          []Handler
 	notFoundHandler Handler
@@ -139,7 +245,11 @@ func (s *Server) Start(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	return s.app.Run()
+	// the standard transport of tls reports its closed listener once stopped
+	if err = s.app.Run(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
 }
 
 func (s *Server) Stop(ctx context.Context) error {
@@ -155,10 +265,11 @@ func (s *Server) Endpoint() (*url.URL, error) {
 }
 
 func (s *Server) Middleware(m middleware.Handler, ctx context.Context, path string) middleware.Handler {
+	operation := path
 	if tr, ok := transport.FromServerContext(ctx); ok {
-		return middleware.Chain(s.middleware.Match(tr.Operation())...)(m)
+		operation = tr.Operation()
 	}
-	return middleware.Chain(s.middleware.Match(path)...)(m)
+	return timeout.Handler(s.timeouts, operation, middleware.Chain(s.middleware.Match(operation)...)(m))
 }
 
 func (s *Server) Router() route.IRoutes {
@@ -178,12 +289,25 @@ func (s *Server) Write(ctx *ReqCtx, v any) {
 
 func (s *Server) initEndpoint() error {
 	if s.endpoint == nil {
-		addr, err := host.Extract(s.address)
+		scheme := endpoint.Scheme("http", s.tlsConf != nil)
+		if s.network == "unix" {
+			s.endpoint = endpoint.NewUnixEndpoint(scheme, s.address)
+			return s.err
+		}
+		var (
+			addr string
+			err  error
+		)
+		if s.lis != nil {
+			addr, err = host.ExtractFromLis(s.address, s.lis)
+		} else {
+			addr, err = host.Extract(s.address)
+		}
 		if err != nil {
 			s.err = err
 			return err
 		}
-		s.endpoint = endpoint.NewEndpoint(endpoint.Scheme("http", s.tlsConf != nil), addr)
+		s.endpoint = endpoint.NewEndpoint(scheme, addr)
 	}
 	return s.err
 }
@@ -191,17 +315,22 @@ func (s *Server) initEndpoint() error {
 func (s *Server) transportMid() Handler {
 	return func(c context.Context, ctx *app.RequestContext) {
 		var cancel context.CancelFunc
-		if s.timeout > 0 {
-			c, cancel = context.WithTimeout(c, s.timeout)
+		if d := timeout.Budget(s.timeout, &ctx.Request.Header); d > 0 {
+			c, cancel = context.WithTimeout(c, d)
 		} else {
 			c, cancel = context.WithCancel(c)
 		}
 		defer cancel()
+		// HTTP/2 carries the host in the :authority pseudo header
+		if len(ctx.Request.Header.Host()) == 0 {
+			ctx.Request.Header.SetHostBytes(ctx.Request.URI().Host())
+		}
 		tr := Transport{
-			endpoint:    s.endpoint.String(),
-			reqHeader:   &requestHeaderCarrier{RequestHeader: &ctx.Request.Header},
-			replyHeader: &responseHeaderCarrier{ResponseHeader: &ctx.Response.Header},
-			request:     &ctx.Request,
+			endpoint:     s.endpoint.String(),
+			pathTemplate: ctx.FullPath(),
+			reqHeader:    &requestHeaderCarrier{RequestHeader: &ctx.Request.Header},
+			replyHeader:  &responseHeaderCarrier{ResponseHeader: &ctx.Response.Header},
+			request:      &ctx.Request,
 		}
 		c = transport.NewServerContext(c, &tr)
 		ctx.Next(c)
@@ -3,14 +3,19 @@ package thertz
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
 	"github.com/cloudwego/hertz/pkg/app"
 	kratoserrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/transport/http"
 	"io"
+	"net"
 	http2 "net/http"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -268,3 +273,317 @@ func testClient(t *testing.T, srv *Server) {
 		}
 	}
 }
+
+func TestServerStream(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Address("127.0.0.1:18083"), StreamHeartbeat(50*time.Millisecond))
+	closed := make(chan error, 1)
+	srv.Router().GET("/stream/:n", func(c context.Context, ctx *app.RequestContext) {
+		n := ctx.Param("n")
+		stream := srv.NewStream(c, ctx)
+		err := srv.ServeStream(ctx, stream, func() error {
+			if n == "forever" {
+				for {
+					if err := stream.Send(&testData{Path: n}); err != nil {
+						closed <- err
+						return err
+					}
+				}
+			}
+			if n == "0" {
+				return kratoserrors.BadRequest("BAD", "bad")
+			}
+			for i := 0; i < 2; i++ {
+				if err := stream.Send(&testData{Path: n}); err != nil {
+					return err
+				}
+			}
+			return kratoserrors.Conflict("CONFLICT", "conflict")
+		})
+		if err != nil {
+			panic(err)
+		}
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	req, _ := http2.NewRequest(http2.MethodGet, "http://127.0.0.1:18083/stream/1", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Last-Event-ID", "5")
+	resp, err := http2.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected %s got %s", "text/event-stream", ct)
+	}
+	want := "id: 6\ndata: {\"path\":\"1\"}\n\nid: 7\ndata: {\"path\":\"1\"}\n\nevent: error\ndata: "
+	if !strings.HasPrefix(string(content), want) || !strings.Contains(string(content), `"reason":"CONFLICT"`) {
+		t.Errorf("unexpected stream %q", content)
+	}
+
+	resp, err = http2.Get("http://127.0.0.1:18083/stream/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http2.StatusBadRequest {
+		t.Errorf("expected %d got %d", http2.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp, err = http2.Get("http://127.0.0.1:18083/stream/forever")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = resp.Body.Read(make([]byte, 64))
+	_ = resp.Body.Close()
+	select {
+	case err = <-closed:
+		if err != context.Canceled {
+			t.Errorf("expected %v got %v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the stream to stop once the client disconnects")
+	}
+}
+
+func TestServerTimeout(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(
+		Address("127.0.0.1:18085"),
+		Timeout(100*time.Millisecond),
+		OperationTimeout("/slow/*", time.Second),
+	)
+	srv.Router().GET("/wait/:op", func(c context.Context, ctx *app.RequestContext) {
+		SetOperation(c, "/"+ctx.Param("op")+"/wait")
+		h := srv.Middleware(func(ctx context.Context, _ interface{}) (interface{}, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(300 * time.Millisecond):
+				return &testData{Path: "done"}, nil
+			}
+		}, c, string(ctx.Path()))
+		out, err := h(c, nil)
+		if err != nil {
+			panic(err)
+		}
+		srv.Write(ctx, out)
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+
+	tests := []struct {
+		name   string
+		op     string
+		header map[string]string
+		code   int
+	}{
+		{"server", "fast", nil, http2.StatusGatewayTimeout},
+		{"operation", "slow", nil, http2.StatusOK},
+		{"grpc", "slow", map[string]string{"Grpc-Timeout": "50m"}, http2.StatusGatewayTimeout},
+		{"request", "slow", map[string]string{"X-Request-Timeout": "0.05"}, http2.StatusGatewayTimeout},
+		{"longer", "fast", map[string]string{"X-Request-Timeout": "10s"}, http2.StatusGatewayTimeout},
+	}
+	for _, test := range tests {
+		req, _ := http2.NewRequest(http2.MethodGet, "http://127.0.0.1:18085/wait/"+test.op, nil)
+		for k, v := range test.header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http2.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != test.code {
+			t.Errorf("%s: expected %d got %d %s", test.name, test.code, resp.StatusCode, body)
+		}
+		if test.code == http2.StatusGatewayTimeout && !strings.Contains(string(body), "DEADLINE_EXCEEDED") {
+			t.Errorf("%s: unexpected body %s", test.name, body)
+		}
+	}
+}
+
+func TestServerListener(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "app.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(Listener(lis))
+	srv.Router().GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		srv.Write(ctx, &testData{Path: "pong"})
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+	ept, err := srv.Endpoint()
+	if err != nil || ept.String() != "http+unix://"+path {
+		t.Errorf("expected %s got %v %v", "http+unix://"+path, ept, err)
+	}
+
+	client := &http2.Client{Transport: &http2.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var data testData
+	if err = json.NewDecoder(resp.Body).Decode(&data); err != nil || data.Path != "pong" {
+		t.Errorf("expected %s got %v %v", "pong", data, err)
+	}
+
+	other := filepath.Join(t.TempDir(), "other.sock")
+	if ept, err = NewServer(Network("unix"), Address(other)).Endpoint(); err != nil || ept.String() != "http+unix://"+other {
+		t.Errorf("expected %s got %v %v", "http+unix://"+other, ept, err)
+	}
+
+	// tls is served on the listener, HTTP/2 negotiated with ALPN
+	tlsPath := filepath.Join(t.TempDir(), "tls.sock")
+	tlsLis, err := net.Listen("unix", tlsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, pool := testutil.Certificate(t, "thertz.test")
+	tlsSrv := NewServer(Listener(tlsLis), TLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}}), HTTP2())
+	tlsSrv.Router().GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		tlsSrv.Write(ctx, &testData{Path: "pong"})
+	})
+	go func() {
+		if err := tlsSrv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = tlsSrv.Stop(ctx)
+	}()
+	if ept, err = tlsSrv.Endpoint(); err != nil || ept.String() != "https+unix://"+tlsPath {
+		t.Errorf("expected %s got %v %v", "https+unix://"+tlsPath, ept, err)
+	}
+	for _, proto := range []string{"h2", "http/1.1"} {
+		client := &http2.Client{Transport: &http2.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", tlsPath)
+			},
+			TLSClientConfig:   &tls.Config{ServerName: "thertz.test", RootCAs: pool, NextProtos: []string{proto}},
+			ForceAttemptHTTP2: proto == "h2",
+		}}
+		resp, err := client.Get("https://thertz.test/ping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data testData
+		if err = json.NewDecoder(resp.Body).Decode(&data); err != nil || data.Path != "pong" {
+			t.Errorf("%s: expected %s got %v %v", proto, "pong", data, err)
+		}
+		if resp.TLS.NegotiatedProtocol != proto {
+			t.Errorf("expected %s got %s", proto, resp.TLS.NegotiatedProtocol)
+		}
+		resp.Body.Close()
+		client.CloseIdleConnections()
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	ctx := context.Background()
+	fooCert, fooPool := testutil.Certificate(t, "foo.test")
+	barCert, barPool := testutil.Certificate(t, "bar.test")
+	clientCert, clientPool := testutil.Certificate(t, "client")
+	serverConf := &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName == "bar.test" {
+				return &barCert, nil
+			}
+			return &fooCert, nil
+		},
+		ClientCAs:  clientPool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		NextProtos: []string{"http/1.1"},
+	}
+	srv := NewServer(Address("127.0.0.1:18086"), TLSConfig(serverConf))
+	srv.Router().GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		srv.Write(ctx, &testData{Path: "pong"})
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		// the standard transport of tls waits for the stop context to be done
+		stopCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_ = srv.Stop(stopCtx)
+	}()
+	ept, err := srv.Endpoint()
+	if err != nil || ept.String() != "https://127.0.0.1:18086" {
+		t.Errorf("expected %s got %v %v", "https://127.0.0.1:18086", ept, err)
+	}
+	if len(serverConf.NextProtos) != 1 {
+		t.Errorf("expected the tls config untouched got %v", serverConf.NextProtos)
+	}
+
+	for name, pool := range map[string]*x509.CertPool{"foo.test": fooPool, "bar.test": barPool} {
+		client := &http2.Client{Transport: &http2.Transport{TLSClientConfig: &tls.Config{
+			ServerName:   name,
+			RootCAs:      pool,
+			Certificates: []tls.Certificate{clientCert},
+			NextProtos:   []string{"http/1.1"},
+		}}}
+		resp, err := client.Get("https://127.0.0.1:18086/ping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data testData
+		if err = json.NewDecoder(resp.Body).Decode(&data); err != nil || data.Path != "pong" {
+			t.Errorf("expected %s got %v %v", "pong", data, err)
+		}
+		if resp.TLS.NegotiatedProtocol != "http/1.1" {
+			t.Errorf("expected %s got %s", "http/1.1", resp.TLS.NegotiatedProtocol)
+		}
+		resp.Body.Close()
+		client.CloseIdleConnections()
+	}
+
+	client := &http2.Client{Transport: &http2.Transport{TLSClientConfig: &tls.Config{
+		ServerName: "foo.test",
+		RootCAs:    fooPool,
+	}}}
+	if resp, err := client.Get("https://127.0.0.1:18086/ping"); err == nil {
+		resp.Body.Close()
+		t.Error("expected an error without a client certificate")
+	}
+}
@@ -0,0 +1,40 @@
+package thertz
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/pkg/sse"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+)
+
+// Stream is the server-sent event stream of a server-streaming rpc.
+type Stream = sse.Stream
+
+// NewStream returns the event stream of the request, messages are encoded with the codec
+// of the Accept header. The stream is not bound by the server timeout, it lasts until the
+// handler returns or the client disconnects.
+func (s *Server) NewStream(c context.Context, ctx *ReqCtx) *Stream {
+	codec := sse.CodecForAccept(ctx.Request.Header.GetAll("Accept")...)
+	lastEventID := string(ctx.Request.Header.Peek(sse.LastEventIDHeader))
+	return sse.NewStream(context.WithoutCancel(c), codec, lastEventID, s.heartbeat)
+}
+
+// ServeStream runs handler and writes the stream as the response. The handler error is
+// returned if it fails before sending any event, later errors are sent as an error event.
+func (s *Server) ServeStream(ctx *ReqCtx, stream *Stream, handler func() error) error {
+	if err := stream.Run(handler); err != nil {
+		return err
+	}
+	ctx.Response.Header.SetContentType(sse.ContentType)
+	ctx.Response.Header.Set("Cache-Control", "no-cache")
+	// HTTP/2 streams the events in data frames in place of chunks
+	if c, ok := ctx.GetConn().(*h2Conn); ok {
+		ctx.Response.HijackWriter(&h2Writer{resp: &ctx.Response, w: c.w})
+	} else {
+		ctx.Response.HijackWriter(resp.NewChunkedBodyWriter(&ctx.Response, ctx.GetWriter()))
+	}
+	if err := stream.WriteTo(ctx.Response.GetHijackWriter()); err != nil {
+		hlog.Debugf("event stream closed: %v", err)
+	}
+	return nil
+}
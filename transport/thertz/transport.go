@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/cloudwego/hertz/pkg/protocol"
 	"github.com/go-kratos/kratos/v2/transport"
+	"slices"
 )
 
 const (
@@ -13,11 +14,12 @@ const (
 )
 
 type Transport struct {
-	endpoint    string
-	operation   string
-	reqHeader   *requestHeaderCarrier
-	replyHeader *responseHeaderCarrier
-	request     *protocol.Request
+	endpoint     string
+	operation    string
+	pathTemplate string
+	reqHeader    *requestHeaderCarrier
+	replyHeader  *responseHeaderCarrier
+	request      *protocol.Request
 }
 
 func (t *Transport) Kind() transport.Kind {
@@ -40,6 +42,16 @@ func (t *Transport) ReplyHeader() transport.Header {
 	return t.replyHeader
 }
 
+// PathTemplate returns the http path template.
+func (t *Transport) PathTemplate() string {
+	return t.pathTemplate
+}
+
+// Request returns the hertz request.
+func (t *Transport) Request() *protocol.Request {
+	return t.request
+}
+
 // header
 type requestHeaderCarrier struct {
 	*protocol.RequestHeader
@@ -48,7 +60,10 @@ type requestHeaderCarrier struct {
 func (h *requestHeaderCarrier) Keys() []string {
 	keys := make([]string, 0)
 	h.VisitAll(func(key, _ []byte) {
-		keys = append(keys, string(key))
+		// repeated headers are visited once per value
+		if k := string(key); !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
 	})
 	return keys
 }
@@ -64,7 +79,10 @@ type responseHeaderCarrier struct {
 func (h *responseHeaderCarrier) Keys() []string {
 	keys := make([]string, 0)
 	h.VisitAll(func(key, _ []byte) {
-		keys = append(keys, string(key))
+		// repeated headers are visited once per value
+		if k := string(key); !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
 	})
 	return keys
 }
@@ -0,0 +1,213 @@
+package thertz
+
+import (
+	"context"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/hertz-contrib/websocket"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// closeCodeOffset maps the kratos error code of a finished stream to an
+// application close code, e.g. a 404 error closes the connection with 4404.
+const closeCodeOffset = 4000
+
+// CloseCode returns the close code and the close text of a stream finished with err,
+// the text is the kratos error reason. Codes outside 0-999 are closed as unknown errors.
+func CloseCode(err error) (int, string) {
+	if err == nil {
+		return websocket.CloseNormalClosure, ""
+	}
+	se := errors.FromError(err)
+	reason := se.Reason
+	// the close frame payload is limited to 125 bytes
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	code := int(se.Code)
+	if code < 0 || code >= 1000 {
+		code = errors.UnknownCode
+	}
+	return closeCodeOffset + code, reason
+}
+
+// ErrorFromClose returns the kratos error of a close frame, nil for a normal closure.
+func ErrorFromClose(code int, text string) error {
+	switch {
+	case code == websocket.CloseNormalClosure:
+		return nil
+	case code >= closeCodeOffset && code < closeCodeOffset+1000:
+		return errors.New(code-closeCodeOffset, text, "")
+	default:
+		return errors.New(errors.UnknownCode, errors.UnknownReason, fmt.Sprintf("websocket closed with %d: %s", code, text))
+	}
+}
+
+// WebSocket is the server side of a client or bidi streaming rpc. A close frame of the
+// client closes its send side, the connection is then closed with the status of the handler.
+type WebSocket struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	conn    *websocket.Conn
+	codec   encoding.Codec
+	msgType int
+	frames  chan []byte
+	err     error
+	writeMu sync.Mutex
+}
+
+// Upgrade runs the middleware of the operation on the upgrade request, then upgrades it to
+// a websocket served by handler. The stream context is the one the middleware passed on,
+// without the server timeout. Errors before the upgrade are returned for the error encoder.
+func (s *Server) Upgrade(c context.Context, ctx *ReqCtx, handler func(ws *WebSocket) error) error {
+	var streamCtx context.Context
+	h := s.Middleware(func(ctx context.Context, _ interface{}) (interface{}, error) {
+		streamCtx = ctx
+		return nil, nil
+	}, c, string(ctx.Path()))
+	if _, err := h(c, nil); err != nil {
+		return err
+	}
+	codec, _ := CodecForRequest(ctx, "Content-Type")
+	u := *s.upgrader
+	status := http.StatusBadRequest
+	u.Error = func(_ *ReqCtx, code int, _ error) {
+		status = code
+	}
+	err := u.Upgrade(ctx, func(conn *websocket.Conn) {
+		ws := newWebSocket(context.WithoutCancel(streamCtx), conn, codec)
+		ws.serve(handler, s.heartbeat)
+	})
+	if err != nil {
+		return errors.New(status, "WEBSOCKET_HANDSHAKE", err.Error())
+	}
+	return nil
+}
+
+func newWebSocket(ctx context.Context, conn *websocket.Conn, codec encoding.Codec) *WebSocket {
+	ctx, cancel := context.WithCancel(ctx)
+	ws := &WebSocket{
+		ctx:     ctx,
+		cancel:  cancel,
+		conn:    conn,
+		codec:   codec,
+		msgType: websocket.TextMessage,
+		frames:  make(chan []byte),
+	}
+	if codec.Name() == "proto" {
+		ws.msgType = websocket.BinaryMessage
+	}
+	// the close frame is answered once the handler has finished
+	conn.SetCloseHandler(func(int, string) error {
+		return nil
+	})
+	return ws
+}
+
+// Context returns the stream context, it is canceled once the client disconnects.
+func (ws *WebSocket) Context() context.Context {
+	return ws.ctx
+}
+
+// SendMsg encodes m as a frame of the connection.
+func (ws *WebSocket) SendMsg(m any) error {
+	data, err := ws.codec.Marshal(m)
+	if err != nil {
+		return err
+	}
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	if err = ws.ctx.Err(); err != nil {
+		return err
+	}
+	return ws.conn.WriteMessage(ws.msgType, data)
+}
+
+// RecvMsg blocks until it receives a frame into m,
+// it returns io.EOF once the client has closed its send side.
+func (ws *WebSocket) RecvMsg(m any) error {
+	select {
+	case data, ok := <-ws.frames:
+		if !ok {
+			return ws.err
+		}
+		return ws.codec.Unmarshal(data, m)
+	case <-ws.ctx.Done():
+		return ws.ctx.Err()
+	}
+}
+
+// serve reads the frames of the client while handler runs,
+// then closes the connection with the handler status.
+func (ws *WebSocket) serve(handler func(ws *WebSocket) error, heartbeat time.Duration) {
+	defer ws.cancel()
+	go ws.read()
+	if heartbeat > 0 {
+		go ws.ping(heartbeat)
+	}
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = errors.InternalServer("PANIC", fmt.Sprintf("%v", r))
+			}
+		}()
+		err = handler(ws)
+	}()
+	if ws.ctx.Err() == nil {
+		code, text := CloseCode(err)
+		ws.writeMu.Lock()
+		e := ws.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(time.Second))
+		ws.writeMu.Unlock()
+		if e != nil {
+			hlog.Debugf("websocket close err: %v", e)
+		}
+	}
+	_ = ws.conn.Close()
+}
+
+// read queues the frames of the client, a normal closure ends them with io.EOF
+// while any other read error cancels the stream.
+func (ws *WebSocket) read() {
+	defer close(ws.frames)
+	for {
+		_, data, err := ws.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				ws.err = io.EOF
+			} else {
+				ws.err = context.Canceled
+				ws.cancel()
+			}
+			return
+		}
+		select {
+		case ws.frames <- data:
+		case <-ws.ctx.Done():
+			ws.err = ws.ctx.Err()
+			return
+		}
+	}
+}
+
+// ping keeps the connection alive and detects a dead client.
+func (ws *WebSocket) ping(heartbeat time.Duration) {
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeat)); err != nil {
+				ws.cancel()
+				return
+			}
+		case <-ws.ctx.Done():
+			return
+		}
+	}
+}
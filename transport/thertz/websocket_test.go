@@ -0,0 +1,269 @@
+package thertz
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"io"
+	"net"
+	http2 "net/http"
+	"testing"
+	"time"
+)
+
+// testWebSocket is a minimal websocket client speaking raw frames.
+type testWebSocket struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialWebSocket(addr, path string, header map[string]string) (*testWebSocket, int, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	req := fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n", path, addr)
+	for k, v := range header {
+		req += k + ": " + v + "\r\n"
+	}
+	if _, err = conn.Write([]byte(req + "\r\n")); err != nil {
+		return nil, 0, err
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http2.ReadResponse(r, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http2.StatusSwitchingProtocols {
+		_ = conn.Close()
+		return nil, resp.StatusCode, nil
+	}
+	return &testWebSocket{conn: conn, r: r}, resp.StatusCode, nil
+}
+
+// write sends a masked frame, the zero mask key leaves the payload as is.
+func (ws *testWebSocket) write(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload)), 0, 0, 0, 0}
+	_, err := ws.conn.Write(append(frame, payload...))
+	return err
+}
+
+func (ws *testWebSocket) close(code int) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return ws.write(8, payload)
+}
+
+// read returns the next data or close frame.
+func (ws *testWebSocket) read() (byte, []byte, error) {
+	for {
+		var head [2]byte
+		if _, err := io.ReadFull(ws.r, head[:]); err != nil {
+			return 0, nil, err
+		}
+		n := uint64(head[1] & 0x7f)
+		switch n {
+		case 126:
+			var l [2]byte
+			if _, err := io.ReadFull(ws.r, l[:]); err != nil {
+				return 0, nil, err
+			}
+			n = uint64(binary.BigEndian.Uint16(l[:]))
+		case 127:
+			var l [8]byte
+			if _, err := io.ReadFull(ws.r, l[:]); err != nil {
+				return 0, nil, err
+			}
+			n = binary.BigEndian.Uint64(l[:])
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(ws.r, payload); err != nil {
+			return 0, nil, err
+		}
+		if opcode := head[0] & 0x0f; opcode != 9 && opcode != 10 {
+			return opcode, payload, nil
+		}
+	}
+}
+
+func (ws *testWebSocket) readClose() error {
+	opcode, payload, err := ws.read()
+	if err != nil {
+		return err
+	}
+	if opcode != 8 || len(payload) < 2 {
+		return fmt.Errorf("expected close frame got %d %q", opcode, payload)
+	}
+	return ErrorFromClose(int(binary.BigEndian.Uint16(payload)), string(payload[2:]))
+}
+
+func TestServerWebSocket(t *testing.T) {
+	ctx := context.Background()
+	auth := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok && tr.RequestHeader().Get("Authorization") == "" {
+				return nil, kratoserrors.Unauthorized("UNAUTHORIZED", "")
+			}
+			return handler(ctx, req)
+		}
+	}
+	srv := NewServer(Address("127.0.0.1:18084"), Middleware(auth), StreamHeartbeat(50*time.Millisecond))
+	closed := make(chan error, 1)
+	srv.Router().GET("/ws/:kind", func(c context.Context, ctx *app.RequestContext) {
+		kind := ctx.Param("kind")
+		err := srv.Upgrade(c, ctx, func(ws *WebSocket) error {
+			if _, ok := transport.FromServerContext(ws.Context()); !ok {
+				return kratoserrors.InternalServer("TRANSPORT", "")
+			}
+			switch kind {
+			case "bidi":
+				for {
+					var in testData
+					if err := ws.RecvMsg(&in); err == io.EOF {
+						return nil
+					} else if err != nil {
+						return err
+					}
+					if err := ws.SendMsg(&in); err != nil {
+						return err
+					}
+				}
+			case "client":
+				var count int
+				for {
+					var in testData
+					if err := ws.RecvMsg(&in); err == io.EOF {
+						break
+					} else if err != nil {
+						return err
+					}
+					count++
+				}
+				if err := ws.SendMsg(&testData{Path: fmt.Sprint(count)}); err != nil {
+					return err
+				}
+				return kratoserrors.Conflict("CONFLICT", "conflict")
+			default:
+				<-ws.Context().Done()
+				closed <- ws.Context().Err()
+				return nil
+			}
+		})
+		if err != nil {
+			panic(err)
+		}
+	})
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(time.Second)
+	defer func() {
+		_ = srv.Stop(ctx)
+	}()
+	header := map[string]string{"Authorization": "token", "Content-Type": "application/json"}
+
+	ws, _, err := dialWebSocket("127.0.0.1:18084", "/ws/bidi", header)
+	if err != nil || ws == nil {
+		t.Fatal(ws, err)
+	}
+	for _, v := range []string{`{"path":"a"}`, `{"path":"b"}`} {
+		if err = ws.write(1, []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+		_, data, err := ws.read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != v {
+			t.Errorf("expected %s got %s", v, data)
+		}
+	}
+	if err = ws.close(1000); err != nil {
+		t.Fatal(err)
+	}
+	if err = ws.readClose(); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+
+	ws, _, err = dialWebSocket("127.0.0.1:18084", "/ws/client", header)
+	if err != nil || ws == nil {
+		t.Fatal(ws, err)
+	}
+	for i := 0; i < 3; i++ {
+		if err = ws.write(1, []byte(`{"path":"a"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = ws.close(1000); err != nil {
+		t.Fatal(err)
+	}
+	if _, data, err := ws.read(); err != nil || string(data) != `{"path":"3"}` {
+		t.Errorf("unexpected reply %s %v", data, err)
+	}
+	if err = ws.readClose(); !kratoserrors.IsConflict(err) || kratoserrors.Reason(err) != "CONFLICT" {
+		t.Errorf("expected conflict got %v", err)
+	}
+
+	ws, _, err = dialWebSocket("127.0.0.1:18084", "/ws/forever", header)
+	if err != nil || ws == nil {
+		t.Fatal(ws, err)
+	}
+	_ = ws.conn.Close()
+	select {
+	case err = <-closed:
+		if err != context.Canceled {
+			t.Errorf("expected %v got %v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the stream to stop once the client disconnects")
+	}
+
+	if _, code, err := dialWebSocket("127.0.0.1:18084", "/ws/bidi", nil); err != nil || code != http2.StatusUnauthorized {
+		t.Errorf("expected %d got %d %v", http2.StatusUnauthorized, code, err)
+	}
+	req, _ := http2.NewRequest(http2.MethodGet, "http://127.0.0.1:18084/ws/bidi", nil)
+	req.Header.Set("Authorization", "token")
+	resp, err := http2.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http2.StatusBadRequest {
+		t.Errorf("expected %d got %d", http2.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestCloseCode(t *testing.T) {
+	for _, c := range []struct {
+		err    error
+		code   int
+		reason string
+	}{
+		{nil, 1000, ""},
+		{kratoserrors.NotFound("NOT_FOUND", ""), 4404, "NOT_FOUND"},
+		{kratoserrors.New(1001, "TOO_BIG", ""), 4500, "TOO_BIG"},
+		{kratoserrors.New(-1, "NEGATIVE", ""), 4500, "NEGATIVE"},
+	} {
+		code, reason := CloseCode(c.err)
+		if code != c.code || reason != c.reason {
+			t.Errorf("expected %d %s got %d %s", c.code, c.reason, code, reason)
+		}
+		err := ErrorFromClose(code, reason)
+		if c.err == nil {
+			if err != nil {
+				t.Errorf("expected nil got %v", err)
+			}
+			continue
+		}
+		if kratoserrors.Code(err) != c.code-4000 || kratoserrors.Reason(err) != c.reason {
+			t.Errorf("expected %d %s got %v", c.code-4000, c.reason, err)
+		}
+	}
+}
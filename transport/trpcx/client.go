@@ -0,0 +1,255 @@
+package trpcx
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
+	"github.com/LiangQinghai/kratos-ext/pkg/resolver"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/smallnest/rpcx/client"
+	"github.com/smallnest/rpcx/protocol"
+	"github.com/smallnest/rpcx/share"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ClientOption is rpcx client option.
+type ClientOption func(o *clientOptions)
+
+// WithEndpoint with client endpoint, such as "127.0.0.1:8972", "direct:///127.0.0.1:8972", "discovery:///svc"
+// or the endpoint of a server on a unix socket, such as "rpcx+unix:///tmp/app.sock".
+func WithEndpoint(endpoint string) ClientOption {
+	return func(o *clientOptions) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithTimeout with client timeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithMiddleware with client middleware.
+func WithMiddleware(m ...middleware.Middleware) ClientOption {
+	return func(o *clientOptions) {
+		o.middleware = m
+	}
+}
+
+// WithDiscovery with client discovery.
+func WithDiscovery(d registry.Discovery) ClientOption {
+	return func(o *clientOptions) {
+		o.discovery = d
+	}
+}
+
+// WithTLSConfig with the tls config of the connections, the rpcxs endpoint of the instances
+// is picked from the discovery then. Set Certificates for mutual tls, and ServerName for unix sockets.
+func WithTLSConfig(c *tls.Config) ClientOption {
+	return func(o *clientOptions) {
+		o.option.TLSConfig = c
+	}
+}
+
+// WithFailMode with how a failed call is retried, client.Failtry by default.
+func WithFailMode(m client.FailMode) ClientOption {
+	return func(o *clientOptions) {
+		o.failMode = m
+	}
+}
+
+// WithSelectMode with how a server is picked, client.RoundRobin by default.
+// client.WeightedRoundRobin reads the weight from the metadata of the instances.
+func WithSelectMode(m client.SelectMode) ClientOption {
+	return func(o *clientOptions) {
+		o.selectMode = m
+	}
+}
+
+// WithSerializeType with the codec of the request and reply messages, protobuf by default.
+func WithSerializeType(t protocol.SerializeType) ClientOption {
+	return func(o *clientOptions) {
+		o.option.SerializeType = t
+	}
+}
+
+// WithErrorDecode with the error decode of the error encode of the servers.
+func WithErrorDecode(dee DecodeErrorFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.dee = dee
+	}
+}
+
+// WithRpcxOption with a function setting options of the rpcx clients, such as the retries or the heartbeat.
+func WithRpcxOption(f func(o *client.Option)) ClientOption {
+	return func(o *clientOptions) {
+		f(&o.option)
+	}
+}
+
+// clientOptions is rpcx client config
+type clientOptions struct {
+	endpoint   string
+	timeout    time.Duration
+	discovery  registry.Discovery
+	middleware []middleware.Middleware
+	failMode   client.FailMode
+	selectMode client.SelectMode
+	option     client.Option
+	dee        DecodeErrorFunc
+}
+
+// Dial returns a client of the servers of the endpoint, a rpcx client of each service path
+// is created on its first call.
+func Dial(ctx context.Context, opts ...ClientOption) (*Client, error) {
+	options := clientOptions{
+		timeout:    time.Second * 5,
+		failMode:   client.Failtry,
+		selectMode: client.RoundRobin,
+		option:     client.DefaultOption,
+		dee:        DefaultErrorDecoder,
+	}
+	options.option.SerializeType = protocol.ProtoBuffer
+	for _, opt := range opts {
+		opt(&options)
+	}
+	secure := options.option.TLSConfig != nil
+	target, err := resolver.ParseTarget(options.endpoint, endpoint.Scheme("rpcx", secure))
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		opts:     &options,
+		xclients: make(map[string]client.XClient),
+	}
+	if target.Scheme == "discovery" {
+		if options.discovery == nil {
+			return nil, fmt.Errorf("trpcx: discovery endpoint %s without discovery", options.endpoint)
+		}
+		if c.discovery, err = NewDiscovery(ctx, options.discovery, target.Endpoint, secure); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	key := "tcp@" + target.Authority
+	if target.Scheme == "direct" {
+		key = "tcp@" + target.Endpoint
+	}
+	if u, err := url.Parse(options.endpoint); err == nil {
+		if path, _, ok := endpoint.UnixPath(u); ok {
+			key = "unix@" + path
+		}
+	}
+	c.discovery = new(Discovery)
+	c.discovery.update([]*client.KVPair{{Key: key}})
+	return c, nil
+}
+
+type Client struct {
+	opts      *clientOptions
+	discovery *Discovery
+	mu        sync.Mutex
+	xclients  map[string]client.XClient
+	closed    bool
+}
+
+// Close stops watching the discovery and closes the connections of the client.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.discovery.Close()
+	var err error
+	for _, xc := range c.xclients {
+		if e := xc.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// xclient returns the rpcx client of servicePath.
+func (c *Client) xclient(servicePath string) (client.XClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, client.ErrXClientShutdown
+	}
+	xc, ok := c.xclients[servicePath]
+	if !ok {
+		xc = client.NewXClient(servicePath, c.opts.failMode, c.opts.selectMode, c.discovery, c.opts.option)
+		c.xclients[servicePath] = xc
+	}
+	return xc, nil
+}
+
+// Call calls operation, such as "/helloworld.Greeter/SayHello", the request header is sent
+// as the metadata of the rpcx request and the metadata of the reply is the reply header.
+func (c *Client) Call(ctx context.Context, operation string, req any, reply any) error {
+	servicePath, method, err := splitOperation(operation)
+	if err != nil {
+		return err
+	}
+	tr := &Transport{
+		operation:   operation,
+		reqHeader:   headerCarrier{},
+		replyHeader: headerCarrier{},
+	}
+	ctx = transport.NewClientContext(ctx, tr)
+
+	var h middleware.Handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+		if c.opts.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.opts.timeout)
+			defer cancel()
+		}
+		xc, err := c.xclient(servicePath)
+		if err != nil {
+			return nil, err
+		}
+		reqMD := make(map[string]string, len(tr.reqHeader))
+		for k, v := range tr.reqHeader {
+			reqMD[k] = v
+		}
+		replyMD := make(map[string]string)
+		ctx = context.WithValue(ctx, share.ReqMetaDataKey, reqMD)
+		ctx = context.WithValue(ctx, share.ResMetaDataKey, replyMD)
+		err = xc.Call(ctx, method, req, reply)
+		for k, v := range replyMD {
+			tr.replyHeader[k] = v
+		}
+		if err != nil {
+			return nil, c.callError(ctx, err)
+		}
+		return reply, nil
+	}
+	if len(c.opts.middleware) > 0 {
+		h = middleware.Chain(c.opts.middleware...)(h)
+	}
+	_, err = h(ctx, req)
+	return err
+}
+
+// callError returns the kratos error of a failed call.
+func (c *Client) callError(ctx context.Context, err error) error {
+	if se, ok := err.(client.ServiceError); ok && se.IsServiceError() {
+		return c.opts.dee(se.Error())
+	}
+	if ctx.Err() != nil {
+		return contextError(ctx.Err())
+	}
+	if errors.Is(err, client.ErrXClientNoServer) {
+		return errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
+	}
+	return err
+}
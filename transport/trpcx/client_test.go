@@ -0,0 +1,89 @@
+package trpcx
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/smallnest/rpcx/client"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func testInstance(addr string, weight string) *registry.ServiceInstance {
+	return &registry.ServiceInstance{
+		Endpoints: []string{"http://" + addr, "rpcx://" + addr},
+		Metadata:  map[string]string{"weight": weight},
+	}
+}
+
+func pairs(d *Discovery) []string {
+	var got []string
+	for _, p := range d.GetServices() {
+		got = append(got, p.Key+" "+p.Value)
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestDiscovery(t *testing.T) {
+	td := testutil.NewDiscovery()
+	d, err := NewDiscovery(context.Background(), td, "svc", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := d.WatchService()
+
+	steps := []struct {
+		name      string
+		instances []*registry.ServiceInstance
+		want      []string
+	}{
+		{"add", []*registry.ServiceInstance{testInstance("a:1", "10"), testInstance("b:1", "20")}, []string{"tcp@a:1 weight=10", "tcp@b:1 weight=20"}},
+		{"remove", []*registry.ServiceInstance{testInstance("b:1", "20")}, []string{"tcp@b:1 weight=20"}},
+		{"empty", nil, []string{"tcp@b:1 weight=20"}},
+		{"no rpcx endpoint", []*registry.ServiceInstance{{Endpoints: []string{"http://c:1"}}}, []string{"tcp@b:1 weight=20"}},
+		{"replace", []*registry.ServiceInstance{testInstance("c:1", "10")}, []string{"tcp@c:1 weight=10"}},
+	}
+	for _, step := range steps {
+		td.Update(step.instances)
+		// the next send waits for the snapshot to be applied
+		td.Update(step.instances)
+		if got := pairs(d); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: expected %v got %v", step.name, step.want, got)
+		}
+	}
+	// the watcher only holds the latest servers
+	if got := <-ch; len(got) != 1 || got[0].Key != "tcp@c:1" {
+		t.Errorf("expected the latest servers got %v", got)
+	}
+
+	d.SetFilter(func(p *client.KVPair) bool {
+		return p.Key != "tcp@c:1"
+	})
+	if got := d.GetServices(); len(got) != 0 {
+		t.Errorf("expected the filtered servers to be left out got %v", got)
+	}
+	d.RemoveWatcher(ch)
+	d.Close()
+	if td.Watchers() != 0 {
+		t.Error("expected the watcher to be stopped")
+	}
+}
+
+func TestClientEndpoint(t *testing.T) {
+	for endpoint, want := range map[string]string{
+		"127.0.0.1:8972":            "tcp@127.0.0.1:8972",
+		"direct:///127.0.0.1:8972":  "tcp@127.0.0.1:8972",
+		"rpcx+unix:///tmp/app.sock": "unix@/tmp/app.sock",
+	} {
+		c, err := Dial(context.Background(), WithEndpoint(endpoint))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.discovery.GetServices(); len(got) != 1 || got[0].Key != want {
+			t.Errorf("%s: expected %s got %v", endpoint, want, got)
+		}
+		_ = c.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package trpcx
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+var CanceledError = errors.New(499, "CANCELED", "call canceled by the caller")
+
+// EncodeErrorFunc encodes the error of a call as the error string of the rpcx reply.
+type EncodeErrorFunc func(err error) string
+
+// DecodeErrorFunc decodes the error string of a rpcx reply.
+type DecodeErrorFunc func(msg string) error
+
+// DefaultErrorEncoder encodes the kratos error of err as json, so that its code, reason
+// and metadata reach the client.
+func DefaultErrorEncoder(err error) string {
+	se := errors.FromError(contextError(err))
+	data, e := json.Marshal(se)
+	if e != nil {
+		return se.Error()
+	}
+	return string(data)
+}
+
+// DefaultErrorDecoder decodes a kratos error encoded by DefaultErrorEncoder, the error
+// strings of other rpcx servers become unknown errors.
+func DefaultErrorDecoder(msg string) error {
+	var se errors.Error
+	if err := json.Unmarshal([]byte(msg), &se); err == nil && se.Code != 0 {
+		return &se
+	}
+	return errors.New(errors.UnknownCode, errors.UnknownReason, msg)
+}
+
+// contextError returns the kratos error of a call aborted by its context, err otherwise.
+func contextError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return timeout.DeadlineExceededError
+	case errors.Is(err, context.Canceled):
+		return CanceledError
+	}
+	return err
+}
+
+// copyReply copies the reply of the handler into the reply rpcx encodes, a nil reply is empty.
+func copyReply(dst, src any) error {
+	if src == nil {
+		return nil
+	}
+	d, ok := dst.(proto.Message)
+	if !ok {
+		return errors.InternalServer("REPLY_NOT_PROTO", "the reply is not a proto message")
+	}
+	s, ok := src.(proto.Message)
+	if !ok {
+		return errors.InternalServer("REPLY_NOT_PROTO", "the reply is not a proto message")
+	}
+	proto.Reset(d)
+	if s.ProtoReflect().IsValid() {
+		proto.Merge(d, s)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package trpcx
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
+	"github.com/LiangQinghai/kratos-ext/pkg/resolver"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/smallnest/rpcx/client"
+	"net/url"
+	"sync"
+)
+
+var (
+	_ client.ServiceDiscovery = (*Discovery)(nil)
+	_ selector.Rebalancer     = (*Discovery)(nil)
+)
+
+// Discovery is a rpcx service discovery of the instances of a kratos service, the rpcx
+// clients of every service path the instances serve share it. The metadata of an instance,
+// such as weight or group, is the rpcx metadata of its server.
+type Discovery struct {
+	r      *resolver.Resolver
+	mu     sync.Mutex
+	pairs  []*client.KVPair
+	chans  []chan []*client.KVPair
+	filter client.ServiceDiscoveryFilter
+}
+
+// NewDiscovery watches the instances of the kratos service name in d, the rpcx endpoint
+// of the instances is picked, or the rpcxs one when secure.
+func NewDiscovery(ctx context.Context, d registry.Discovery, name string, secure bool) (*Discovery, error) {
+	scheme := endpoint.Scheme("rpcx", secure)
+	rd := new(Discovery)
+	r, err := resolver.New(ctx, d, &resolver.Target{Scheme: "discovery", Endpoint: name}, rd, false, scheme)
+	if err != nil {
+		return nil, err
+	}
+	rd.r = r
+	return rd, nil
+}
+
+// Apply applies the nodes of the kratos discovery.
+func (d *Discovery) Apply(nodes []selector.Node) {
+	pairs := make([]*client.KVPair, 0, len(nodes))
+	for _, node := range nodes {
+		values := url.Values{}
+		for k, v := range node.Metadata() {
+			values.Set(k, v)
+		}
+		pairs = append(pairs, &client.KVPair{Key: "tcp@" + node.Address(), Value: values.Encode()})
+	}
+	d.update(pairs)
+}
+
+// update replaces the servers and notifies the watchers, a watcher that has not
+// taken the previous servers yet only gets the latest ones.
+func (d *Discovery) update(pairs []*client.KVPair) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pairs = pairs
+	for _, ch := range d.chans {
+		pairs := d.filtered()
+		select {
+		case ch <- pairs:
+			continue
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- pairs:
+		default:
+		}
+	}
+}
+
+// filtered returns a copy of the servers the filter keeps, rpcx sorts the servers it gets.
+func (d *Discovery) filtered() []*client.KVPair {
+	pairs := make([]*client.KVPair, 0, len(d.pairs))
+	for _, p := range d.pairs {
+		if d.filter == nil || d.filter(p) {
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs
+}
+
+// GetServices returns the current servers.
+func (d *Discovery) GetServices() []*client.KVPair {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.filtered()
+}
+
+// WatchService returns a channel of the servers on every change.
+func (d *Discovery) WatchService() chan []*client.KVPair {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ch := make(chan []*client.KVPair, 1)
+	d.chans = append(d.chans, ch)
+	return ch
+}
+
+// RemoveWatcher stops notifying ch.
+func (d *Discovery) RemoveWatcher(ch chan []*client.KVPair) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, c := range d.chans {
+		if c == ch {
+			d.chans = append(d.chans[:i], d.chans[i+1:]...)
+			return
+		}
+	}
+}
+
+// Clone returns d, the instances of a kratos service serve all of its service paths.
+func (d *Discovery) Clone(string) (client.ServiceDiscovery, error) {
+	return d, nil
+}
+
+// SetFilter sets the filter of the servers.
+func (d *Discovery) SetFilter(filter client.ServiceDiscoveryFilter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.filter = filter
+}
+
+// Close stops watching the kratos discovery.
+func (d *Discovery) Close() {
+	if d.r != nil {
+		_ = d.r.Close()
+	}
+}
@@ -0,0 +1,287 @@
+package trpcx_test
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/LiangQinghai/kratos-ext/internal/testutil"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
+	"github.com/LiangQinghai/kratos-ext/transport/trpcx"
+	"github.com/LiangQinghai/kratos-ext/transport/trpcx/internal/helloworld"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/smallnest/rpcx/protocol"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// greeter serves the generated Greeter with a function.
+type greeter func(context.Context, *helloworld.HelloRequest) (*helloworld.HelloReply, error)
+
+func (g greeter) SayHello(ctx context.Context, in *helloworld.HelloRequest) (*helloworld.HelloReply, error) {
+	return g(ctx, in)
+}
+
+var hello greeter = func(_ context.Context, in *helloworld.HelloRequest) (*helloworld.HelloReply, error) {
+	switch in.Name {
+	case "error":
+		return nil, errors.BadRequest("BAD_NAME", "bad name").WithMetadata(map[string]string{"name": in.Name})
+	case "panic":
+		panic("boom")
+	}
+	return &helloworld.HelloReply{Message: "hello " + in.Name}, nil
+}
+
+var headerMid = func(handler middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			tr.ReplyHeader().Set("x-reply", tr.RequestHeader().Get("x-request"))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// startServer starts srv with its endpoint resolved beforehand, as a kratos app does.
+func startServer(t *testing.T, srv *trpcx.Server) {
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Start(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	t.Cleanup(func() {
+		_ = srv.Stop(context.Background())
+	})
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	srv := trpcx.NewServer(trpcx.Address("127.0.0.1:18104"), trpcx.Middleware(headerMid))
+	helloworld.RegisterGreeterRpcxServer(srv, hello)
+	startServer(t, srv)
+	ept, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ept.String() != "rpcx://127.0.0.1:18104" {
+		t.Errorf("expected rpcx://127.0.0.1:18104 got %s", ept)
+	}
+
+	for _, st := range []protocol.SerializeType{protocol.ProtoBuffer, protocol.JSON} {
+		var tr transport.Transporter
+		clientMid := func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				tr, _ = transport.FromClientContext(ctx)
+				tr.RequestHeader().Set("x-request", "foo")
+				return handler(ctx, req)
+			}
+		}
+		cc, err := trpcx.Dial(ctx, trpcx.WithEndpoint(ept.Host), trpcx.WithSerializeType(st), trpcx.WithMiddleware(clientMid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := helloworld.NewGreeterRpcxClient(cc)
+
+		reply, err := client.SayHello(ctx, &helloworld.HelloRequest{Name: "kratos"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reply.Message != "hello kratos" {
+			t.Errorf("expected hello kratos got %s", reply.Message)
+		}
+		if got := tr.ReplyHeader().Get("x-reply"); got != "foo" {
+			t.Errorf("expected the reply header foo got %s", got)
+		}
+		for _, k := range tr.ReplyHeader().Keys() {
+			if k != "x-reply" {
+				t.Errorf("unexpected reply header %s", k)
+			}
+		}
+
+		_, err = client.SayHello(ctx, &helloworld.HelloRequest{Name: "error"})
+		if se := errors.FromError(err); se.Code != 400 || se.Reason != "BAD_NAME" || se.Metadata["name"] != "error" {
+			t.Errorf("expected the kratos error got %v", err)
+		}
+		if got := tr.ReplyHeader().Get("x-reply"); got != "foo" {
+			t.Errorf("expected the reply header of an error foo got %s", got)
+		}
+		_, err = client.SayHello(ctx, &helloworld.HelloRequest{Name: "panic"})
+		if se := errors.FromError(err); se.Code != errors.UnknownCode {
+			t.Errorf("expected an unknown error got %v", err)
+		}
+		err = cc.Call(ctx, "/helloworld.Greeter/Missing", &helloworld.HelloRequest{Name: "kratos"}, new(helloworld.HelloReply))
+		if se := errors.FromError(err); se.Code != errors.UnknownCode {
+			t.Errorf("expected an unknown error got %v", err)
+		}
+		if err = cc.Call(ctx, "Missing", &helloworld.HelloRequest{Name: "kratos"}, new(helloworld.HelloReply)); err == nil {
+			t.Error("expected an error for an invalid operation")
+		}
+		_ = cc.Close()
+		if _, err = client.SayHello(ctx, &helloworld.HelloRequest{Name: "kratos"}); err == nil {
+			t.Error("expected an error once closed")
+		}
+	}
+}
+
+func TestServerTimeout(t *testing.T) {
+	ctx := context.Background()
+	srv := trpcx.NewServer(trpcx.Address("127.0.0.1:18105"), trpcx.Timeout(100*time.Millisecond))
+	helloworld.RegisterGreeterRpcxServer(srv, greeter(func(ctx context.Context, _ *helloworld.HelloRequest) (*helloworld.HelloReply, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	startServer(t, srv)
+
+	cc, err := trpcx.Dial(ctx, trpcx.WithEndpoint("direct:///127.0.0.1:18105"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	client := helloworld.NewGreeterRpcxClient(cc)
+	start := time.Now()
+	_, err = client.SayHello(ctx, &helloworld.HelloRequest{Name: "kratos"})
+	if !errors.Is(err, timeout.DeadlineExceededError) {
+		t.Errorf("expected %v got %v", timeout.DeadlineExceededError, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("expected the server timeout, took %s", d)
+	}
+
+	// the deadline of the caller reaches the server
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = client.SayHello(ctx, &helloworld.HelloRequest{Name: "kratos"})
+	if !errors.Is(err, timeout.DeadlineExceededError) {
+		t.Errorf("expected %v got %v", timeout.DeadlineExceededError, err)
+	}
+}
+
+// testMutualTLS returns the tls configs of a server and of a client authenticating each other.
+func testMutualTLS(t *testing.T) (*tls.Config, *tls.Config) {
+	serverCert, serverPool := testutil.Certificate(t, "127.0.0.1")
+	clientCert, clientPool := testutil.Certificate(t, "client")
+	return &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      serverPool,
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	ctx := context.Background()
+	serverConf, clientConf := testMutualTLS(t)
+	srv := trpcx.NewServer(trpcx.Address("127.0.0.1:18106"), trpcx.TLSConfig(serverConf))
+	helloworld.RegisterGreeterRpcxServer(srv, greeter(func(ctx context.Context, _ *helloworld.HelloRequest) (*helloworld.HelloReply, error) {
+		var name string
+		if tr, ok := trpcx.FromRpcxTransport(ctx); ok && len(tr.PeerCertificates()) > 0 {
+			name = tr.PeerCertificates()[0].Subject.CommonName
+		}
+		return &helloworld.HelloReply{Message: name}, nil
+	}))
+	startServer(t, srv)
+	ept, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ept.Scheme != "rpcxs" {
+		t.Errorf("expected %s got %s", "rpcxs", ept.Scheme)
+	}
+
+	cc, err := trpcx.Dial(ctx, trpcx.WithEndpoint(ept.Host), trpcx.WithTLSConfig(clientConf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	reply, err := helloworld.NewGreeterRpcxClient(cc).SayHello(ctx, &helloworld.HelloRequest{Name: "kratos"})
+	if err != nil || reply.Message != "client" {
+		t.Errorf("expected the identity of the client got %v %v", reply, err)
+	}
+
+	plain, err := trpcx.Dial(ctx, trpcx.WithEndpoint(ept.Host), trpcx.WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	if _, err = helloworld.NewGreeterRpcxClient(plain).SayHello(ctx, &helloworld.HelloRequest{Name: "kratos"}); err == nil {
+		t.Error("expected a plain client to fail")
+	}
+}
+
+func TestServerUnix(t *testing.T) {
+	ctx := context.Background()
+	lis, err := net.Listen("unix", filepath.Join(t.TempDir(), "app.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := trpcx.NewServer(trpcx.Listener(lis))
+	helloworld.RegisterGreeterRpcxServer(srv, hello)
+	startServer(t, srv)
+	ept, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ept.Scheme != "rpcx+unix" {
+		t.Errorf("expected rpcx+unix got %s", ept.Scheme)
+	}
+	cc, err := trpcx.Dial(ctx, trpcx.WithEndpoint(ept.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	reply, err := helloworld.NewGreeterRpcxClient(cc).SayHello(ctx, &helloworld.HelloRequest{Name: "unix"})
+	if err != nil || reply.Message != "hello unix" {
+		t.Fatalf("expected hello unix got %v %v", reply, err)
+	}
+}
+
+func TestClientDiscovery(t *testing.T) {
+	ctx := context.Background()
+	srv := trpcx.NewServer(trpcx.Address("127.0.0.1:18107"))
+	helloworld.RegisterGreeterRpcxServer(srv, hello)
+	startServer(t, srv)
+	ept, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	td := testutil.NewDiscovery()
+	cc, err := trpcx.Dial(ctx, trpcx.WithEndpoint("discovery:///svc"), trpcx.WithDiscovery(td))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	client := helloworld.NewGreeterRpcxClient(cc)
+	if _, err = client.SayHello(ctx, &helloworld.HelloRequest{Name: "kratos"}); err == nil {
+		t.Error("expected an error without instances")
+	}
+
+	instance := &registry.ServiceInstance{Endpoints: []string{ept.String()}}
+	td.Update([]*registry.ServiceInstance{instance})
+	td.Update([]*registry.ServiceInstance{instance})
+	// the rpcx client watching the discovery applies the servers asynchronously
+	var reply *helloworld.HelloReply
+	deadline := time.Now().Add(time.Second)
+	for {
+		reply, err = client.SayHello(ctx, &helloworld.HelloRequest{Name: "kratos"})
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil || reply.Message != "hello kratos" {
+		t.Fatalf("expected hello kratos got %v %v", reply, err)
+	}
+
+	if _, err = trpcx.Dial(ctx, trpcx.WithEndpoint("discovery:///svc")); err == nil {
+		t.Error("expected an error without discovery")
+	}
+}
@@ -0,0 +1,62 @@
+package trpcx
+
+import (
+	"fmt"
+	"github.com/go-kratos/kratos/v2/log"
+	rpcxLog "github.com/smallnest/rpcx/log"
+)
+
+func init() {
+	rpcxLog.SetLogger(new(rpcxLogAdapter))
+}
+
+type rpcxLogAdapter struct {
+}
+
+func (a *rpcxLogAdapter) Debug(v ...interface{}) {
+	log.Debug(v...)
+}
+
+func (a *rpcxLogAdapter) Debugf(format string, v ...interface{}) {
+	log.Debugf(format, v...)
+}
+
+func (a *rpcxLogAdapter) Info(v ...interface{}) {
+	log.Info(v...)
+}
+
+func (a *rpcxLogAdapter) Infof(format string, v ...interface{}) {
+	log.Infof(format, v...)
+}
+
+func (a *rpcxLogAdapter) Warn(v ...interface{}) {
+	log.Warn(v...)
+}
+
+func (a *rpcxLogAdapter) Warnf(format string, v ...interface{}) {
+	log.Warnf(format, v...)
+}
+
+func (a *rpcxLogAdapter) Error(v ...interface{}) {
+	log.Error(v...)
+}
+
+func (a *rpcxLogAdapter) Errorf(format string, v ...interface{}) {
+	log.Errorf(format, v...)
+}
+
+func (a *rpcxLogAdapter) Fatal(v ...interface{}) {
+	log.Fatal(v...)
+}
+
+func (a *rpcxLogAdapter) Fatalf(format string, v ...interface{}) {
+	log.Fatalf(format, v...)
+}
+
+func (a *rpcxLogAdapter) Panic(v ...interface{}) {
+	panic(fmt.Sprint(v...))
+}
+
+func (a *rpcxLogAdapter) Panicf(format string, v ...interface{}) {
+	panic(fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,287 @@
+package trpcx
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/LiangQinghai/kratos-ext/pkg/endpoint"
+	"github.com/LiangQinghai/kratos-ext/pkg/host"
+	"github.com/LiangQinghai/kratos-ext/pkg/matcher"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/smallnest/rpcx/protocol"
+	"github.com/smallnest/rpcx/server"
+	"github.com/smallnest/rpcx/share"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	_              transport.Server     = (*Server)(nil)
+	_              transport.Endpointer = (*Server)(nil)
+	NoHandlerError                      = errors.New(404, "handler not found", "no handler found")
+)
+
+// ServerOption is a rpcx server option
+type ServerOption func(*Server)
+
+// Network set network
+func Network(network string) ServerOption {
+	return func(s *Server) {
+		s.network = network
+	}
+}
+
+// Address with address
+func Address(address string) ServerOption {
+	return func(s *Server) {
+		s.address = address
+	}
+}
+
+// Listener with a listener opened beforehand, such as a unix socket or a socket activated by systemd,
+// in place of the network and address.
+func Listener(lis net.Listener) ServerOption {
+	return func(s *Server) {
+		s.lis = lis
+		s.network = lis.Addr().Network()
+		s.address = lis.Addr().String()
+	}
+}
+
+// Endpoint with endpoint
+func Endpoint(endpoint *url.URL) ServerOption {
+	return func(s *Server) {
+		s.endpoint = endpoint
+	}
+}
+
+// TLSConfig with the tls config of the listener, the endpoint is advertised as rpcxs.
+// Set ClientAuth to tls.RequireAndVerifyClientCert for mutual tls, the identity of the client
+// is then available from the PeerCertificates of the Transport.
+func TLSConfig(c *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tlsConf = c
+	}
+}
+
+// Timeout with the timeout of a call, the deadline of the caller applies as well.
+func Timeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = timeout
+	}
+}
+
+// Middleware mid
+func Middleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.middleware.Use(m...)
+	}
+}
+
+// ErrorEncode with error encode, the clients must decode the errors the same way.
+func ErrorEncode(ene EncodeErrorFunc) ServerOption {
+	return func(s *Server) {
+		s.ene = ene
+	}
+}
+
+// RpcxOption with options of the rpcx server, such as server.WithPool or server.WithReadTimeout.
+func RpcxOption(opts ...server.OptionFn) ServerOption {
+	return func(s *Server) {
+		s.rpcxOpts = append(s.rpcxOpts, opts...)
+	}
+}
+
+func NewServer(opts ...ServerOption) *Server {
+	srv := &Server{
+		network:    "tcp",
+		address:    ":8972",
+		timeout:    3 * time.Second,
+		middleware: matcher.New(),
+		ene:        DefaultErrorEncoder,
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	if srv.lis != nil && srv.tlsConf != nil {
+		srv.lis = tls.NewListener(srv.lis, srv.tlsConf)
+	}
+	rpcxServer := server.NewServer(srv.rpcxOpts...)
+	// the listener serves rpcx only, the http of kratos has its own transport
+	rpcxServer.DisableHTTPGateway = true
+	rpcxServer.DisableJSONRPC = true
+	rpcxServer.ServerErrorFunc = func(_ *protocol.Message, err error) string {
+		return srv.ene(err)
+	}
+	srv.rpcxServer = rpcxServer
+	return srv
+}
+
+type Server struct {
+	rpcxServer *server.Server
+	rpcxOpts   []server.OptionFn
+	lis        net.Listener
+	err        error
+	network    string
+	address    string
+	endpoint   *url.URL
+	tlsConf    *tls.Config
+	timeout    time.Duration
+	middleware matcher.Matcher
+	ene        EncodeErrorFunc
+}
+
+// Rpcx returns the rpcx server, to add plugins or to register services directly.
+func (s *Server) Rpcx() *server.Server {
+	return s.rpcxServer
+}
+
+func (s *Server) Endpoint() (*url.URL, error) {
+	err := s.listenAndEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	return s.endpoint, nil
+}
+
+func (s *Server) Start(_ context.Context) error {
+	err := s.listenAndEndpoint()
+	if err != nil {
+		return err
+	}
+	log.Infof("[RPCX] server listening on: %s", s.lis.Addr().String())
+	// rpcx multiplexes a tcp network for its gateways, which are disabled,
+	// serving another network keeps the connections unwrapped
+	err = s.rpcxServer.ServeListener("rpcx", s.lis)
+	if err != nil && !errors.Is(err, server.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// Stop stops serving, it rejects the new calls and waits for the in-flight calls
+// until ctx is done, then closes the connections.
+func (s *Server) Stop(ctx context.Context) error {
+	log.Info("[RPCX] server stopping")
+	err := s.rpcxServer.Shutdown(ctx)
+	if err != nil {
+		log.Warnf("[RPCX] server stop: %v, closing the in-flight calls", err)
+	}
+	if s.lis != nil {
+		_ = s.lis.Close()
+	}
+	return nil
+}
+
+// Handle registers fn as the function of operation, such as "/helloworld.Greeter/SayHello"
+// for the method SayHello of the rpcx service path helloworld.Greeter.
+// fn is a func(context.Context, *Request, *Reply) error.
+func (s *Server) Handle(operation string, fn any) *Server {
+	servicePath, method, err := splitOperation(operation)
+	if err == nil {
+		err = s.rpcxServer.RegisterFunctionName(servicePath, method, fn, "")
+	}
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+	return s
+}
+
+// NewContext returns ctx of a call with the server Transport of operation,
+// its headers are the metadata of the rpcx request and reply.
+func (s *Server) NewContext(ctx context.Context, operation string) context.Context {
+	tr := &Transport{
+		operation: operation,
+	}
+	if s.endpoint != nil {
+		tr.endpoint = s.endpoint.String()
+	}
+	if md, ok := ctx.Value(share.ReqMetaDataKey).(map[string]string); ok {
+		tr.reqHeader = md
+	} else {
+		tr.reqHeader = headerCarrier{}
+	}
+	if md, ok := ctx.Value(share.ResMetaDataKey).(map[string]string); ok {
+		tr.replyHeader = md
+	} else {
+		tr.replyHeader = headerCarrier{}
+	}
+	if tc, ok := ctx.Value(server.RemoteConnContextKey).(*tls.Conn); ok {
+		state := tc.ConnectionState()
+		tr.tlsState = &state
+	}
+	return transport.NewServerContext(ctx, tr)
+}
+
+// Middleware returns the handler of the operation wrapped with its middleware,
+// a call whose deadline has passed by then is rejected.
+func (s *Server) Middleware(ctx context.Context, m middleware.Handler) middleware.Handler {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		h := middleware.Chain(s.middleware.Match(tr.Operation())...)(m)
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, contextError(err)
+			}
+			return h(ctx, req)
+		}
+	}
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, NoHandlerError
+	}
+}
+
+// Timeout bounds ctx by the server timeout, the deadline of the caller applies as well.
+func (s *Server) Timeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout > 0 {
+		return context.WithTimeout(ctx, s.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Reply copies the reply of the handler into out, the reply rpcx sends back.
+func (s *Server) Reply(out any, reply any) error {
+	return copyReply(out, reply)
+}
+
+func (s *Server) listenAndEndpoint() error {
+	if s.lis == nil {
+		lis, err := net.Listen(s.network, s.address)
+		if err != nil {
+			s.err = err
+			return err
+		}
+		s.lis = lis
+		if s.tlsConf != nil {
+			s.lis = tls.NewListener(lis, s.tlsConf)
+		}
+	}
+	if s.endpoint == nil {
+		scheme := endpoint.Scheme("rpcx", s.tlsConf != nil)
+		if path, ok := host.Unix(s.lis); ok {
+			s.endpoint = endpoint.NewUnixEndpoint(scheme, path)
+			return s.err
+		}
+		addr, err := host.ExtractFromLis(s.address, s.lis)
+		if err != nil {
+			s.err = err
+			return err
+		}
+		s.endpoint = endpoint.NewEndpoint(scheme, addr)
+	}
+	return s.err
+}
+
+// splitOperation splits an operation such as "/helloworld.Greeter/SayHello"
+// into the rpcx service path and method.
+func splitOperation(operation string) (string, string, error) {
+	servicePath, method, ok := strings.Cut(strings.TrimPrefix(operation, "/"), "/")
+	if !ok || servicePath == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", fmt.Errorf("trpcx: invalid operation %s", operation)
+	}
+	return servicePath, method, nil
+}
@@ -0,0 +1,68 @@
+package trpcx
+
+import (
+	"context"
+	"github.com/LiangQinghai/kratos-ext/pkg/timeout"
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"testing"
+)
+
+const testOperation = "/helloworld.Greeter/SayHello"
+
+func TestServerHandle(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"))
+	srv.Handle("Missing", func(context.Context, *wrapperspb.StringValue, *wrapperspb.StringValue) error {
+		return nil
+	})
+	if err := srv.Start(context.Background()); err == nil {
+		t.Error("expected an error for an invalid operation")
+	}
+	srv = NewServer(Address("127.0.0.1:0"))
+	srv.Handle(testOperation, func() {})
+	if err := srv.Start(context.Background()); err == nil {
+		t.Error("expected an error for an invalid function")
+	}
+}
+
+func TestSplitOperation(t *testing.T) {
+	servicePath, method, err := splitOperation(testOperation)
+	if err != nil || servicePath != "helloworld.Greeter" || method != "SayHello" {
+		t.Errorf("unexpected %s %s %v", servicePath, method, err)
+	}
+	for _, op := range []string{"", "/", "/helloworld.Greeter", "/helloworld.Greeter/", "//SayHello", "/a/b/c"} {
+		if _, _, err = splitOperation(op); err == nil {
+			t.Errorf("%s: expected an error", op)
+		}
+	}
+}
+
+func TestErrorCodec(t *testing.T) {
+	err := DefaultErrorDecoder(DefaultErrorEncoder(errors.NotFound("USER_NOT_FOUND", "no user")))
+	if se := errors.FromError(err); se.Code != 404 || se.Reason != "USER_NOT_FOUND" || se.Message != "no user" {
+		t.Errorf("unexpected %v", err)
+	}
+	err = DefaultErrorDecoder(DefaultErrorEncoder(context.DeadlineExceeded))
+	if !errors.Is(err, timeout.DeadlineExceededError) {
+		t.Errorf("expected %v got %v", timeout.DeadlineExceededError, err)
+	}
+	err = DefaultErrorDecoder("rpcx: can't find method")
+	if se := errors.FromError(err); se.Code != errors.UnknownCode || se.Message != "rpcx: can't find method" {
+		t.Errorf("unexpected %v", err)
+	}
+}
+
+func TestReply(t *testing.T) {
+	srv := NewServer()
+	out := wrapperspb.String("stale")
+	if err := srv.Reply(out, wrapperspb.String("reply")); err != nil || out.Value != "reply" {
+		t.Errorf("expected reply got %s %v", out.Value, err)
+	}
+	var reply *wrapperspb.StringValue
+	if err := srv.Reply(out, reply); err != nil || out.Value != "" {
+		t.Errorf("expected an empty reply got %s %v", out.Value, err)
+	}
+	if err := srv.Reply(out, "reply"); err == nil {
+		t.Error("expected an error for a reply that is not a proto message")
+	}
+}
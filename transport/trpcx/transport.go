@@ -0,0 +1,119 @@
+package trpcx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/go-kratos/kratos/v2/transport"
+	"strings"
+)
+
+const (
+	KindRpcx transport.Kind = "rpcx"
+	// SupportPackageIsVersion1 These constants should not be referenced from any other code.
+	SupportPackageIsVersion1 = true
+)
+
+type Transport struct {
+	endpoint    string
+	operation   string
+	reqHeader   headerCarrier
+	replyHeader headerCarrier
+	tlsState    *tls.ConnectionState
+}
+
+// Kind returns the transport kind.
+func (tr *Transport) Kind() transport.Kind {
+	return KindRpcx
+}
+
+// Endpoint returns the transport endpoint.
+func (tr *Transport) Endpoint() string {
+	return tr.endpoint
+}
+
+// Operation returns the transport operation.
+func (tr *Transport) Operation() string {
+	return tr.operation
+}
+
+// RequestHeader returns the request header.
+func (tr *Transport) RequestHeader() transport.Header {
+	return tr.reqHeader
+}
+
+// ReplyHeader returns the reply header.
+func (tr *Transport) ReplyHeader() transport.Header {
+	return tr.replyHeader
+}
+
+// ConnectionState returns the tls state of the connection of the call on the server, nil without tls.
+func (tr *Transport) ConnectionState() *tls.ConnectionState {
+	return tr.tlsState
+}
+
+// PeerCertificates returns the certificate chain the client presented, the leaf first,
+// it is empty unless the server asks for client certificates.
+func (tr *Transport) PeerCertificates() []*x509.Certificate {
+	if tr.tlsState == nil {
+		return nil
+	}
+	return tr.tlsState.PeerCertificates
+}
+
+// headerCarrier is the metadata of a rpcx message, it holds a single value per key,
+// Add joins the values of a key with a comma as http does.
+type headerCarrier map[string]string
+
+// Get returns the value associated with the passed key.
+func (mc headerCarrier) Get(key string) string {
+	return mc[key]
+}
+
+// Set stores the key-value pair.
+func (mc headerCarrier) Set(key string, value string) {
+	mc[key] = value
+}
+
+// Add append value to key-values pair.
+func (mc headerCarrier) Add(key string, value string) {
+	if v, ok := mc[key]; ok {
+		value = v + "," + value
+	}
+	mc[key] = value
+}
+
+// Keys lists the keys stored in this carrier, the internal keys of rpcx are left out.
+func (mc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for k := range mc {
+		if !strings.HasPrefix(k, "__") {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Values returns a slice of values associated with the passed key.
+func (mc headerCarrier) Values(key string) []string {
+	if v, ok := mc[key]; ok {
+		return []string{v}
+	}
+	return nil
+}
+
+// FromRpcxTransport get rpcx Transport from context
+func FromRpcxTransport(ctx context.Context) (*Transport, bool) {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		tr, ok := tr.(*Transport)
+		return tr, ok
+	}
+	return nil, false
+}
+
+// SetOperation sets the transport operation.
+func SetOperation(ctx context.Context, op string) {
+	if tr, ok := FromRpcxTransport(ctx); ok {
+		tr.operation = op
+	}
+}